@@ -8,14 +8,21 @@ import (
 
 // Account interface
 type Account interface {
-	Address() common.Hash
+	Address() common.Address
 	Balance() *big.Int
 	Nonce() uint64
+	Keys() [][]byte
 
 	Marshal() ([]byte, error)
 	Unmarshal([]byte) error
 
 	IncreaseNonce()
 	AddToBalance(*big.Int) error
-	SubFromAccount(*big.Int) error
+	SubFromBalance(*big.Int) error
+	SetKeys([][]byte)
+}
+
+// AccountReader reads accounts of the world state.
+type AccountReader interface {
+	GetAccount(common.Address) (Account, error)
 }
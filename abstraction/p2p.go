@@ -1,7 +1,102 @@
 package abstraction
 
+import (
+	"context"
+	"time"
+)
+
+// MessageType is the type of a p2p message, the types are defined by the p2p package.
+type MessageType uint16
+
+// MessagePriority is the priority of a p2p message, the priorities are defined by the p2p package.
+type MessagePriority uint8
+
+// RequestType identifies the handler of a p2p request.
+type RequestType uint32
+
+// RequestHandler handles a request of the peer of id and returns the response data.
+type RequestHandler func(from string, data []byte) ([]byte, error)
+
+// MessageValidator verifies the data of a message received from the peer of
+// id, messages are only relayed and passed to subscribers if they are valid.
+type MessageValidator func(from string, data []byte) error
+
+// BroadcastStrategy is how messages of a type are published and relayed.
+type BroadcastStrategy struct {
+	// Fanout pushes messages to a random sqrt(N) of the neighbors and
+	// announces them to the others, they are flooded otherwise.
+	Fanout   bool
+	Priority MessagePriority
+}
+
+// IncomingMessage is a valid message received from the peer of From.
+type IncomingMessage struct {
+	From string
+	Type MessageType
+	Data []byte
+}
+
+// PeerInfo describes a neighbor of the node.
+type PeerInfo struct {
+	ID         string
+	Addr       string
+	Version    uint16
+	HeadHeight uint64
+	// Latency is the last measured round trip time, 0 if it's not measured yet.
+	Latency  time.Duration
+	LastSeen time.Time
+	// Score is the reputation of the peer, it's banned when the score gets too low.
+	Score    int
+	BytesIn  uint64
+	BytesOut uint64
+}
+
+// BandwidthStats is the traffic of all peers of a message type.
+type BandwidthStats struct {
+	Type string
+	In   uint64
+	Out  uint64
+}
+
+// BannedPeer is a peer the node refuses to connect with until the ban ends.
+type BannedPeer struct {
+	ID    string
+	Until time.Time
+}
+
 // P2PService interface of p2p service.
 type P2PService interface {
 	Start() error
 	Stop()
+	// Broadcast floods data to all neighbors which do not have it yet.
+	Broadcast(data []byte, typ MessageType, mp MessagePriority)
+	// BroadcastFanout pushes data to a random sqrt(N) of the neighbors and
+	// announces its hash to the others, which fetch it if they need it.
+	BroadcastFanout(data []byte, typ MessageType, mp MessagePriority)
+	// Publish sends data to the network by the broadcast strategy of typ,
+	// which neighbors relay it with too.
+	Publish(data []byte, typ MessageType)
+	// SetBroadcastStrategy sets how messages of typ are published and relayed.
+	SetBroadcastStrategy(typ MessageType, s BroadcastStrategy)
+	// SendToPeer sends data to the neighbor of id.
+	SendToPeer(id string, data []byte, typ MessageType, mp MessagePriority) error
+	// Register returns a channel of incoming messages of types for the
+	// subscriber id, messages are dropped while the channel is full.
+	Register(id string, types ...MessageType) chan IncomingMessage
+	// SetValidator sets the validator of messages of typ.
+	SetValidator(typ MessageType, v MessageValidator)
+	// Request sends a request of typ to the neighbor of id and waits for the response data.
+	Request(ctx context.Context, id string, typ RequestType, data []byte) ([]byte, error)
+	// HandleRequest sets the handler of requests of typ.
+	HandleRequest(typ RequestType, h RequestHandler)
+	// Peers returns the neighbors of the node.
+	Peers() []*PeerInfo
+	// Bandwidth returns the traffic by message type.
+	Bandwidth() []*BandwidthStats
+	// BannedPeers returns the peers which are banned now.
+	BannedPeers() []*BannedPeer
+	// Ban disconnects the peer of id and bans it for duration d.
+	Ban(id string, d time.Duration) error
+	// Unban lifts the ban of the peer of id.
+	Unban(id string) error
 }
@@ -10,11 +10,20 @@ import (
 type Transaction interface {
 	Sign(KeyPair)
 	Verify([]byte) bool
-	VerifyIntegrity() error
+	VerifyIntegrity(AccountReader) error
+	VerifyData() error
+	VerifyPublicKey(AccountReader) error
+	VerifyWindow(height uint64, timestamp int64) error
+	Expired(height uint64, timestamp int64) bool
 
 	Hash() common.Hash
 	Timestamp() int64
 	Nonce() uint64
 	Fee() *big.Int
+	Size() uint64
+	Weight() uint64
+	EffectiveTip(baseFee *big.Int) *big.Int
 	ChainID() uint32
+	PublicKey() []byte
+	Signature() []byte
 }
@@ -1,8 +1,26 @@
 package abstraction
 
+import (
+	"math/big"
+)
+
+// FeeEstimate is the suggested fee of a tx.
+type FeeEstimate struct {
+	// Weight is the weight of the tx.
+	Weight uint64
+	// BaseFee is the base fee per weight unit of the next block.
+	BaseFee *big.Int
+	// Tip is the suggested tip of the tx.
+	Tip *big.Int
+	// MaxFee is the suggested max fee of the tx, it leaves room for base fee increases.
+	MaxFee *big.Int
+}
+
 // TxPool interface
 type TxPool interface {
 	AddTx(Transaction, bool) error
+	AddTxs([]Transaction, bool) []error
+	EstimateFee(weight uint64) *FeeEstimate
 	Start()
 	Stop()
 	//GetTx(txHash common.Hash) transaction.Transaction
@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"errors"
 
+	"github.com/ldmtam/tam-chain/common"
 	"github.com/mr-tron/base58/base58"
 	"golang.org/x/crypto/ed25519"
 )
@@ -49,6 +50,11 @@ func (kp *KeyPairImpl) Verify(sig, message []byte) bool {
 	return ed25519.Verify(kp.PublicKey, message, sig)
 }
 
+// Address returns address derived from public key
+func (kp *KeyPairImpl) Address() common.Address {
+	return common.NewAddress(common.AddressVersionEd25519, kp.PublicKey)
+}
+
 // EncodePrivateKey encode private key to string
 func (kp *KeyPairImpl) EncodePrivateKey() string {
 	return base58.Encode(kp.PrivateKey[:])
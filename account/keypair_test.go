@@ -6,10 +6,12 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/curve25519"
 )
 
 var (
 	message = []byte("hello, i am simple chain")
+	memo    = []byte("invoice #42")
 )
 
 func TestKeyPair(t *testing.T) {
@@ -27,3 +29,33 @@ func TestKeyPair(t *testing.T) {
 	verified := k.Verify(sig, message)
 	assert.True(t, verified)
 }
+
+func TestX25519PublicKey(t *testing.T) {
+	k, err := NewKeyPair()
+	assert.Nil(t, err)
+
+	// public key converted from ed25519 must match the one derived from the converted private key.
+	pubKey, err := X25519PublicKey(k.PublicKey)
+	assert.Nil(t, err)
+	wanted, err := curve25519.X25519(k.X25519PrivateKey(), curve25519.Basepoint)
+	assert.Nil(t, err)
+	assert.Equal(t, wanted, pubKey)
+}
+
+func TestMemo(t *testing.T) {
+	recipient, err := NewKeyPair()
+	assert.Nil(t, err)
+	other, err := NewKeyPair()
+	assert.Nil(t, err)
+
+	encryptedMemo, err := EncryptMemo(recipient.PublicKey, memo)
+	assert.Nil(t, err)
+	assert.NotEqual(t, memo, encryptedMemo)
+
+	decryptedMemo, err := recipient.DecryptMemo(encryptedMemo)
+	assert.Nil(t, err)
+	assert.Equal(t, memo, decryptedMemo)
+
+	_, err = other.DecryptMemo(encryptedMemo)
+	assert.NotNil(t, err)
+}
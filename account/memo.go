@@ -0,0 +1,23 @@
+package account
+
+import (
+	"crypto/rand"
+
+	"github.com/ldmtam/tam-chain/crypto/ecies"
+	"golang.org/x/crypto/ed25519"
+)
+
+// EncryptMemo encrypts memo with ECIES so only the owner of the recipient's
+// public key can read it.
+func EncryptMemo(recipient ed25519.PublicKey, memo []byte) ([]byte, error) {
+	pubKey, err := X25519PublicKey(recipient)
+	if err != nil {
+		return nil, err
+	}
+	return ecies.EncryptX25519(rand.Reader, pubKey, memo, nil, nil)
+}
+
+// DecryptMemo decrypts memo encrypted to our public key.
+func (kp *KeyPairImpl) DecryptMemo(encryptedMemo []byte) ([]byte, error) {
+	return ecies.DecryptX25519(kp.X25519PrivateKey(), encryptedMemo, nil, nil)
+}
@@ -0,0 +1,60 @@
+package account
+
+import (
+	"crypto/sha512"
+	"errors"
+	"math/big"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+var (
+	errInvalidEd25519PublicKey = errors.New("ed25519 public key cannot be converted to x25519")
+
+	// curve25519 field prime: 2^255 - 19
+	curve25519P = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+)
+
+// X25519PrivateKey converts ed25519 private key to x25519 private key, so the
+// key pair can be used for Diffie-Hellman key exchange.
+func (kp *KeyPairImpl) X25519PrivateKey() []byte {
+	h := sha512.Sum512(kp.PrivateKey.Seed())
+	return h[:32]
+}
+
+// X25519PublicKey converts ed25519 public key to x25519 public key with the
+// birational map from edwards25519 to curve25519: u = (1 + y) / (1 - y).
+func X25519PublicKey(pubKey ed25519.PublicKey) ([]byte, error) {
+	if len(pubKey) != ed25519.PublicKeySize {
+		return nil, errInvalidPublicKeyLength
+	}
+
+	// y is encoded in little endian, the highest bit is the sign of x.
+	yBytes := make([]byte, ed25519.PublicKeySize)
+	for i := range pubKey {
+		yBytes[ed25519.PublicKeySize-1-i] = pubKey[i]
+	}
+	yBytes[0] &= 0x7f
+	y := new(big.Int).SetBytes(yBytes)
+	if y.Cmp(curve25519P) >= 0 {
+		return nil, errInvalidEd25519PublicKey
+	}
+
+	denominator := new(big.Int).Sub(big.NewInt(1), y)
+	denominator.Mod(denominator, curve25519P)
+	if denominator.Sign() == 0 {
+		return nil, errInvalidEd25519PublicKey
+	}
+	denominator.ModInverse(denominator, curve25519P)
+
+	u := new(big.Int).Add(big.NewInt(1), y)
+	u.Mul(u, denominator)
+	u.Mod(u, curve25519P)
+
+	uBytes := u.Bytes()
+	x25519PubKey := make([]byte, 32)
+	for i := range uBytes {
+		x25519PubKey[i] = uBytes[len(uBytes)-1-i]
+	}
+	return x25519PubKey, nil
+}
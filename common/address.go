@@ -0,0 +1,146 @@
+package common
+
+import (
+	"errors"
+
+	"github.com/ldmtam/tam-chain/crypto/sha3"
+	"github.com/mr-tron/base58/base58"
+)
+
+/*
+Address format:
+
+ +---------+------------------------------------+------------+
+ | Version |        Public Key Hash (20)        |  Checksum  |
+ +---------+------------------------------------+------------+
+
+Version identifies the type of key the address is derived from, public key hash
+is the last 20 bytes of sha3-256(public key). The raw address is version + hash,
+the string form appends the first 4 bytes of sha3-256(sha3-256(version + hash))
+and is encoded with base58.
+*/
+
+const (
+	addressHashLength     = 20
+	addressChecksumLength = 4
+
+	// AddressLength of address
+	AddressLength = 1 + addressHashLength
+)
+
+// Address versions.
+const (
+	// AddressVersionEd25519 is the version of addresses derived from ed25519 public keys.
+	AddressVersionEd25519 byte = 0x01
+	// AddressVersionContract is the version of contract addresses, no key can sign for them.
+	AddressVersionContract byte = 0x02
+)
+
+// Errors
+var (
+	ErrInvalidAddressString   = errors.New("invalid address string")
+	ErrInvalidAddressLength   = errors.New("invalid address length")
+	ErrInvalidAddressVersion  = errors.New("invalid address version")
+	ErrInvalidAddressChecksum = errors.New("invalid address checksum")
+)
+
+// Address is version + public key hash.
+type Address [AddressLength]byte
+
+// NewAddress derives address from public key.
+func NewAddress(version byte, pubKey []byte) Address {
+	hash := sha3.Sum256(pubKey)
+
+	var a Address
+	a[0] = version
+	copy(a[1:], hash[len(hash)-addressHashLength:])
+	return a
+}
+
+// NewContractAddress derives the address of a contract deployed by the tx of deployer with nonce.
+func NewContractAddress(deployer Address, nonce uint64) Address {
+	return NewAddress(AddressVersionContract, append(deployer.CloneBytes(), FromUint64(nonce)...))
+}
+
+// ParseAddress decodes checksummed address string.
+func ParseAddress(s string) (Address, error) {
+	var a Address
+
+	b, err := base58.Decode(s)
+	if err != nil {
+		return a, ErrInvalidAddressString
+	}
+	if len(b) != AddressLength+addressChecksumLength {
+		return a, ErrInvalidAddressLength
+	}
+	if !isValidAddressVersion(b[0]) {
+		return a, ErrInvalidAddressVersion
+	}
+	if !Equal(addressChecksum(b[:AddressLength]), b[AddressLength:]) {
+		return a, ErrInvalidAddressChecksum
+	}
+
+	copy(a[:], b[:AddressLength])
+	return a, nil
+}
+
+// IsValidAddress checks whether s is a valid address string or not.
+func IsValidAddress(s string) bool {
+	_, err := ParseAddress(s)
+	return err == nil
+}
+
+// Version returns version of the address.
+func (a Address) Version() byte {
+	return a[0]
+}
+
+// IsValid checks whether the address has a known version or not.
+func (a Address) IsValid() bool {
+	return isValidAddressVersion(a.Version())
+}
+
+// CloneBytes returns a copy of the bytes which represent the address.
+func (a Address) CloneBytes() []byte {
+	b := make([]byte, AddressLength)
+	copy(b, a[:])
+	return b
+}
+
+// SetBytes sets the bytes which represent the address.
+func (a *Address) SetBytes(b []byte) {
+	if len(b) > len(a) {
+		b = b[len(b)-AddressLength:]
+	}
+	copy(a[AddressLength-len(b):], b)
+}
+
+// String returns checksummed base58 string of the address.
+func (a Address) String() string {
+	b := make([]byte, 0, AddressLength+addressChecksumLength)
+	b = append(b, a[:]...)
+	b = append(b, addressChecksum(a[:])...)
+	return base58.Encode(b)
+}
+
+// Equals compares two addresses. True is equal, otherwise false.
+func (a Address) Equals(b Address) bool {
+	a1 := a.CloneBytes()
+	a2 := b.CloneBytes()
+	return Equal(a1, a2)
+}
+
+func isValidAddressVersion(version byte) bool {
+	switch version {
+	case AddressVersionEd25519, AddressVersionContract:
+		return true
+	default:
+		return false
+	}
+}
+
+func addressChecksum(b []byte) []byte {
+	first := sha3.Sum256(b)
+	second := sha3.Sum256(first[:])
+	return second[:addressChecksumLength]
+}
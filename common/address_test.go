@@ -0,0 +1,62 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/mr-tron/base58/base58"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	pubKey = []byte{224, 95, 62, 36, 248, 102, 227, 57, 41, 18, 4, 88, 80, 126, 228, 44, 60, 194, 179, 171, 134, 223, 254, 20, 126, 208, 203, 185, 186, 61, 6, 194}
+)
+
+func TestAddress(t *testing.T) {
+	a := NewAddress(AddressVersionEd25519, pubKey)
+	assert.Equal(t, AddressVersionEd25519, a.Version())
+	assert.True(t, a.IsValid())
+
+	parsed, err := ParseAddress(a.String())
+	assert.Nil(t, err)
+	assert.True(t, a.Equals(parsed))
+	assert.True(t, IsValidAddress(a.String()))
+
+	var b Address
+	b.SetBytes(a.CloneBytes())
+	assert.Equal(t, a, b)
+}
+
+func TestContractAddress(t *testing.T) {
+	deployer := NewAddress(AddressVersionEd25519, pubKey)
+	a := NewContractAddress(deployer, 1)
+	assert.Equal(t, AddressVersionContract, a.Version())
+	assert.True(t, a.IsValid())
+	assert.False(t, a.Equals(NewContractAddress(deployer, 2)))
+
+	parsed, err := ParseAddress(a.String())
+	assert.Nil(t, err)
+	assert.Equal(t, a, parsed)
+}
+
+func TestParseInvalidAddress(t *testing.T) {
+	a := NewAddress(AddressVersionEd25519, pubKey)
+	raw, _ := base58.Decode(a.String())
+
+	_, err := ParseAddress("0OIl")
+	assert.Equal(t, ErrInvalidAddressString, err)
+
+	_, err = ParseAddress(base58.Encode(raw[1:]))
+	assert.Equal(t, ErrInvalidAddressLength, err)
+
+	typo := make([]byte, len(raw))
+	copy(typo, raw)
+	typo[5] ^= 0x01
+	_, err = ParseAddress(base58.Encode(typo))
+	assert.Equal(t, ErrInvalidAddressChecksum, err)
+
+	version := make([]byte, len(raw))
+	copy(version, raw)
+	version[0] = 0xff
+	_, err = ParseAddress(base58.Encode(version))
+	assert.Equal(t, ErrInvalidAddressVersion, err)
+}
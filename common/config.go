@@ -1,10 +1,93 @@
 package common
 
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+)
+
+// Network profiles
+const (
+	Mainnet = "mainnet"
+	Testnet = "testnet"
+)
+
+// P2P transports
+const (
+	// TransportCustom relays gossip with the flooding protocol of the p2p package.
+	TransportCustom = "custom"
+	// TransportGossipSub relays txs and blocks over libp2p gossipsub topics.
+	TransportGossipSub = "gossipsub"
+)
+
+var (
+	errUnknownNetwork = errors.New("unknown network")
+	errInvalidChainID = errors.New("chain id must not be 0")
+)
+
 // P2PConfig is the config of p2p network.
 type P2PConfig struct {
 	Port      string
 	SeedNodes []string
-	Version   uint16
-	ChainID   uint32
-	DataPath  string
+	// Version is the highest protocol version the node speaks, MinVersion
+	// the lowest, 0 means only Version.
+	Version    uint16
+	MinVersion uint16
+	ChainID    uint32
+	DataPath   string
+	// MaxInboundRate and MaxOutboundRate cap the bytes per second of all
+	// peers, 0 means unlimited.
+	MaxInboundRate  uint64
+	MaxOutboundRate uint64
+	// Transport is TransportCustom or TransportGossipSub, empty means TransportCustom.
+	Transport string
+}
+
+// ChainConfig is the config of the chain, it's defined by the genesis.
+type ChainConfig struct {
+	Network string `json:"network"`
+	ChainID uint32 `json:"chain_id"`
+}
+
+var (
+	// MainnetConfig is the chain config of our production network.
+	MainnetConfig = &ChainConfig{
+		Network: Mainnet,
+		ChainID: 1,
+	}
+
+	// TestnetConfig is the chain config of the test network.
+	TestnetConfig = &ChainConfig{
+		Network: Testnet,
+		ChainID: 2,
+	}
+)
+
+// NetworkConfig returns chain config of a network profile.
+func NetworkConfig(network string) (*ChainConfig, error) {
+	switch network {
+	case Mainnet:
+		return MainnetConfig, nil
+	case Testnet:
+		return TestnetConfig, nil
+	default:
+		return nil, errUnknownNetwork
+	}
+}
+
+// LoadChainConfig reads chain config from a genesis json file.
+func LoadChainConfig(path string) (*ChainConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &ChainConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+	if config.ChainID == 0 {
+		return nil, errInvalidChainID
+	}
+	return config, nil
 }
@@ -0,0 +1,39 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkConfig(t *testing.T) {
+	mainnet, err := NetworkConfig(Mainnet)
+	assert.Nil(t, err)
+	testnet, err := NetworkConfig(Testnet)
+	assert.Nil(t, err)
+	assert.NotEqual(t, mainnet.ChainID, testnet.ChainID)
+
+	_, err = NetworkConfig("unknown")
+	assert.Equal(t, errUnknownNetwork, err)
+}
+
+func TestLoadChainConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "genesis")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "genesis.json")
+	ioutil.WriteFile(path, []byte(`{"network":"devnet","chain_id":100}`), 0644)
+
+	config, err := LoadChainConfig(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "devnet", config.Network)
+	assert.EqualValues(t, 100, config.ChainID)
+
+	ioutil.WriteFile(path, []byte(`{"network":"devnet"}`), 0644)
+	_, err = LoadChainConfig(path)
+	assert.Equal(t, errInvalidChainID, err)
+}
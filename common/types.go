@@ -8,9 +8,6 @@ import (
 const (
 	// HashLength length of hash
 	HashLength = 32
-
-	// AddressLength of address
-	AddressLength = 32
 )
 
 // Hash is hash of data
@@ -56,32 +53,3 @@ func Equal(a, b []byte) bool {
 	}
 	return true
 }
-
-// Address ..
-type Address [AddressLength]byte
-
-// CloneBytes ...
-func (a Address) CloneBytes() []byte {
-	b := make([]byte, AddressLength)
-	copy(b, a[:])
-	return b
-}
-
-// SetBytes ...
-func (a *Address) SetBytes(b []byte) {
-	if len(b) > len(a) {
-		b = b[len(b)-AddressLength:]
-	}
-	copy(a[AddressLength-len(b):], b)
-}
-
-func (a Address) String() string {
-	return hex.EncodeToString(a[:])
-}
-
-// Equals ...
-func (a Address) Equals(b Address) bool {
-	a1 := a.CloneBytes()
-	a2 := b.CloneBytes()
-	return Equal(a1, a2)
-}
@@ -0,0 +1,163 @@
+package block
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/transaction"
+	"github.com/ldmtam/tam-chain/crypto/sha3"
+	"github.com/ldmtam/tam-chain/proto"
+)
+
+var (
+	errInvalidBlockToProto = errors.New("block cannot be converted to protobuf message")
+	errInvalidProtoToBlock = errors.New("protobuf message cannot be converted into block")
+)
+
+// Header is the header of a block.
+type Header struct {
+	Height     uint64
+	ParentHash common.Hash
+	Timestamp  int64
+	Coinbase   common.Address
+	StateRoot  common.Hash
+	TxRoot     common.Hash
+
+	// BaseFee is the fee per weight unit every tx of the block burns.
+	BaseFee *big.Int
+	// Weight is the total weight of txs in the block.
+	Weight uint64
+	// LogsBloom has addresses and topics of the logs of txs in the block.
+	LogsBloom Bloom
+}
+
+func (h *Header) toProto() *corepb.BlockHeader {
+	return &corepb.BlockHeader{
+		Height:     h.Height,
+		ParentHash: h.ParentHash.CloneBytes(),
+		Timestamp:  h.Timestamp,
+		Coinbase:   h.Coinbase.CloneBytes(),
+		StateRoot:  h.StateRoot.CloneBytes(),
+		TxRoot:     h.TxRoot.CloneBytes(),
+		BaseFee:    h.BaseFee.Bytes(),
+		Weight:     h.Weight,
+		LogsBloom:  h.LogsBloom.CloneBytes(),
+	}
+}
+
+func (h *Header) fromProto(pbHeader *corepb.BlockHeader) {
+	h.Height = pbHeader.Height
+	h.ParentHash.SetBytes(pbHeader.ParentHash)
+	h.Timestamp = pbHeader.Timestamp
+	h.Coinbase.SetBytes(pbHeader.Coinbase)
+	h.StateRoot.SetBytes(pbHeader.StateRoot)
+	h.TxRoot.SetBytes(pbHeader.TxRoot)
+	h.BaseFee = new(big.Int).SetBytes(pbHeader.BaseFee)
+	h.Weight = pbHeader.Weight
+	h.LogsBloom.SetBytes(pbHeader.LogsBloom)
+}
+
+// Marshal encodes header using protobuf.
+func (h *Header) Marshal() ([]byte, error) {
+	data, err := proto.Marshal(h.toProto())
+	if err != nil {
+		return nil, errInvalidBlockToProto
+	}
+	return data, nil
+}
+
+// Unmarshal decodes header using protobuf.
+func (h *Header) Unmarshal(data []byte) error {
+	pbHeader := &corepb.BlockHeader{}
+	if err := proto.Unmarshal(data, pbHeader); err != nil {
+		return errInvalidProtoToBlock
+	}
+	h.fromProto(pbHeader)
+	return nil
+}
+
+// Hash returns sha3-256 of the encoded header, it's the hash of the block.
+func (h *Header) Hash() common.Hash {
+	var hash common.Hash
+
+	data, _ := h.Marshal()
+	sum := sha3.Sum256(data)
+	hash.SetBytes(sum[:])
+	return hash
+}
+
+// Block is a header with its txs.
+type Block struct {
+	Header *Header
+	Txs    []*transaction.TxImpl
+}
+
+// Hash returns hash of the block header.
+func (b *Block) Hash() common.Hash {
+	return b.Header.Hash()
+}
+
+// Marshal encodes block using protobuf.
+func (b *Block) Marshal() ([]byte, error) {
+	pbBlock := &corepb.Block{
+		Header: b.Header.toProto(),
+		Txs:    make([][]byte, len(b.Txs)),
+	}
+	for i, tx := range b.Txs {
+		data, err := tx.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		pbBlock.Txs[i] = data
+	}
+
+	data, err := proto.Marshal(pbBlock)
+	if err != nil {
+		return nil, errInvalidBlockToProto
+	}
+	return data, nil
+}
+
+// Unmarshal decodes block using protobuf.
+func (b *Block) Unmarshal(data []byte) error {
+	pbBlock := &corepb.Block{}
+	if err := proto.Unmarshal(data, pbBlock); err != nil || pbBlock.Header == nil {
+		return errInvalidProtoToBlock
+	}
+
+	b.Header = &Header{}
+	b.Header.fromProto(pbBlock.Header)
+	b.Txs = make([]*transaction.TxImpl, len(pbBlock.Txs))
+	for i, txData := range pbBlock.Txs {
+		tx := &transaction.TxImpl{}
+		if err := tx.Unmarshal(txData); err != nil {
+			return err
+		}
+		b.Txs[i] = tx
+	}
+	return nil
+}
+
+// CalcTxRoot returns sha3-256 of the concatenated tx hashes.
+func CalcTxRoot(txs []*transaction.TxImpl) common.Hash {
+	hasher := sha3.New256()
+	for _, tx := range txs {
+		hash := tx.Hash()
+		hasher.Write(hash.CloneBytes())
+	}
+
+	var root common.Hash
+	root.SetBytes(hasher.Sum(nil))
+	return root
+}
+
+// CalcWeight returns the total weight of txs.
+func CalcWeight(txs []*transaction.TxImpl) uint64 {
+	var weight uint64
+	for _, tx := range txs {
+		weight += tx.Weight()
+	}
+	return weight
+}
@@ -0,0 +1,71 @@
+package block
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldmtam/tam-chain/account"
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalBlock(t *testing.T) {
+	from, _ := account.NewKeyPair()
+	to, _ := account.NewKeyPair()
+
+	tx, err := transaction.NewTransaction(common.MainnetConfig.ChainID, from.PublicKey, to.Address(), big.NewInt(1), big.NewInt(10000), 1, 1557360000, nil)
+	assert.Nil(t, err)
+	tx.Sign(from)
+
+	txs := []*transaction.TxImpl{tx}
+	b := &Block{
+		Header: &Header{
+			Height:    1,
+			Timestamp: 1557360000,
+			Coinbase:  from.Address(),
+			TxRoot:    CalcTxRoot(txs),
+			BaseFee:   InitialBaseFee,
+			Weight:    CalcWeight(txs),
+		},
+		Txs: txs,
+	}
+
+	data, err := b.Marshal()
+	assert.Nil(t, err)
+
+	newBlock := &Block{}
+	assert.Nil(t, newBlock.Unmarshal(data))
+	assert.Equal(t, b.Hash(), newBlock.Hash())
+	assert.Equal(t, tx.Hash(), newBlock.Txs[0].Hash())
+	assert.Equal(t, tx.Weight(), newBlock.Header.Weight)
+
+	newBlock.Header.Height = 2
+	assert.NotEqual(t, b.Hash(), newBlock.Hash())
+
+	// the logs bloom is committed in the hash.
+	newBlock.Header.Height = 1
+	newBlock.Header.LogsBloom.Add([]byte("topic"))
+	assert.NotEqual(t, b.Hash(), newBlock.Hash())
+	data, _ = newBlock.Header.Marshal()
+	header := &Header{}
+	assert.Nil(t, header.Unmarshal(data))
+	assert.Equal(t, newBlock.Header.LogsBloom, header.LogsBloom)
+}
+
+func TestBloom(t *testing.T) {
+	var bloom Bloom
+	assert.False(t, bloom.Test([]byte("a")))
+
+	bloom.Add([]byte("a"))
+	bloom.Add([]byte("b"))
+	assert.True(t, bloom.Test([]byte("a")))
+	assert.True(t, bloom.Test([]byte("b")))
+	assert.False(t, bloom.Test([]byte("c")))
+
+	var other Bloom
+	other.SetBytes(bloom.CloneBytes())
+	assert.Equal(t, bloom, other)
+	other.SetBytes([]byte{1})
+	assert.Equal(t, Bloom{}, other)
+}
@@ -0,0 +1,57 @@
+package block
+
+import (
+	"github.com/ldmtam/tam-chain/crypto/sha3"
+)
+
+// BloomLength is the size of a logs bloom in bytes.
+const BloomLength = 256
+
+// Bloom is a 2048 bit bloom filter of log addresses and topics. Every item sets
+// 3 bits picked by the sha3-256 of the item, so blooms are the same on every
+// node and can be committed in the header.
+type Bloom [BloomLength]byte
+
+// bloomBits returns the byte indexes and bit masks of item.
+func bloomBits(item []byte) (idx [3]int, bits [3]byte) {
+	hash := sha3.Sum256(item)
+	for i := range idx {
+		// 11 bits of each of the first 3 pairs of bytes select one of 2048 bits.
+		v := (uint(hash[2*i])<<8 | uint(hash[2*i+1])) & (BloomLength*8 - 1)
+		idx[i] = BloomLength - 1 - int(v/8)
+		bits[i] = 1 << (v % 8)
+	}
+	return idx, bits
+}
+
+// Add adds item to the bloom.
+func (b *Bloom) Add(item []byte) {
+	idx, bits := bloomBits(item)
+	for i := range idx {
+		b[idx[i]] |= bits[i]
+	}
+}
+
+// Test returns false if item was definitely not added to the bloom.
+func (b *Bloom) Test(item []byte) bool {
+	idx, bits := bloomBits(item)
+	for i := range idx {
+		if b[idx[i]]&bits[i] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SetBytes sets the bloom from b, it's left empty if b has another length.
+func (b *Bloom) SetBytes(data []byte) {
+	*b = Bloom{}
+	if len(data) == BloomLength {
+		copy(b[:], data)
+	}
+}
+
+// CloneBytes returns a copy of the bloom bytes.
+func (b Bloom) CloneBytes() []byte {
+	return append([]byte{}, b[:]...)
+}
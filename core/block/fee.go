@@ -0,0 +1,59 @@
+package block
+
+import (
+	"math/big"
+)
+
+// Base fee parameters, the base fee follows EIP-1559: it rises when blocks are
+// fuller than the target weight and falls when they are emptier, by at most
+// 1/BaseFeeChangeDenominator per block.
+const (
+	// TargetBlockWeight is the block weight at which the base fee stays the same.
+	TargetBlockWeight uint64 = 512 * 1024
+	// MaxBlockWeight is the maximum total weight of txs in a block.
+	MaxBlockWeight = 2 * TargetBlockWeight
+	// BaseFeeChangeDenominator bounds the change of base fee between blocks.
+	BaseFeeChangeDenominator = 8
+)
+
+var (
+	// InitialBaseFee is the base fee of the genesis block.
+	InitialBaseFee = big.NewInt(10)
+	// MinBaseFee is the lower bound of the base fee.
+	MinBaseFee = big.NewInt(1)
+
+	big1 = big.NewInt(1)
+)
+
+// CalcBaseFee returns the base fee of the child block of parent.
+func CalcBaseFee(parent *Header) *big.Int {
+	if parent.Weight == TargetBlockWeight {
+		return new(big.Int).Set(parent.BaseFee)
+	}
+
+	target := new(big.Int).SetUint64(TargetBlockWeight)
+	denominator := big.NewInt(BaseFeeChangeDenominator)
+
+	if parent.Weight > TargetBlockWeight {
+		// parent base fee + max(1, parent base fee * weight delta / target / denominator)
+		delta := new(big.Int).SetUint64(parent.Weight - TargetBlockWeight)
+		delta.Mul(delta, parent.BaseFee)
+		delta.Div(delta, target)
+		delta.Div(delta, denominator)
+		if delta.Cmp(big1) < 0 {
+			delta.Set(big1)
+		}
+		return delta.Add(delta, parent.BaseFee)
+	}
+
+	// max(min base fee, parent base fee - parent base fee * weight delta / target / denominator)
+	delta := new(big.Int).SetUint64(TargetBlockWeight - parent.Weight)
+	delta.Mul(delta, parent.BaseFee)
+	delta.Div(delta, target)
+	delta.Div(delta, denominator)
+	baseFee := delta.Sub(parent.BaseFee, delta)
+	if baseFee.Cmp(MinBaseFee) < 0 {
+		baseFee.Set(MinBaseFee)
+	}
+	return baseFee
+}
@@ -0,0 +1,30 @@
+package block
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalcBaseFee(t *testing.T) {
+	tests := []struct {
+		baseFee int64
+		weight  uint64
+		want    int64
+	}{
+		{1000, TargetBlockWeight, 1000},
+		{1000, 2 * TargetBlockWeight, 1125},
+		{1000, TargetBlockWeight + TargetBlockWeight/2, 1062},
+		{1000, 0, 875},
+		{1000, TargetBlockWeight / 2, 938},
+		// increases by at least 1.
+		{1, TargetBlockWeight + 1, 2},
+		// never falls below min base fee.
+		{1, 0, 1},
+	}
+	for _, test := range tests {
+		parent := &Header{BaseFee: big.NewInt(test.baseFee), Weight: test.weight}
+		assert.Equal(t, test.want, CalcBaseFee(parent).Int64(), "%+v", test)
+	}
+}
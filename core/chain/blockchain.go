@@ -0,0 +1,261 @@
+package chain
+
+import (
+	"errors"
+	"sync"
+
+	log "github.com/inconshreveable/log15"
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/block"
+	"github.com/ldmtam/tam-chain/core/executor"
+	"github.com/ldmtam/tam-chain/core/state"
+	"github.com/ldmtam/tam-chain/core/transaction"
+)
+
+var (
+	errInvalidBlockHeight    = errors.New("block height is not next to the head")
+	errInvalidParentHash     = errors.New("block parent hash does not match the head")
+	errInvalidBlockTimestamp = errors.New("block timestamp is earlier than its parent")
+	errInvalidBaseFee        = errors.New("invalid block base fee")
+	errInvalidBlockWeight    = errors.New("invalid block weight")
+	errBlockTooHeavy         = errors.New("block weight exceeds the limit")
+	errInvalidTxRoot         = errors.New("invalid block tx root")
+	errInvalidStateRoot      = errors.New("invalid block state root")
+	errInvalidLogsBloom      = errors.New("invalid block logs bloom")
+
+	// ErrStaleBlock is returned by VerifyBlock for blocks which are not above the head.
+	ErrStaleBlock = errors.New("block is not above the head")
+	// ErrFutureBlock is returned by VerifyBlock for blocks above the next height.
+	ErrFutureBlock = errors.New("block is above the next height")
+)
+
+// BlockChain keeps blocks in memory and applies them to the state.
+type BlockChain struct {
+	config   *common.ChainConfig
+	executor *executor.Executor
+	state    *state.StateDB
+	blocks   []*block.Block
+	// receipts of txs of blocks by height, txReceipts indexes them by tx hash.
+	receipts   [][]*executor.Receipt
+	txReceipts map[common.Hash]*executor.Receipt
+
+	mu sync.RWMutex
+}
+
+// NewBlockChain returns a new BlockChain, the genesis block commits to the current state.
+func NewBlockChain(config *common.ChainConfig, s *state.StateDB) *BlockChain {
+	s.Commit()
+	genesis := &block.Block{
+		Header: &block.Header{
+			StateRoot: s.Root(),
+			TxRoot:    block.CalcTxRoot(nil),
+			BaseFee:   block.InitialBaseFee,
+		},
+	}
+
+	return &BlockChain{
+		config:     config,
+		executor:   executor.NewExecutor(config),
+		state:      s,
+		blocks:     []*block.Block{genesis},
+		receipts:   [][]*executor.Receipt{nil},
+		txReceipts: make(map[common.Hash]*executor.Receipt),
+	}
+}
+
+// Executor returns the executor applying txs of blocks.
+func (bc *BlockChain) Executor() *executor.Executor {
+	return bc.executor
+}
+
+// State returns the state of the head block.
+func (bc *BlockChain) State() *state.StateDB {
+	return bc.state
+}
+
+// Genesis returns the genesis block.
+func (bc *BlockChain) Genesis() *block.Block {
+	return bc.GetBlockByHeight(0)
+}
+
+// Head returns the latest block.
+func (bc *BlockChain) Head() *block.Block {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	return bc.blocks[len(bc.blocks)-1]
+}
+
+// GetBlockByHeight returns the block of height, nil if it does not exist.
+func (bc *BlockChain) GetBlockByHeight(height uint64) *block.Block {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	if height >= uint64(len(bc.blocks)) {
+		return nil
+	}
+	return bc.blocks[height]
+}
+
+// GetReceipt returns the receipt of a tx applied in a block, nil if there is none.
+func (bc *BlockChain) GetReceipt(txHash common.Hash) *executor.Receipt {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	return bc.txReceipts[txHash]
+}
+
+// newContext returns the execution context of a block.
+func (bc *BlockChain) newContext(header *block.Header) *executor.Context {
+	return &executor.Context{
+		State:     bc.state,
+		Height:    header.Height,
+		Timestamp: header.Timestamp,
+		Coinbase:  header.Coinbase,
+		BaseFee:   header.BaseFee,
+	}
+}
+
+// BuildBlock makes the next block on top of the head with txs which can be
+// applied, others are skipped. The state is not changed.
+func (bc *BlockChain) BuildBlock(coinbase common.Address, timestamp int64, txs []*transaction.TxImpl) (*block.Block, error) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	parent := bc.blocks[len(bc.blocks)-1].Header
+	header := &block.Header{
+		Height:     parent.Height + 1,
+		ParentHash: parent.Hash(),
+		Timestamp:  timestamp,
+		Coinbase:   coinbase,
+		BaseFee:    block.CalcBaseFee(parent),
+	}
+
+	ctx := bc.newContext(header)
+	snapshot := bc.state.Snapshot()
+	defer bc.state.RevertToSnapshot(snapshot)
+
+	if err := bc.executor.BeginBlock(ctx); err != nil {
+		return nil, err
+	}
+
+	included := make([]*transaction.TxImpl, 0, len(txs))
+	receipts := make([]*executor.Receipt, 0, len(txs))
+	weight := uint64(0)
+	for _, tx := range txs {
+		// a smaller tx later in the list may still fit.
+		if weight+tx.Weight() > block.MaxBlockWeight {
+			continue
+		}
+		receipt, err := bc.executor.ApplyTx(ctx, tx)
+		if err != nil {
+			hash := tx.Hash()
+			log.Debug("Skip tx", "hash", hash.String(), "error", err)
+			continue
+		}
+		included = append(included, tx)
+		receipts = append(receipts, receipt)
+		weight += tx.Weight()
+	}
+
+	header.StateRoot = bc.state.Root()
+	header.TxRoot = block.CalcTxRoot(included)
+	header.Weight = weight
+	header.LogsBloom = executor.CreateBloom(receipts)
+	return &block.Block{Header: header, Txs: included}, nil
+}
+
+// VerifyBlock verifies the header of b against the head without applying its
+// txs. It returns ErrStaleBlock or ErrFutureBlock if b is not next to the head.
+func (bc *BlockChain) VerifyBlock(b *block.Block) error {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	parent := bc.blocks[len(bc.blocks)-1].Header
+	switch {
+	case b.Header.Height <= parent.Height:
+		return ErrStaleBlock
+	case b.Header.Height > parent.Height+1:
+		return ErrFutureBlock
+	}
+	return verifyHeader(parent, b)
+}
+
+// verifyHeader verifies the header of b against its parent and the txs of b.
+func verifyHeader(parent *block.Header, b *block.Block) error {
+	header := b.Header
+
+	if header.Height != parent.Height+1 {
+		return errInvalidBlockHeight
+	}
+	parentHash := parent.Hash()
+	if header.ParentHash.Equals(&parentHash) == false {
+		return errInvalidParentHash
+	}
+	if header.Timestamp < parent.Timestamp {
+		return errInvalidBlockTimestamp
+	}
+	if header.BaseFee == nil || header.BaseFee.Cmp(block.CalcBaseFee(parent)) != 0 {
+		return errInvalidBaseFee
+	}
+	if header.Weight > block.MaxBlockWeight {
+		return errBlockTooHeavy
+	}
+	if header.Weight != block.CalcWeight(b.Txs) {
+		return errInvalidBlockWeight
+	}
+	txRoot := block.CalcTxRoot(b.Txs)
+	if header.TxRoot.Equals(&txRoot) == false {
+		return errInvalidTxRoot
+	}
+	return nil
+}
+
+// ApplyBlock verifies the block against the head and applies its txs, the
+// block becomes the new head.
+func (bc *BlockChain) ApplyBlock(b *block.Block) (err error) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	parent := bc.blocks[len(bc.blocks)-1].Header
+	header := b.Header
+	if err := verifyHeader(parent, b); err != nil {
+		return err
+	}
+
+	snapshot := bc.state.Snapshot()
+	defer func() {
+		if err != nil {
+			bc.state.RevertToSnapshot(snapshot)
+		}
+	}()
+
+	ctx := bc.newContext(header)
+	if err := bc.executor.BeginBlock(ctx); err != nil {
+		return err
+	}
+	receipts := make([]*executor.Receipt, 0, len(b.Txs))
+	for _, tx := range b.Txs {
+		receipt, err := bc.executor.ApplyTx(ctx, tx)
+		if err != nil {
+			return err
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	stateRoot := bc.state.Root()
+	if header.StateRoot.Equals(&stateRoot) == false {
+		return errInvalidStateRoot
+	}
+	if header.LogsBloom != executor.CreateBloom(receipts) {
+		return errInvalidLogsBloom
+	}
+
+	bc.state.Commit()
+	bc.blocks = append(bc.blocks, b)
+	bc.receipts = append(bc.receipts, receipts)
+	for _, receipt := range receipts {
+		bc.txReceipts[receipt.TxHash] = receipt
+	}
+	return nil
+}
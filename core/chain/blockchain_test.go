@@ -0,0 +1,150 @@
+package chain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldmtam/tam-chain/account"
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/block"
+	"github.com/ldmtam/tam-chain/core/executor"
+	"github.com/ldmtam/tam-chain/core/state"
+	"github.com/ldmtam/tam-chain/core/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestChain(t *testing.T, alloc map[common.Address]int64) *BlockChain {
+	s := state.NewStateDB()
+	for addr, balance := range alloc {
+		acc, err := s.GetAccount(addr)
+		assert.Nil(t, err)
+		acc.AddToBalance(big.NewInt(balance))
+		assert.Nil(t, s.PutAccount(acc))
+	}
+	return NewBlockChain(common.TestnetConfig, s)
+}
+
+func newTransfer(t *testing.T, from *account.KeyPairImpl, to common.Address, nonce uint64, fee, tip int64) *transaction.TxImpl {
+	tx, err := transaction.NewTransaction(common.TestnetConfig.ChainID, from.PublicKey, to, big.NewInt(1), big.NewInt(fee), nonce, 1557360000, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, tx.SetTip(big.NewInt(tip)))
+	tx.Sign(from)
+	return tx
+}
+
+func TestApplyBlock(t *testing.T) {
+	sender, _ := account.NewKeyPair()
+	coinbase, _ := account.NewKeyPair()
+	bc := newTestChain(t, map[common.Address]int64{sender.Address(): 100000})
+
+	genesis := bc.Genesis()
+	assert.Equal(t, genesis, bc.Head())
+
+	txs := []*transaction.TxImpl{
+		newTransfer(t, sender, coinbase.Address(), 1, 5000, 10),
+		// wrong nonce, skipped.
+		newTransfer(t, sender, coinbase.Address(), 3, 5000, 10),
+		newTransfer(t, sender, coinbase.Address(), 2, 5000, 10),
+	}
+	b, err := bc.BuildBlock(coinbase.Address(), 1557360010, txs)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(b.Txs))
+	assert.Equal(t, block.CalcBaseFee(genesis.Header), b.Header.BaseFee)
+
+	// building a block does not change the state.
+	assert.Equal(t, genesis.Header.StateRoot, bc.state.Root())
+
+	assert.Nil(t, bc.ApplyBlock(b))
+	assert.Equal(t, b, bc.Head())
+	assert.Equal(t, b, bc.GetBlockByHeight(1))
+	assert.Nil(t, bc.GetBlockByHeight(2))
+
+	receipt := bc.GetReceipt(txs[0].Hash())
+	assert.NotNil(t, receipt)
+	assert.Equal(t, executor.ReceiptStatusSuccessful, receipt.Status)
+	assert.Nil(t, bc.GetReceipt(txs[1].Hash()))
+
+	acc, _ := bc.state.GetAccount(coinbase.Address())
+	assert.Equal(t, int64(2+2*10), acc.Balance().Int64())
+
+	burned := new(big.Int).Mul(b.Header.BaseFee, new(big.Int).SetUint64(b.Header.Weight)).Int64()
+	acc, _ = bc.state.GetAccount(sender.Address())
+	assert.Equal(t, 100000-2-2*10-burned, acc.Balance().Int64())
+
+	assert.Equal(t, errInvalidBlockHeight, bc.ApplyBlock(b))
+	assert.Equal(t, ErrStaleBlock, bc.VerifyBlock(b))
+}
+
+func TestApplyInvalidBlock(t *testing.T) {
+	sender, _ := account.NewKeyPair()
+	coinbase, _ := account.NewKeyPair()
+	bc := newTestChain(t, map[common.Address]int64{sender.Address(): 100000})
+	root := bc.state.Root()
+
+	build := func() *block.Block {
+		b, err := bc.BuildBlock(coinbase.Address(), 1557360010, []*transaction.TxImpl{newTransfer(t, sender, coinbase.Address(), 1, 5000, 10)})
+		assert.Nil(t, err)
+		return b
+	}
+
+	b := build()
+	b.Header.BaseFee = new(big.Int).Add(b.Header.BaseFee, big.NewInt(1))
+	assert.Equal(t, errInvalidBaseFee, bc.ApplyBlock(b))
+
+	b = build()
+	b.Header.Weight++
+	assert.Equal(t, errInvalidBlockWeight, bc.VerifyBlock(b))
+	assert.Equal(t, errInvalidBlockWeight, bc.ApplyBlock(b))
+
+	b = build()
+	b.Header.Weight = block.MaxBlockWeight + 1
+	assert.Equal(t, errBlockTooHeavy, bc.ApplyBlock(b))
+
+	// the state root is only verified by applying the txs.
+	b = build()
+	b.Header.StateRoot = common.Hash{}
+	assert.Nil(t, bc.VerifyBlock(b))
+	assert.Equal(t, errInvalidStateRoot, bc.ApplyBlock(b))
+	assert.Equal(t, root, bc.state.Root())
+
+	b = build()
+	b.Header.LogsBloom = block.Bloom{}
+	assert.Equal(t, errInvalidLogsBloom, bc.ApplyBlock(b))
+
+	b = build()
+	b.Header.Timestamp = -1
+	assert.Equal(t, errInvalidBlockTimestamp, bc.ApplyBlock(b))
+
+	b = build()
+	b.Header.Height++
+	assert.Equal(t, ErrFutureBlock, bc.VerifyBlock(b))
+
+	assert.Nil(t, bc.ApplyBlock(build()))
+}
+
+func TestTimeLockRelease(t *testing.T) {
+	sender, _ := account.NewKeyPair()
+	recipient, _ := account.NewKeyPair()
+	bc := newTestChain(t, map[common.Address]int64{sender.Address(): 100000, recipient.Address(): 0})
+
+	payload, _ := transaction.EncodeTimeLockPayload(2)
+	tx, err := transaction.NewTypedTransaction(transaction.TxTypeTimeLockTransfer, payload, common.TestnetConfig.ChainID, sender.Address(), sender.PublicKey,
+		recipient.Address(), big.NewInt(100), big.NewInt(5000), 1, 1557360000, nil)
+	assert.Nil(t, err)
+	tx.Sign(sender)
+
+	b, err := bc.BuildBlock(sender.Address(), 1557360010, []*transaction.TxImpl{tx})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(b.Txs))
+	assert.Nil(t, bc.ApplyBlock(b))
+	acc, _ := bc.state.GetAccount(recipient.Address())
+	assert.Equal(t, int64(0), acc.Balance().Int64())
+
+	// the time lock is released by the next block, which changes its state root.
+	b, err = bc.BuildBlock(sender.Address(), 1557360020, nil)
+	assert.Nil(t, err)
+	assert.NotEqual(t, bc.Head().Header.StateRoot, b.Header.StateRoot)
+	assert.Nil(t, bc.ApplyBlock(b))
+	acc, _ = bc.state.GetAccount(recipient.Address())
+	assert.Equal(t, int64(100), acc.Balance().Int64())
+}
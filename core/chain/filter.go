@@ -0,0 +1,119 @@
+package chain
+
+import (
+	"errors"
+
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/block"
+	"github.com/ldmtam/tam-chain/core/executor"
+)
+
+// MaxLogsBlockRange is the maximum number of blocks a logs filter can search.
+const MaxLogsBlockRange = 10000
+
+var (
+	errInvalidLogsRange = errors.New("invalid logs filter block range")
+)
+
+// LogFilter selects logs of a block range. A log matches if it's emitted by one
+// of Addresses and its topic at every position i has one of the values in
+// Topics[i]. Empty Addresses or Topics[i] match anything.
+type LogFilter struct {
+	FromHeight uint64
+	ToHeight   uint64
+	Addresses  []common.Address
+	Topics     [][][]byte
+}
+
+// bloomMatches returns false if no log of the block can match the filter.
+func (f *LogFilter) bloomMatches(bloom *block.Bloom) bool {
+	if len(f.Addresses) > 0 {
+		found := false
+		for _, address := range f.Addresses {
+			if bloom.Test(address.CloneBytes()) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, alternatives := range f.Topics {
+		if len(alternatives) == 0 {
+			continue
+		}
+		found := false
+		for _, topic := range alternatives {
+			if bloom.Test(topic) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *LogFilter) matches(log *executor.Log) bool {
+	if len(f.Addresses) > 0 {
+		found := false
+		for _, address := range f.Addresses {
+			if address.Equals(log.Address) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.Topics) > len(log.Topics) {
+		return false
+	}
+	for i, alternatives := range f.Topics {
+		if len(alternatives) == 0 {
+			continue
+		}
+		found := false
+		for _, topic := range alternatives {
+			if common.Equal(topic, log.Topics[i]) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// GetLogs returns logs matching the filter, in the order they were emitted.
+// Blocks whose logs bloom does not match the filter are skipped. The range is
+// cut at the head.
+func (bc *BlockChain) GetLogs(filter *LogFilter) ([]*executor.Log, error) {
+	if filter.ToHeight < filter.FromHeight || filter.ToHeight-filter.FromHeight >= MaxLogsBlockRange {
+		return nil, errInvalidLogsRange
+	}
+
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	logs := []*executor.Log{}
+	for height := filter.FromHeight; height <= filter.ToHeight && height < uint64(len(bc.blocks)); height++ {
+		if !filter.bloomMatches(&bc.blocks[height].Header.LogsBloom) {
+			continue
+		}
+		for _, receipt := range bc.receipts[height] {
+			for _, log := range receipt.Logs {
+				if filter.matches(log) {
+					logs = append(logs, log)
+				}
+			}
+		}
+	}
+	return logs, nil
+}
@@ -0,0 +1,55 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/ldmtam/tam-chain/account"
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/executor"
+	"github.com/ldmtam/tam-chain/core/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLogs(t *testing.T) {
+	sender, _ := account.NewKeyPair()
+	alice, _ := account.NewKeyPair()
+	bob, _ := account.NewKeyPair()
+	coinbase, _ := account.NewKeyPair()
+	bc := newTestChain(t, map[common.Address]int64{sender.Address(): 100000, alice.Address(): 0, bob.Address(): 0})
+
+	// block 1 pays alice, block 2 pays bob twice.
+	for i, txs := range [][]*transaction.TxImpl{
+		{newTransfer(t, sender, alice.Address(), 1, 5000, 10)},
+		{newTransfer(t, sender, bob.Address(), 2, 5000, 10), newTransfer(t, sender, bob.Address(), 3, 5000, 10)},
+	} {
+		b, err := bc.BuildBlock(coinbase.Address(), 1557360010+int64(i), txs)
+		assert.Nil(t, err)
+		assert.Nil(t, bc.ApplyBlock(b))
+	}
+	assert.True(t, bc.GetBlockByHeight(1).Header.LogsBloom.Test(alice.Address().CloneBytes()))
+	assert.False(t, bc.GetBlockByHeight(1).Header.LogsBloom.Test(bob.Address().CloneBytes()))
+
+	logs, err := bc.GetLogs(&LogFilter{FromHeight: 0, ToHeight: 100, Addresses: []common.Address{sender.Address()}})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(logs))
+	assert.Equal(t, uint64(1), logs[0].Height)
+
+	// the recipient is the second topic.
+	logs, err = bc.GetLogs(&LogFilter{FromHeight: 1, ToHeight: 2, Topics: [][][]byte{{executor.TransferTopic}, {bob.Address().CloneBytes()}}})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(logs))
+	assert.Equal(t, uint64(2), logs[1].Height)
+
+	logs, err = bc.GetLogs(&LogFilter{FromHeight: 1, ToHeight: 1, Topics: [][][]byte{nil, {bob.Address().CloneBytes(), alice.Address().CloneBytes()}}})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(logs))
+
+	logs, err = bc.GetLogs(&LogFilter{FromHeight: 0, ToHeight: 2, Addresses: []common.Address{alice.Address()}})
+	assert.Nil(t, err)
+	assert.Empty(t, logs)
+
+	_, err = bc.GetLogs(&LogFilter{FromHeight: 2, ToHeight: 1})
+	assert.Equal(t, errInvalidLogsRange, err)
+	_, err = bc.GetLogs(&LogFilter{FromHeight: 0, ToHeight: MaxLogsBlockRange})
+	assert.Equal(t, errInvalidLogsRange, err)
+}
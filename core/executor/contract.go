@@ -0,0 +1,137 @@
+package executor
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/state"
+	"github.com/ldmtam/tam-chain/core/transaction"
+	"github.com/ldmtam/tam-chain/core/vm"
+)
+
+var (
+	codePrefix    = []byte("code/")
+	storagePrefix = []byte("storage/")
+)
+
+var (
+	errContractExists   = errors.New("contract already exists")
+	errContractNotFound = errors.New("contract does not exist")
+)
+
+func codeKey(address common.Address) []byte {
+	return append(append([]byte{}, codePrefix...), address.CloneBytes()...)
+}
+
+// storageKey is unambiguous as addresses have a fixed length.
+func storageKey(address common.Address, key []byte) []byte {
+	k := append(append([]byte{}, storagePrefix...), address.CloneBytes()...)
+	return append(k, key...)
+}
+
+// GetCode returns code of the contract at address, nil if there is none.
+func GetCode(s *state.StateDB, address common.Address) []byte {
+	return s.Get(codeKey(address))
+}
+
+// GetStorage returns value of key in storage of the contract at address, nil if it does not exist.
+func GetStorage(s *state.StateDB, address common.Address, key []byte) []byte {
+	return s.Get(storageKey(address, key))
+}
+
+// contractHost gives a running contract access to the state.
+type contractHost struct {
+	state   *state.StateDB
+	address common.Address
+}
+
+func (h *contractHost) GetStorage(key []byte) []byte {
+	return GetStorage(h.state, h.address, key)
+}
+
+func (h *contractHost) SetStorage(key, value []byte) {
+	if len(value) == 0 {
+		h.state.Delete(storageKey(h.address, key))
+		return
+	}
+	h.state.Put(storageKey(h.address, key), value)
+}
+
+func (h *contractHost) Balance(address common.Address) (*big.Int, error) {
+	acc, err := h.state.GetAccount(address)
+	if err != nil {
+		return nil, err
+	}
+	return acc.Balance(), nil
+}
+
+func (h *contractHost) Transfer(to common.Address, amount *big.Int) error {
+	return transfer(h.state, h.address, to, amount)
+}
+
+// executeContractDeploy stores code at the `to` address, which is derived from
+// the sender and the nonce, and funds the contract with tx value.
+func executeContractDeploy(ctx *Context, tx *transaction.TxImpl, receipt *Receipt) error {
+	from, to := txAddresses(tx)
+	code, _, err := transaction.DecodeContractDeployPayload(tx.Payload())
+	if err != nil {
+		return err
+	}
+	if ctx.State.Has(codeKey(to)) {
+		return errContractExists
+	}
+
+	if err := transfer(ctx.State, from, to, tx.Value()); err != nil {
+		return err
+	}
+	transferLog(receipt, from, to, tx.Value())
+	ctx.State.Put(codeKey(to), code)
+	receipt.GasUsed = uint64(len(code)) * vm.GasPerCodeByte
+	return nil
+}
+
+// executeContractCall sends tx value to the contract and runs its code. If the
+// execution fails, its changes and the value transfer are reverted but the tx
+// is still applied with a failed receipt, so the sender pays for the gas.
+// Transfers made by the contract itself are not logged, contracts emit events
+// for them if they need to.
+func executeContractCall(ctx *Context, tx *transaction.TxImpl, receipt *Receipt) error {
+	from, to := txAddresses(tx)
+	args, gasLimit, err := transaction.DecodeContractCallPayload(tx.Payload())
+	if err != nil {
+		return err
+	}
+	code := GetCode(ctx.State, to)
+	if code == nil {
+		return errContractNotFound
+	}
+
+	snapshot := ctx.State.Snapshot()
+	if err := transfer(ctx.State, from, to, tx.Value()); err != nil {
+		return err
+	}
+	result := vm.Execute(code, &vm.Context{
+		Caller:    from,
+		Address:   to,
+		Value:     tx.Value(),
+		Input:     args,
+		Height:    ctx.Height,
+		Timestamp: ctx.Timestamp,
+		GasLimit:  gasLimit,
+	}, &contractHost{state: ctx.State, address: to})
+
+	receipt.GasUsed = result.GasUsed
+	receipt.Return = result.Return
+	if result.Err != nil {
+		ctx.State.RevertToSnapshot(snapshot)
+		receipt.Status = ReceiptStatusFailed
+		receipt.Error = result.Err.Error()
+		return nil
+	}
+	transferLog(receipt, from, to, tx.Value())
+	for _, event := range result.Events {
+		receipt.addLog(event.Address, event.Topics, event.Data)
+	}
+	return nil
+}
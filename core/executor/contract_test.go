@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/transaction"
+	"github.com/ldmtam/tam-chain/core/vm"
+	"github.com/stretchr/testify/assert"
+)
+
+func push(data []byte) []byte {
+	return append([]byte{byte(vm.PUSH), byte(len(data))}, data...)
+}
+
+// deploy deploys code with the next nonce and returns the contract address.
+func (env *testEnv) deploy(t *testing.T, code []byte, value int64) common.Address {
+	contract := common.NewContractAddress(env.sender.Address(), env.nonce+1)
+	payload, err := transaction.EncodeContractDeployPayload(code, 10000)
+	assert.Nil(t, err)
+	assert.Nil(t, env.apply(t, transaction.TxTypeContractDeploy, payload, contract, value))
+	return contract
+}
+
+func (env *testEnv) call(t *testing.T, contract common.Address, value int64, args ...[]byte) error {
+	payload, err := transaction.EncodeContractCallPayload(args, 10000)
+	assert.Nil(t, err)
+	return env.apply(t, transaction.TxTypeContractCall, payload, contract, value)
+}
+
+func TestContractCall(t *testing.T) {
+	env := newTestEnv(t)
+
+	// increments the counter, emits and returns its new value.
+	var code []byte
+	code = append(code, push([]byte("count"))...)
+	code = append(code, byte(vm.SLOAD))
+	code = append(code, push([]byte{1})...)
+	code = append(code, byte(vm.ADD), byte(vm.DUP), 0)
+	code = append(code, push([]byte("count"))...)
+	code = append(code, byte(vm.SSTORE), byte(vm.DUP), 0)
+	code = append(code, push([]byte("inc"))...)
+	code = append(code, byte(vm.EMIT), 1, byte(vm.RETURN))
+
+	contract := env.deploy(t, code, 0)
+	assert.Equal(t, code, GetCode(env.ctx.State, contract))
+	assert.Equal(t, uint64(len(code))*vm.GasPerCodeByte, env.receipt.GasUsed)
+
+	// the value transfer is logged before the event.
+	assert.Nil(t, env.call(t, contract, 5))
+	assert.Equal(t, 2, len(env.receipt.Logs))
+	assert.Equal(t, TransferTopic, env.receipt.Logs[0].Topics[0])
+	assert.Equal(t, contract, env.receipt.Logs[1].Address)
+	assert.Nil(t, env.call(t, contract, 0))
+	assert.Equal(t, ReceiptStatusSuccessful, env.receipt.Status)
+	assert.Equal(t, []byte{2}, env.receipt.Return)
+	assert.Equal(t, []byte{2}, GetStorage(env.ctx.State, contract, []byte("count")))
+	assert.Equal(t, 1, len(env.receipt.Logs))
+	assert.Equal(t, []byte("inc"), env.receipt.Logs[0].Topics[0])
+	assert.True(t, env.receipt.GasUsed > vm.GasSstore)
+	assert.Equal(t, int64(5), env.balance(contract))
+
+	// only addresses with code can be called.
+	assert.Equal(t, errContractNotFound, env.call(t, env.sender.Address(), 0))
+}
+
+func TestContractCallFailure(t *testing.T) {
+	env := newTestEnv(t)
+
+	// stores the value, then pays 100 to the caller which it cannot afford.
+	var code []byte
+	code = append(code, push([]byte{1})...)
+	code = append(code, push([]byte("key"))...)
+	code = append(code, byte(vm.SSTORE))
+	code = append(code, push([]byte{100})...)
+	code = append(code, byte(vm.CALLER), byte(vm.TRANSFER))
+
+	contract := env.deploy(t, code, 10)
+	balance := env.balance(env.sender.Address())
+
+	// the tx is applied with a failed receipt, only the fee is paid.
+	assert.Nil(t, env.call(t, contract, 50))
+	assert.Equal(t, ReceiptStatusFailed, env.receipt.Status)
+	assert.Equal(t, uint64(10000), env.receipt.GasUsed)
+	assert.NotEmpty(t, env.receipt.Error)
+	assert.Empty(t, env.receipt.Logs)
+	assert.Equal(t, balance-1, env.balance(env.sender.Address()))
+	assert.Equal(t, int64(10), env.balance(contract))
+	assert.Nil(t, GetStorage(env.ctx.State, contract, []byte("key")))
+
+	// with enough balance it pays out.
+	assert.Nil(t, env.call(t, contract, 100))
+	assert.Equal(t, ReceiptStatusSuccessful, env.receipt.Status)
+	assert.Equal(t, int64(10), env.balance(contract))
+	assert.Equal(t, []byte{1}, GetStorage(env.ctx.State, contract, []byte("key")))
+}
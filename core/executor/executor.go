@@ -0,0 +1,151 @@
+package executor
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/state"
+	"github.com/ldmtam/tam-chain/core/transaction"
+)
+
+var (
+	errTxChainIDMismatch = errors.New("transaction chain id does not match")
+	errInvalidNonce      = errors.New("invalid transaction nonce")
+	errNoTxHandler       = errors.New("no handler for transaction type")
+)
+
+// Context is the environment in which txs are executed.
+type Context struct {
+	State     *state.StateDB
+	Height    uint64
+	Timestamp int64
+
+	// Coinbase receives tx tips, tips are burned if it's not set.
+	Coinbase common.Address
+	// BaseFee is the fee per weight unit every tx burns.
+	BaseFee *big.Int
+}
+
+// TxHandler applies the type-specific state transition of a tx and fills in
+// receipt. Nonce and fee of the sender are already handled by the executor
+// when it's called.
+type TxHandler func(ctx *Context, tx *transaction.TxImpl, receipt *Receipt) error
+
+// Executor applies txs to the state.
+type Executor struct {
+	config   *common.ChainConfig
+	handlers map[transaction.TxType]TxHandler
+}
+
+// NewExecutor returns a new Executor with handlers of all built-in tx types.
+func NewExecutor(config *common.ChainConfig) *Executor {
+	e := &Executor{
+		config:   config,
+		handlers: make(map[transaction.TxType]TxHandler),
+	}
+	e.RegisterTxHandler(transaction.TxTypeTransfer, executeTransfer)
+	e.RegisterTxHandler(transaction.TxTypeCreateAccount, executeCreateAccount)
+	e.RegisterTxHandler(transaction.TxTypeRotateKey, executeRotateKey)
+	e.RegisterTxHandler(transaction.TxTypeRegisterValidator, executeRegisterValidator)
+	e.RegisterTxHandler(transaction.TxTypeAnchorData, executeAnchorData)
+	e.RegisterTxHandler(transaction.TxTypeTimeLockTransfer, executeTimeLockTransfer)
+	e.RegisterTxHandler(transaction.TxTypeCancelTimeLock, executeCancelTimeLock)
+	e.RegisterTxHandler(transaction.TxTypeHTLCLock, executeHTLCLock)
+	e.RegisterTxHandler(transaction.TxTypeHTLCClaim, executeHTLCClaim)
+	e.RegisterTxHandler(transaction.TxTypeHTLCRefund, executeHTLCRefund)
+	e.RegisterTxHandler(transaction.TxTypeContractDeploy, executeContractDeploy)
+	e.RegisterTxHandler(transaction.TxTypeContractCall, executeContractCall)
+	return e
+}
+
+// RegisterTxHandler sets the handler of a tx type.
+func (e *Executor) RegisterTxHandler(txType transaction.TxType, handler TxHandler) {
+	e.handlers[txType] = handler
+}
+
+// BeginBlock applies the state transitions which are due at the start of a
+// block before its txs, i.e. releasing time locks whose unlock bound is reached.
+func (e *Executor) BeginBlock(ctx *Context) error {
+	return releaseTimeLocks(ctx)
+}
+
+// ApplyTx verifies and applies tx to ctx.State and returns its receipt. Either
+// all changes of the tx are applied or none of them is.
+func (e *Executor) ApplyTx(ctx *Context, tx *transaction.TxImpl) (receipt *Receipt, err error) {
+	if tx.ChainID() != e.config.ChainID {
+		return nil, errTxChainIDMismatch
+	}
+	if err := tx.VerifyWindow(ctx.Height, ctx.Timestamp); err != nil {
+		return nil, err
+	}
+	if err := tx.VerifyIntegrity(ctx.State); err != nil {
+		return nil, err
+	}
+	handler, ok := e.handlers[tx.Type()]
+	if !ok {
+		return nil, errNoTxHandler
+	}
+
+	snapshot := ctx.State.Snapshot()
+	defer func() {
+		if err != nil {
+			ctx.State.RevertToSnapshot(snapshot)
+		}
+	}()
+
+	fee, err := chargeSender(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	receipt = &Receipt{
+		TxHash: tx.Hash(),
+		Height: ctx.Height,
+		Status: ReceiptStatusSuccessful,
+		Fee:    fee,
+	}
+	if err := handler(ctx, tx, receipt); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// chargeSender checks and increases nonce of the sender, then burns the base
+// fee and pays the tip to the coinbase. It returns the fee paid.
+func chargeSender(ctx *Context, tx *transaction.TxImpl) (*big.Int, error) {
+	var from common.Address
+	from.SetBytes(tx.From())
+
+	sender, err := ctx.State.GetAccount(from)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Nonce() != sender.Nonce()+1 {
+		return nil, errInvalidNonce
+	}
+	baseFee := ctx.BaseFee
+	if baseFee == nil {
+		baseFee = new(big.Int)
+	}
+	fee, burned, err := tx.EffectiveFee(baseFee)
+	if err != nil {
+		return nil, err
+	}
+	if err := sender.SubFromBalance(fee); err != nil {
+		return nil, err
+	}
+	sender.IncreaseNonce()
+	if err := ctx.State.PutAccount(sender); err != nil {
+		return nil, err
+	}
+
+	if ctx.Coinbase.IsValid() == false {
+		return fee, nil
+	}
+	coinbase, err := ctx.State.GetAccount(ctx.Coinbase)
+	if err != nil {
+		return nil, err
+	}
+	coinbase.AddToBalance(new(big.Int).Sub(fee, burned))
+	return fee, ctx.State.PutAccount(coinbase)
+}
@@ -0,0 +1,260 @@
+package executor
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/ldmtam/tam-chain/account"
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/state"
+	"github.com/ldmtam/tam-chain/core/transaction"
+	"github.com/ldmtam/tam-chain/crypto/bls"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	chainConfig = common.TestnetConfig
+)
+
+type testEnv struct {
+	executor *Executor
+	ctx      *Context
+	sender   *account.KeyPairImpl
+	signer   *account.KeyPairImpl
+	nonce    uint64
+	// receipt of the last applied tx.
+	receipt *Receipt
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	sender, err := account.NewKeyPair()
+	assert.Nil(t, err)
+
+	coinbase, err := account.NewKeyPair()
+	assert.Nil(t, err)
+
+	env := &testEnv{
+		executor: NewExecutor(chainConfig),
+		ctx: &Context{
+			State:     state.NewStateDB(),
+			Height:    10,
+			Timestamp: 1557360000,
+			Coinbase:  coinbase.Address(),
+		},
+		sender: sender,
+		signer: sender,
+	}
+	env.fund(sender.Address(), 1000)
+	return env
+}
+
+func (env *testEnv) fund(addr common.Address, amount int64) {
+	acc, _ := env.ctx.State.GetAccount(addr)
+	acc.AddToBalance(big.NewInt(amount))
+	env.ctx.State.PutAccount(acc)
+}
+
+// newRecipient returns the key pair of a new empty account.
+func (env *testEnv) newRecipient(t *testing.T) *account.KeyPairImpl {
+	kp, err := account.NewKeyPair()
+	assert.Nil(t, err)
+	env.fund(kp.Address(), 0)
+	return kp
+}
+
+func (env *testEnv) balance(addr common.Address) int64 {
+	acc, _ := env.ctx.State.GetAccount(addr)
+	return acc.Balance().Int64()
+}
+
+// newTx returns a signed tx of the sender with the next nonce.
+func (env *testEnv) newTx(t *testing.T, txType transaction.TxType, payload []byte, to common.Address, value int64) *transaction.TxImpl {
+	tx, err := transaction.NewTypedTransaction(txType, payload, chainConfig.ChainID, env.sender.Address(), env.signer.PublicKey, to,
+		big.NewInt(value), big.NewInt(1), env.nonce+1, env.ctx.Timestamp, nil)
+	assert.Nil(t, err)
+	// base fee is zero, the whole fee is tip.
+	assert.Nil(t, tx.SetTip(big.NewInt(1)))
+	tx.Sign(env.signer)
+	return tx
+}
+
+func (env *testEnv) applyTx(tx *transaction.TxImpl) error {
+	receipt, err := env.executor.ApplyTx(env.ctx, tx)
+	if err == nil {
+		env.nonce++
+		env.receipt = receipt
+	}
+	return err
+}
+
+func (env *testEnv) apply(t *testing.T, txType transaction.TxType, payload []byte, to common.Address, value int64) error {
+	return env.applyTx(env.newTx(t, txType, payload, to, value))
+}
+
+func TestTransfer(t *testing.T) {
+	env := newTestEnv(t)
+	recipient := env.newRecipient(t)
+
+	assert.Nil(t, env.apply(t, transaction.TxTypeTransfer, nil, recipient.Address(), 100))
+	assert.Equal(t, int64(899), env.balance(env.sender.Address()))
+	assert.Equal(t, int64(100), env.balance(recipient.Address()))
+	assert.Equal(t, int64(1), env.balance(env.ctx.Coinbase))
+
+	assert.Equal(t, ReceiptStatusSuccessful, env.receipt.Status)
+	assert.Equal(t, int64(1), env.receipt.Fee.Int64())
+	assert.Equal(t, 1, len(env.receipt.Logs))
+	log := env.receipt.Logs[0]
+	assert.Equal(t, env.sender.Address(), log.Address)
+	assert.Equal(t, [][]byte{TransferTopic, recipient.Address().CloneBytes()}, log.Topics)
+	assert.Equal(t, []byte{100}, log.Data)
+	assert.Equal(t, env.ctx.Height, log.Height)
+
+	bloom := CreateBloom([]*Receipt{env.receipt})
+	assert.True(t, bloom.Test(env.sender.Address().CloneBytes()))
+	assert.True(t, bloom.Test(recipient.Address().CloneBytes()))
+	assert.False(t, bloom.Test(env.ctx.Coinbase.CloneBytes()))
+
+	// a failed tx changes nothing, not even the nonce.
+	root := env.ctx.State.Root()
+	assert.Equal(t, state.ErrBalanceInsufficient, env.apply(t, transaction.TxTypeTransfer, nil, recipient.Address(), 899))
+	assert.Equal(t, root, env.ctx.State.Root())
+
+	// nonce must be the next one.
+	env.nonce++
+	assert.Equal(t, errInvalidNonce, env.apply(t, transaction.TxTypeTransfer, nil, recipient.Address(), 1))
+}
+
+func TestCreateAccountAndRotateKey(t *testing.T) {
+	env := newTestEnv(t)
+	owner, _ := account.NewKeyPair()
+	backup, _ := account.NewKeyPair()
+
+	// a transfer cannot create the account ahead of the create account tx.
+	assert.Equal(t, errRecipientNotFound, env.apply(t, transaction.TxTypeTransfer, nil, owner.Address(), 1))
+	assert.False(t, env.ctx.State.HasAccount(owner.Address()))
+
+	payload, err := transaction.EncodeKeysPayload([][]byte{owner.PublicKey, backup.PublicKey})
+	assert.Nil(t, err)
+	assert.Nil(t, env.apply(t, transaction.TxTypeCreateAccount, payload, owner.Address(), 50))
+
+	acc, err := env.ctx.State.GetAccount(owner.Address())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(50), acc.Balance().Int64())
+	assert.Equal(t, [][]byte{[]byte(owner.PublicKey), []byte(backup.PublicKey)}, acc.Keys())
+
+	// an account can only be created once.
+	assert.Equal(t, errAccountExists, env.apply(t, transaction.TxTypeCreateAccount, payload, owner.Address(), 50))
+
+	payload, err = transaction.EncodeKeysPayload([][]byte{backup.PublicKey})
+	assert.Nil(t, err)
+	assert.Nil(t, env.apply(t, transaction.TxTypeRotateKey, payload, env.sender.Address(), 0))
+
+	acc, err = env.ctx.State.GetAccount(env.sender.Address())
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte(backup.PublicKey)}, acc.Keys())
+
+	// the old key cannot sign for the account anymore, the new key can.
+	assert.NotNil(t, env.apply(t, transaction.TxTypeTransfer, nil, owner.Address(), 1))
+	env.signer = backup
+	assert.Nil(t, env.apply(t, transaction.TxTypeTransfer, nil, owner.Address(), 1))
+}
+
+func TestRegisterValidator(t *testing.T) {
+	env := newTestEnv(t)
+	sk, err := bls.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+	other, err := bls.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+
+	payload, err := transaction.EncodeValidatorPayload(sk.PublicKey(), sk.ProvePossession())
+	assert.Nil(t, err)
+	assert.Nil(t, env.apply(t, transaction.TxTypeRegisterValidator, payload, env.sender.Address(), 300))
+	assert.Nil(t, env.apply(t, transaction.TxTypeRegisterValidator, payload, env.sender.Address(), 200))
+	assert.Equal(t, int64(498), env.balance(env.sender.Address()))
+
+	validator, err := GetValidator(env.ctx.State, env.sender.Address())
+	assert.Nil(t, err)
+	assert.Equal(t, sk.PublicKey().Marshal(), validator.BlsPublicKey)
+	assert.Equal(t, int64(500), new(big.Int).SetBytes(validator.Stake).Int64())
+
+	payload, err = transaction.EncodeValidatorPayload(other.PublicKey(), other.ProvePossession())
+	assert.Nil(t, err)
+	assert.Equal(t, errValidatorKeyMismatch, env.apply(t, transaction.TxTypeRegisterValidator, payload, env.sender.Address(), 100))
+}
+
+func TestAnchorData(t *testing.T) {
+	env := newTestEnv(t)
+
+	var dataHash common.Hash
+	dataHash.SetBytes(make([]byte, common.HashLength))
+	dataHash[31] = 7
+
+	anchor, err := GetAnchor(env.ctx.State, dataHash)
+	assert.Nil(t, err)
+	assert.Nil(t, anchor)
+
+	payload, err := transaction.EncodeAnchorPayload(dataHash)
+	assert.Nil(t, err)
+	assert.Nil(t, env.apply(t, transaction.TxTypeAnchorData, payload, env.sender.Address(), 0))
+
+	anchor, err = GetAnchor(env.ctx.State, dataHash)
+	assert.Nil(t, err)
+	assert.Equal(t, env.sender.Address().CloneBytes(), anchor.Owner)
+	assert.Equal(t, env.ctx.Height, anchor.Height)
+
+	assert.Equal(t, errDataAlreadyAnchored, env.apply(t, transaction.TxTypeAnchorData, payload, env.sender.Address(), 0))
+}
+
+func TestUnknownTxType(t *testing.T) {
+	env := newTestEnv(t)
+	delete(env.executor.handlers, transaction.TxTypeAnchorData)
+
+	var dataHash common.Hash
+	payload, err := transaction.EncodeAnchorPayload(dataHash)
+	assert.Nil(t, err)
+	assert.Equal(t, errNoTxHandler, env.apply(t, transaction.TxTypeAnchorData, payload, env.sender.Address(), 0))
+}
+
+func TestValidityWindow(t *testing.T) {
+	env := newTestEnv(t)
+	recipient := env.newRecipient(t)
+
+	tx, err := transaction.NewTransaction(chainConfig.ChainID, env.sender.PublicKey, recipient.Address(), big.NewInt(1), big.NewInt(1), 1, env.ctx.Timestamp, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, tx.SetValidityWindow(env.ctx.Height+1, env.ctx.Height+2))
+	tx.Sign(env.sender)
+
+	assert.NotNil(t, env.applyTx(tx))
+
+	env.ctx.Height += 3
+	assert.NotNil(t, env.applyTx(tx))
+
+	env.ctx.Height--
+	assert.Nil(t, env.applyTx(tx))
+}
+
+func TestBaseFee(t *testing.T) {
+	env := newTestEnv(t)
+	env.ctx.BaseFee = big.NewInt(2)
+	recipient := env.newRecipient(t)
+
+	newTx := func(fee, tip int64) *transaction.TxImpl {
+		tx, err := transaction.NewTransaction(chainConfig.ChainID, env.sender.PublicKey, recipient.Address(), big.NewInt(1), big.NewInt(fee), env.nonce+1, env.ctx.Timestamp, nil)
+		assert.Nil(t, err)
+		assert.Nil(t, tx.SetTip(big.NewInt(tip)))
+		tx.Sign(env.sender)
+		return tx
+	}
+
+	// base fee is burned, tip goes to coinbase.
+	tx := newTx(800, 5)
+	burned := int64(2 * tx.Weight())
+	assert.Nil(t, env.applyTx(tx))
+	assert.Equal(t, 1000-1-burned-5, env.balance(env.sender.Address()))
+	assert.Equal(t, int64(5), env.balance(env.ctx.Coinbase))
+
+	// max fee must cover base fee.
+	tx = newTx(10, 0)
+	assert.NotNil(t, env.applyTx(tx))
+}
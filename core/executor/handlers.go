@@ -0,0 +1,223 @@
+package executor
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/state"
+	"github.com/ldmtam/tam-chain/core/transaction"
+	"github.com/ldmtam/tam-chain/proto"
+)
+
+var (
+	validatorPrefix = []byte("validator/")
+	anchorPrefix    = []byte("anchor/")
+)
+
+var (
+	errAccountExists        = errors.New("account already exists")
+	errRecipientNotFound    = errors.New("recipient account does not exist")
+	errValidatorKeyMismatch = errors.New("validator is registered with another bls key")
+	errDataAlreadyAnchored  = errors.New("data hash is already anchored")
+	errInvalidStateRecord   = errors.New("state record cannot be decoded")
+)
+
+func txAddresses(tx *transaction.TxImpl) (from, to common.Address) {
+	from.SetBytes(tx.From())
+	to.SetBytes(tx.To())
+	return from, to
+}
+
+// transfer moves value between two accounts.
+func transfer(s *state.StateDB, from, to common.Address, value *big.Int) error {
+	sender, err := s.GetAccount(from)
+	if err != nil {
+		return err
+	}
+	if err := sender.SubFromBalance(value); err != nil {
+		return err
+	}
+	if err := s.PutAccount(sender); err != nil {
+		return err
+	}
+
+	recipient, err := s.GetAccount(to)
+	if err != nil {
+		return err
+	}
+	recipient.AddToBalance(value)
+	return s.PutAccount(recipient)
+}
+
+// checkRecipient checks that value is sent to an existing account. Only
+// create account txs create accounts, otherwise anyone could make a create
+// account tx fail by sending a tiny value to the new address first.
+func checkRecipient(s *state.StateDB, to common.Address) error {
+	if !s.HasAccount(to) {
+		return errRecipientNotFound
+	}
+	return nil
+}
+
+// transferLog logs a value transfer of tx, so the accounts can be followed with
+// a logs filter. Transfers of zero are not logged.
+func transferLog(receipt *Receipt, from, to common.Address, value *big.Int) {
+	if value.Sign() == 0 {
+		return
+	}
+	receipt.addLog(from, [][]byte{TransferTopic, to.CloneBytes()}, value.Bytes())
+}
+
+func executeTransfer(ctx *Context, tx *transaction.TxImpl, receipt *Receipt) error {
+	from, to := txAddresses(tx)
+	if err := checkRecipient(ctx.State, to); err != nil {
+		return err
+	}
+	if err := transfer(ctx.State, from, to, tx.Value()); err != nil {
+		return err
+	}
+	transferLog(receipt, from, to, tx.Value())
+	return nil
+}
+
+func executeCreateAccount(ctx *Context, tx *transaction.TxImpl, receipt *Receipt) error {
+	from, to := txAddresses(tx)
+	if ctx.State.HasAccount(to) {
+		return errAccountExists
+	}
+	keys, err := transaction.DecodeKeysPayload(tx.Payload())
+	if err != nil {
+		return err
+	}
+
+	if err := transfer(ctx.State, from, to, tx.Value()); err != nil {
+		return err
+	}
+	transferLog(receipt, from, to, tx.Value())
+	acc, err := ctx.State.GetAccount(to)
+	if err != nil {
+		return err
+	}
+	acc.SetKeys(keys)
+	return ctx.State.PutAccount(acc)
+}
+
+func executeRotateKey(ctx *Context, tx *transaction.TxImpl, receipt *Receipt) error {
+	from, _ := txAddresses(tx)
+	keys, err := transaction.DecodeKeysPayload(tx.Payload())
+	if err != nil {
+		return err
+	}
+
+	acc, err := ctx.State.GetAccount(from)
+	if err != nil {
+		return err
+	}
+	acc.SetKeys(keys)
+	return ctx.State.PutAccount(acc)
+}
+
+func validatorKey(address common.Address) []byte {
+	return append(append([]byte{}, validatorPrefix...), address.CloneBytes()...)
+}
+
+// GetValidator returns the validator registered by address, nil if there is none.
+func GetValidator(s *state.StateDB, address common.Address) (*corepb.Validator, error) {
+	data := s.Get(validatorKey(address))
+	if data == nil {
+		return nil, nil
+	}
+	validator := &corepb.Validator{}
+	if err := proto.Unmarshal(data, validator); err != nil {
+		return nil, errInvalidStateRecord
+	}
+	return validator, nil
+}
+
+// executeRegisterValidator locks tx value as stake of the sender. Registering
+// again with the same bls key tops up the stake.
+func executeRegisterValidator(ctx *Context, tx *transaction.TxImpl, receipt *Receipt) error {
+	from, _ := txAddresses(tx)
+	blsKey, _, err := transaction.DecodeValidatorPayload(tx.Payload())
+	if err != nil {
+		return err
+	}
+
+	validator, err := GetValidator(ctx.State, from)
+	if err != nil {
+		return err
+	}
+	if validator == nil {
+		validator = &corepb.Validator{
+			Address:      from.CloneBytes(),
+			BlsPublicKey: blsKey.Marshal(),
+		}
+	}
+	if common.Equal(validator.BlsPublicKey, blsKey.Marshal()) == false {
+		return errValidatorKeyMismatch
+	}
+
+	sender, err := ctx.State.GetAccount(from)
+	if err != nil {
+		return err
+	}
+	if err := sender.SubFromBalance(tx.Value()); err != nil {
+		return err
+	}
+	if err := ctx.State.PutAccount(sender); err != nil {
+		return err
+	}
+
+	stake := new(big.Int).SetBytes(validator.Stake)
+	validator.Stake = stake.Add(stake, tx.Value()).Bytes()
+
+	data, err := proto.Marshal(validator)
+	if err != nil {
+		return err
+	}
+	ctx.State.Put(validatorKey(from), data)
+	return nil
+}
+
+func anchorKey(dataHash common.Hash) []byte {
+	return append(append([]byte{}, anchorPrefix...), dataHash.CloneBytes()...)
+}
+
+// GetAnchor returns the anchor of data hash, nil if the hash is not anchored.
+func GetAnchor(s *state.StateDB, dataHash common.Hash) (*corepb.Anchor, error) {
+	data := s.Get(anchorKey(dataHash))
+	if data == nil {
+		return nil, nil
+	}
+	anchor := &corepb.Anchor{}
+	if err := proto.Unmarshal(data, anchor); err != nil {
+		return nil, errInvalidStateRecord
+	}
+	return anchor, nil
+}
+
+// executeAnchorData records who anchored the data hash and when, a hash can only be anchored once.
+func executeAnchorData(ctx *Context, tx *transaction.TxImpl, receipt *Receipt) error {
+	from, _ := txAddresses(tx)
+	dataHash, err := transaction.DecodeAnchorPayload(tx.Payload())
+	if err != nil {
+		return err
+	}
+	if ctx.State.Has(anchorKey(dataHash)) {
+		return errDataAlreadyAnchored
+	}
+
+	data, err := proto.Marshal(&corepb.Anchor{
+		Owner:     from.CloneBytes(),
+		DataHash:  dataHash.CloneBytes(),
+		Height:    ctx.Height,
+		Timestamp: ctx.Timestamp,
+	})
+	if err != nil {
+		return err
+	}
+	ctx.State.Put(anchorKey(dataHash), data)
+	return nil
+}
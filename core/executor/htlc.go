@@ -0,0 +1,175 @@
+package executor
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/state"
+	"github.com/ldmtam/tam-chain/core/transaction"
+	"github.com/ldmtam/tam-chain/proto"
+)
+
+var (
+	htlcPrefix = []byte("htlc/")
+)
+
+var (
+	errHTLCNotFound     = errors.New("htlc does not exist")
+	errHTLCClosed       = errors.New("htlc is already claimed or refunded")
+	errHTLCTimedOut     = errors.New("htlc is timed out")
+	errHTLCNotTimedOut  = errors.New("htlc is not timed out yet")
+	errNotHTLCRecipient = errors.New("htlc can only be claimed by its recipient")
+	errNotHTLCSender    = errors.New("htlc can only be refunded to its sender")
+	errPreimageMismatch = errors.New("preimage does not match the hashlock")
+)
+
+func htlcKey(id common.Hash) []byte {
+	return append(append([]byte{}, htlcPrefix...), id.CloneBytes()...)
+}
+
+// GetHTLC returns the contract of id, nil if there is none. Claimed and
+// refunded contracts are kept, so the preimage of a claim can be read.
+func GetHTLC(s *state.StateDB, id common.Hash) (*corepb.HTLC, error) {
+	data := s.Get(htlcKey(id))
+	if data == nil {
+		return nil, nil
+	}
+	htlc := &corepb.HTLC{}
+	if err := proto.Unmarshal(data, htlc); err != nil {
+		return nil, errInvalidStateRecord
+	}
+	return htlc, nil
+}
+
+func putHTLC(s *state.StateDB, htlc *corepb.HTLC) error {
+	data, err := proto.Marshal(htlc)
+	if err != nil {
+		return err
+	}
+	var id common.Hash
+	id.SetBytes(htlc.Id)
+	s.Put(htlcKey(id), data)
+	return nil
+}
+
+// executeHTLCLock escrows tx value from the sender, the contract is identified by the tx hash.
+func executeHTLCLock(ctx *Context, tx *transaction.TxImpl, receipt *Receipt) error {
+	from, to := txAddresses(tx)
+	lock, err := transaction.DecodeHTLCLockPayload(tx.Payload())
+	if err != nil {
+		return err
+	}
+	if ctx.Height >= lock.TimeoutHeight {
+		return errHTLCTimedOut
+	}
+	if err := checkRecipient(ctx.State, to); err != nil {
+		return err
+	}
+
+	sender, err := ctx.State.GetAccount(from)
+	if err != nil {
+		return err
+	}
+	if err := sender.SubFromBalance(tx.Value()); err != nil {
+		return err
+	}
+	if err := ctx.State.PutAccount(sender); err != nil {
+		return err
+	}
+
+	id := tx.Hash()
+	return putHTLC(ctx.State, &corepb.HTLC{
+		Id:            id.CloneBytes(),
+		Sender:        from.CloneBytes(),
+		Recipient:     to.CloneBytes(),
+		Amount:        tx.Value().Bytes(),
+		HashAlgorithm: corepb.HashAlgorithm(lock.HashAlgorithm),
+		HashLock:      lock.HashLock.CloneBytes(),
+		TimeoutHeight: lock.TimeoutHeight,
+		Height:        ctx.Height,
+	})
+}
+
+// executeHTLCClaim pays a contract to its recipient, who reveals the preimage
+// of the hashlock before the timeout.
+func executeHTLCClaim(ctx *Context, tx *transaction.TxImpl, receipt *Receipt) error {
+	from, _ := txAddresses(tx)
+	id, preimage, err := transaction.DecodeHTLCClaimPayload(tx.Payload())
+	if err != nil {
+		return err
+	}
+
+	htlc, err := getLockedHTLC(ctx.State, id)
+	if err != nil {
+		return err
+	}
+	if common.Equal(htlc.Recipient, from.CloneBytes()) == false {
+		return errNotHTLCRecipient
+	}
+	if ctx.Height >= htlc.TimeoutHeight {
+		return errHTLCTimedOut
+	}
+	hash, err := transaction.HashAlgorithm(htlc.HashAlgorithm).Sum(preimage)
+	if err != nil {
+		return err
+	}
+	if common.Equal(hash.CloneBytes(), htlc.HashLock) == false {
+		return errPreimageMismatch
+	}
+
+	htlc.Status = corepb.HTLCStatus_CLAIMED
+	htlc.Preimage = preimage
+	return closeHTLC(ctx.State, htlc, from)
+}
+
+// executeHTLCRefund returns a timed out contract to its sender.
+func executeHTLCRefund(ctx *Context, tx *transaction.TxImpl, receipt *Receipt) error {
+	from, _ := txAddresses(tx)
+	id, err := transaction.DecodeHTLCRefundPayload(tx.Payload())
+	if err != nil {
+		return err
+	}
+
+	htlc, err := getLockedHTLC(ctx.State, id)
+	if err != nil {
+		return err
+	}
+	if common.Equal(htlc.Sender, from.CloneBytes()) == false {
+		return errNotHTLCSender
+	}
+	if ctx.Height < htlc.TimeoutHeight {
+		return errHTLCNotTimedOut
+	}
+
+	htlc.Status = corepb.HTLCStatus_REFUNDED
+	return closeHTLC(ctx.State, htlc, from)
+}
+
+func getLockedHTLC(s *state.StateDB, id common.Hash) (*corepb.HTLC, error) {
+	htlc, err := GetHTLC(s, id)
+	if err != nil {
+		return nil, err
+	}
+	if htlc == nil {
+		return nil, errHTLCNotFound
+	}
+	if htlc.Status != corepb.HTLCStatus_LOCKED {
+		return nil, errHTLCClosed
+	}
+	return htlc, nil
+}
+
+// closeHTLC pays the escrowed value to payee and saves the final status of the contract.
+func closeHTLC(s *state.StateDB, htlc *corepb.HTLC, payee common.Address) error {
+	acc, err := s.GetAccount(payee)
+	if err != nil {
+		return err
+	}
+	acc.AddToBalance(new(big.Int).SetBytes(htlc.Amount))
+	if err := s.PutAccount(acc); err != nil {
+		return err
+	}
+	return putHTLC(s, htlc)
+}
@@ -0,0 +1,81 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/transaction"
+	"github.com/ldmtam/tam-chain/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+// lockHTLC locks 100 to recipient under the hash of preimage, it returns the
+// contract id, which is the hash of the lock tx.
+func (env *testEnv) lockHTLC(t *testing.T, recipient common.Address, preimage []byte, timeout uint64) common.Hash {
+	hashLock, _ := transaction.HashSHA3256.Sum(preimage)
+	payload, _ := transaction.EncodeHTLCLockPayload(&transaction.HTLCLock{
+		HashAlgorithm: transaction.HashSHA3256,
+		HashLock:      hashLock,
+		TimeoutHeight: timeout,
+	})
+	tx := env.newTx(t, transaction.TxTypeHTLCLock, payload, recipient, 100)
+	assert.Nil(t, env.applyTx(tx))
+	return tx.Hash()
+}
+
+func TestHTLCClaim(t *testing.T) {
+	env := newTestEnv(t)
+	recipient := newTestEnv(t)
+	recipient.ctx.State = env.ctx.State
+	recipient.fund(recipient.sender.Address(), 10)
+
+	preimage := []byte("secret")
+	id := env.lockHTLC(t, recipient.sender.Address(), preimage, env.ctx.Height+10)
+	assert.Equal(t, int64(1000-100-1), env.balance(env.sender.Address()))
+
+	htlc, err := GetHTLC(env.ctx.State, id)
+	assert.Nil(t, err)
+	assert.Equal(t, corepb.HTLCStatus_LOCKED, htlc.Status)
+
+	// only the recipient with the right preimage can claim.
+	claim, _ := transaction.EncodeHTLCClaimPayload(id, preimage)
+	assert.Equal(t, errNotHTLCRecipient, env.apply(t, transaction.TxTypeHTLCClaim, claim, env.sender.Address(), 0))
+	wrong, _ := transaction.EncodeHTLCClaimPayload(id, []byte("guess"))
+	assert.Equal(t, errPreimageMismatch, recipient.apply(t, transaction.TxTypeHTLCClaim, wrong, recipient.sender.Address(), 0))
+
+	assert.Nil(t, recipient.apply(t, transaction.TxTypeHTLCClaim, claim, recipient.sender.Address(), 0))
+	assert.Equal(t, int64(10-1+100), env.balance(recipient.sender.Address()))
+
+	// the preimage is revealed for the counterparty.
+	htlc, _ = GetHTLC(env.ctx.State, id)
+	assert.Equal(t, corepb.HTLCStatus_CLAIMED, htlc.Status)
+	assert.Equal(t, preimage, htlc.Preimage)
+
+	assert.Equal(t, errHTLCClosed, recipient.apply(t, transaction.TxTypeHTLCClaim, claim, recipient.sender.Address(), 0))
+	env.ctx.Height += 10
+	refund, _ := transaction.EncodeHTLCRefundPayload(id)
+	assert.Equal(t, errHTLCClosed, env.apply(t, transaction.TxTypeHTLCRefund, refund, env.sender.Address(), 0))
+}
+
+func TestHTLCRefund(t *testing.T) {
+	env := newTestEnv(t)
+	recipient := env.newRecipient(t)
+
+	preimage := []byte("secret")
+	id := env.lockHTLC(t, recipient.Address(), preimage, env.ctx.Height+10)
+
+	refund, _ := transaction.EncodeHTLCRefundPayload(id)
+	assert.Equal(t, errHTLCNotTimedOut, env.apply(t, transaction.TxTypeHTLCRefund, refund, env.sender.Address(), 0))
+
+	env.ctx.Height += 10
+	assert.Nil(t, env.apply(t, transaction.TxTypeHTLCRefund, refund, env.sender.Address(), 0))
+	assert.Equal(t, int64(1000-2), env.balance(env.sender.Address()))
+
+	htlc, _ := GetHTLC(env.ctx.State, id)
+	assert.Equal(t, corepb.HTLCStatus_REFUNDED, htlc.Status)
+
+	// a contract cannot be created timed out.
+	hashLock, _ := transaction.HashSHA3256.Sum(preimage)
+	payload, _ := transaction.EncodeHTLCLockPayload(&transaction.HTLCLock{HashLock: hashLock, TimeoutHeight: env.ctx.Height})
+	assert.Equal(t, errHTLCTimedOut, env.apply(t, transaction.TxTypeHTLCLock, payload, recipient.Address(), 100))
+}
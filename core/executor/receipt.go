@@ -0,0 +1,71 @@
+package executor
+
+import (
+	"math/big"
+
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/block"
+)
+
+// Receipt statuses
+const (
+	ReceiptStatusFailed uint8 = iota
+	ReceiptStatusSuccessful
+)
+
+var (
+	// TransferTopic is the first topic of the logs of value transfers between
+	// accounts, the second topic is the recipient and data is the value.
+	TransferTopic = []byte("transfer")
+)
+
+// Log is an event of a tx, emitted by a contract or by the executor itself.
+type Log struct {
+	Address common.Address
+	Topics  [][]byte
+	Data    []byte
+
+	// TxHash and Height locate the tx which emitted the log.
+	TxHash common.Hash
+	Height uint64
+}
+
+// Receipt is the outcome of an applied tx. A contract call which fails is still
+// applied, its sender pays the fee, so its receipt has the failed status.
+type Receipt struct {
+	TxHash common.Hash
+	Height uint64
+	Status uint8
+	// Fee is paid by the sender, including the burned base fee.
+	Fee     *big.Int
+	GasUsed uint64
+	Return  []byte
+	Logs    []*Log
+	// Error is the reason of a failed status.
+	Error string
+}
+
+// addLog appends a log of address to the receipt.
+func (r *Receipt) addLog(address common.Address, topics [][]byte, data []byte) {
+	r.Logs = append(r.Logs, &Log{
+		Address: address,
+		Topics:  topics,
+		Data:    data,
+		TxHash:  r.TxHash,
+		Height:  r.Height,
+	})
+}
+
+// CreateBloom returns the bloom of addresses and topics of the logs in receipts.
+func CreateBloom(receipts []*Receipt) block.Bloom {
+	var bloom block.Bloom
+	for _, receipt := range receipts {
+		for _, log := range receipt.Logs {
+			bloom.Add(log.Address.CloneBytes())
+			for _, topic := range log.Topics {
+				bloom.Add(topic)
+			}
+		}
+	}
+	return bloom
+}
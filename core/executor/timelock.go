@@ -0,0 +1,247 @@
+package executor
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/state"
+	"github.com/ldmtam/tam-chain/core/transaction"
+	"github.com/ldmtam/tam-chain/proto"
+)
+
+var (
+	timeLockPrefix         = []byte("timelock/")
+	accountTimeLocksPrefix = []byte("timelock-account/")
+	pendingTimeLocksKey    = []byte("timelock-pending")
+)
+
+var (
+	errTimeLockUnlocked = errors.New("unlock bound of time lock is already reached")
+	errTimeLockNotFound = errors.New("time lock does not exist")
+	errNotTimeLockOwner = errors.New("time lock is not created by the sender")
+)
+
+func timeLockKey(id common.Hash) []byte {
+	return append(append([]byte{}, timeLockPrefix...), id.CloneBytes()...)
+}
+
+func accountTimeLocksKey(address common.Address) []byte {
+	return append(append([]byte{}, accountTimeLocksPrefix...), address.CloneBytes()...)
+}
+
+// GetTimeLock returns the pending time lock of id, nil if there is none.
+func GetTimeLock(s *state.StateDB, id common.Hash) (*corepb.TimeLock, error) {
+	data := s.Get(timeLockKey(id))
+	if data == nil {
+		return nil, nil
+	}
+	lock := &corepb.TimeLock{}
+	if err := proto.Unmarshal(data, lock); err != nil {
+		return nil, errInvalidStateRecord
+	}
+	return lock, nil
+}
+
+// GetTimeLocks returns the pending time locks which are created or received by address.
+func GetTimeLocks(s *state.StateDB, address common.Address) ([]*corepb.TimeLock, error) {
+	ids, err := getTimeLockIndex(s, accountTimeLocksKey(address))
+	if err != nil {
+		return nil, err
+	}
+	locks := make([]*corepb.TimeLock, 0, len(ids))
+	for _, id := range ids {
+		lock, err := GetTimeLock(s, id)
+		if err != nil {
+			return nil, err
+		}
+		if lock != nil {
+			locks = append(locks, lock)
+		}
+	}
+	return locks, nil
+}
+
+// executeTimeLockTransfer escrows tx value from the sender, the time lock is
+// identified by the tx hash.
+func executeTimeLockTransfer(ctx *Context, tx *transaction.TxImpl, receipt *Receipt) error {
+	from, to := txAddresses(tx)
+	unlockAt, err := transaction.DecodeTimeLockPayload(tx.Payload())
+	if err != nil {
+		return err
+	}
+	if transaction.BoundReached(unlockAt, ctx.Height, ctx.Timestamp) {
+		return errTimeLockUnlocked
+	}
+	if err := checkRecipient(ctx.State, to); err != nil {
+		return err
+	}
+
+	sender, err := ctx.State.GetAccount(from)
+	if err != nil {
+		return err
+	}
+	if err := sender.SubFromBalance(tx.Value()); err != nil {
+		return err
+	}
+	if err := ctx.State.PutAccount(sender); err != nil {
+		return err
+	}
+
+	id := tx.Hash()
+	lock := &corepb.TimeLock{
+		Id:        id.CloneBytes(),
+		Owner:     from.CloneBytes(),
+		Recipient: to.CloneBytes(),
+		Amount:    tx.Value().Bytes(),
+		UnlockAt:  unlockAt,
+		Height:    ctx.Height,
+	}
+	data, err := proto.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	ctx.State.Put(timeLockKey(id), data)
+
+	if err := addToTimeLockIndex(ctx.State, pendingTimeLocksKey, id); err != nil {
+		return err
+	}
+	if err := addToTimeLockIndex(ctx.State, accountTimeLocksKey(from), id); err != nil {
+		return err
+	}
+	if from.Equals(to) {
+		return nil
+	}
+	return addToTimeLockIndex(ctx.State, accountTimeLocksKey(to), id)
+}
+
+// executeCancelTimeLock returns the escrowed value to the owner of a time lock
+// which is not released yet.
+func executeCancelTimeLock(ctx *Context, tx *transaction.TxImpl, receipt *Receipt) error {
+	from, _ := txAddresses(tx)
+	id, err := transaction.DecodeCancelTimeLockPayload(tx.Payload())
+	if err != nil {
+		return err
+	}
+
+	lock, err := GetTimeLock(ctx.State, id)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		return errTimeLockNotFound
+	}
+	if common.Equal(lock.Owner, from.CloneBytes()) == false {
+		return errNotTimeLockOwner
+	}
+	if transaction.BoundReached(lock.UnlockAt, ctx.Height, ctx.Timestamp) {
+		return errTimeLockUnlocked
+	}
+
+	return closeTimeLock(ctx.State, lock, from)
+}
+
+// releaseTimeLocks pays the time locks whose unlock bound is reached to their recipients.
+func releaseTimeLocks(ctx *Context) error {
+	ids, err := getTimeLockIndex(ctx.State, pendingTimeLocksKey)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		lock, err := GetTimeLock(ctx.State, id)
+		if err != nil {
+			return err
+		}
+		if lock == nil || transaction.BoundReached(lock.UnlockAt, ctx.Height, ctx.Timestamp) == false {
+			continue
+		}
+
+		var recipient common.Address
+		recipient.SetBytes(lock.Recipient)
+		if err := closeTimeLock(ctx.State, lock, recipient); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// closeTimeLock pays the escrowed value to payee and removes the time lock.
+func closeTimeLock(s *state.StateDB, lock *corepb.TimeLock, payee common.Address) error {
+	acc, err := s.GetAccount(payee)
+	if err != nil {
+		return err
+	}
+	acc.AddToBalance(new(big.Int).SetBytes(lock.Amount))
+	if err := s.PutAccount(acc); err != nil {
+		return err
+	}
+
+	var id common.Hash
+	id.SetBytes(lock.Id)
+	var owner, recipient common.Address
+	owner.SetBytes(lock.Owner)
+	recipient.SetBytes(lock.Recipient)
+
+	s.Delete(timeLockKey(id))
+	for _, key := range [][]byte{pendingTimeLocksKey, accountTimeLocksKey(owner), accountTimeLocksKey(recipient)} {
+		if err := removeFromTimeLockIndex(s, key, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getTimeLockIndex(s *state.StateDB, key []byte) ([]common.Hash, error) {
+	data := s.Get(key)
+	if data == nil {
+		return nil, nil
+	}
+	index := &corepb.TimeLockIndex{}
+	if err := proto.Unmarshal(data, index); err != nil {
+		return nil, errInvalidStateRecord
+	}
+	ids := make([]common.Hash, len(index.Ids))
+	for i, id := range index.Ids {
+		ids[i].SetBytes(id)
+	}
+	return ids, nil
+}
+
+func putTimeLockIndex(s *state.StateDB, key []byte, ids []common.Hash) error {
+	if len(ids) == 0 {
+		s.Delete(key)
+		return nil
+	}
+	index := &corepb.TimeLockIndex{Ids: make([][]byte, len(ids))}
+	for i, id := range ids {
+		index.Ids[i] = id.CloneBytes()
+	}
+	data, err := proto.Marshal(index)
+	if err != nil {
+		return err
+	}
+	s.Put(key, data)
+	return nil
+}
+
+func addToTimeLockIndex(s *state.StateDB, key []byte, id common.Hash) error {
+	ids, err := getTimeLockIndex(s, key)
+	if err != nil {
+		return err
+	}
+	return putTimeLockIndex(s, key, append(ids, id))
+}
+
+func removeFromTimeLockIndex(s *state.StateDB, key []byte, id common.Hash) error {
+	ids, err := getTimeLockIndex(s, key)
+	if err != nil {
+		return err
+	}
+	for i := range ids {
+		if ids[i].Equals(&id) {
+			return putTimeLockIndex(s, key, append(ids[:i], ids[i+1:]...))
+		}
+	}
+	return nil
+}
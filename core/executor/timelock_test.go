@@ -0,0 +1,97 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeLockRelease(t *testing.T) {
+	env := newTestEnv(t)
+	recipient := env.newRecipient(t)
+
+	payload, _ := transaction.EncodeTimeLockPayload(env.ctx.Height + 5)
+	assert.Nil(t, env.apply(t, transaction.TxTypeTimeLockTransfer, payload, recipient.Address(), 100))
+	assert.Equal(t, int64(1000-100-1), env.balance(env.sender.Address()))
+	assert.Equal(t, int64(0), env.balance(recipient.Address()))
+
+	// the time lock is listed for both the owner and the recipient.
+	locks, err := GetTimeLocks(env.ctx.State, env.sender.Address())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(locks))
+	assert.Equal(t, recipient.Address().CloneBytes(), locks[0].Recipient)
+	assert.Equal(t, env.ctx.Height+5, locks[0].UnlockAt)
+	locks, _ = GetTimeLocks(env.ctx.State, recipient.Address())
+	assert.Equal(t, 1, len(locks))
+
+	env.ctx.Height += 4
+	assert.Nil(t, env.executor.BeginBlock(env.ctx))
+	assert.Equal(t, int64(0), env.balance(recipient.Address()))
+
+	env.ctx.Height++
+	assert.Nil(t, env.executor.BeginBlock(env.ctx))
+	assert.Equal(t, int64(100), env.balance(recipient.Address()))
+
+	locks, _ = GetTimeLocks(env.ctx.State, env.sender.Address())
+	assert.Empty(t, locks)
+	locks, _ = GetTimeLocks(env.ctx.State, recipient.Address())
+	assert.Empty(t, locks)
+
+	// a time lock cannot be created already unlocked.
+	payload, _ = transaction.EncodeTimeLockPayload(env.ctx.Height)
+	assert.Equal(t, errTimeLockUnlocked, env.apply(t, transaction.TxTypeTimeLockTransfer, payload, recipient.Address(), 100))
+}
+
+func TestTimeLockByTimestamp(t *testing.T) {
+	env := newTestEnv(t)
+	recipient := env.newRecipient(t)
+
+	payload, _ := transaction.EncodeTimeLockPayload(uint64(env.ctx.Timestamp + 60))
+	assert.Nil(t, env.apply(t, transaction.TxTypeTimeLockTransfer, payload, recipient.Address(), 100))
+
+	env.ctx.Height += 1000
+	assert.Nil(t, env.executor.BeginBlock(env.ctx))
+	assert.Equal(t, int64(0), env.balance(recipient.Address()))
+
+	env.ctx.Timestamp += 60
+	assert.Nil(t, env.executor.BeginBlock(env.ctx))
+	assert.Equal(t, int64(100), env.balance(recipient.Address()))
+}
+
+func TestCancelTimeLock(t *testing.T) {
+	env := newTestEnv(t)
+	recipient := env.newRecipient(t)
+	self := env.sender.Address()
+
+	payload, _ := transaction.EncodeTimeLockPayload(env.ctx.Height + 5)
+	assert.Nil(t, env.apply(t, transaction.TxTypeTimeLockTransfer, payload, recipient.Address(), 100))
+	locks, _ := GetTimeLocks(env.ctx.State, self)
+	var id common.Hash
+	id.SetBytes(locks[0].Id)
+
+	// only the owner can cancel.
+	other := newTestEnv(t)
+	other.ctx.State = env.ctx.State
+	other.fund(other.sender.Address(), 1000)
+	cancel, _ := transaction.EncodeCancelTimeLockPayload(id)
+	assert.Equal(t, errNotTimeLockOwner, other.apply(t, transaction.TxTypeCancelTimeLock, cancel, other.sender.Address(), 0))
+
+	assert.Nil(t, env.apply(t, transaction.TxTypeCancelTimeLock, cancel, self, 0))
+	assert.Equal(t, int64(1000-2), env.balance(self))
+	locks, _ = GetTimeLocks(env.ctx.State, recipient.Address())
+	assert.Empty(t, locks)
+	assert.Equal(t, errTimeLockNotFound, env.apply(t, transaction.TxTypeCancelTimeLock, cancel, self, 0))
+
+	// a released time lock cannot be cancelled.
+	assert.Nil(t, env.apply(t, transaction.TxTypeTimeLockTransfer, payload, recipient.Address(), 100))
+	locks, _ = GetTimeLocks(env.ctx.State, self)
+	id.SetBytes(locks[0].Id)
+	cancel, _ = transaction.EncodeCancelTimeLockPayload(id)
+
+	env.ctx.Height += 5
+	assert.Nil(t, env.executor.BeginBlock(env.ctx))
+	assert.Equal(t, errTimeLockNotFound, env.apply(t, transaction.TxTypeCancelTimeLock, cancel, self, 0))
+	assert.Equal(t, int64(100), env.balance(recipient.Address()))
+}
@@ -19,9 +19,17 @@ var (
 )
 
 type account struct {
-	address common.Hash
+	address common.Address
 	balance *big.Int
 	nonce   uint64
+	keys    [][]byte
+}
+
+func newAccount(address common.Address) *account {
+	return &account{
+		address: address,
+		balance: new(big.Int),
+	}
 }
 
 // Marshal encode account struct with protobuf
@@ -33,6 +41,7 @@ func (acc *account) Marshal() ([]byte, error) {
 		Address: accAddress,
 		Balance: accBalance,
 		Nonce:   acc.nonce,
+		Keys:    acc.keys,
 	}
 
 	serializedData, err := proto.Marshal(pbAcc)
@@ -53,6 +62,7 @@ func (acc *account) Unmarshal(data []byte) error {
 	acc.balance = new(big.Int)
 	acc.balance.SetBytes(pbAcc.Balance)
 	acc.nonce = pbAcc.Nonce
+	acc.keys = pbAcc.Keys
 	return nil
 }
 
@@ -62,7 +72,7 @@ func (acc *account) Balance() *big.Int {
 }
 
 // Address get account's address
-func (acc *account) Address() common.Hash {
+func (acc *account) Address() common.Address {
 	return acc.address
 }
 
@@ -71,6 +81,16 @@ func (acc *account) Nonce() uint64 {
 	return acc.nonce
 }
 
+// Keys get account's authorized public keys
+func (acc *account) Keys() [][]byte {
+	return acc.keys
+}
+
+// SetKeys replaces account's authorized public keys
+func (acc *account) SetKeys(keys [][]byte) {
+	acc.keys = keys
+}
+
 // IncreaseNonce increase nonce by 1
 func (acc *account) IncreaseNonce() {
 	acc.nonce++
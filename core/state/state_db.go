@@ -0,0 +1,229 @@
+package state
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/ldmtam/tam-chain/abstraction"
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/crypto/sha3"
+)
+
+var (
+	accountPrefix = []byte("account/")
+)
+
+// rootBuckets is the number of buckets the state root is computed from.
+const rootBuckets = 4096
+
+// journalEntry records the value of a key before it is modified.
+type journalEntry struct {
+	key     string
+	prev    []byte
+	existed bool
+}
+
+// StateDB is an in-memory key-value store of the world state.
+//
+// Accounts and the records of tx types (validators, anchors, ...) are stored
+// as protobuf encoded values. Every modification is journaled, so changes
+// made by a failed tx can be reverted to a snapshot.
+//
+// Keys are spread over buckets by their hash and the hash of each bucket is
+// cached, so Root only rehashes the buckets modified since the last call.
+type StateDB struct {
+	data    map[string][]byte
+	journal []journalEntry
+
+	buckets      [rootBuckets]map[string]struct{}
+	bucketHashes [rootBuckets]common.Hash
+	dirty        map[int]struct{}
+
+	mu sync.RWMutex
+}
+
+// NewStateDB returns an empty StateDB.
+func NewStateDB() *StateDB {
+	s := &StateDB{
+		data:  make(map[string][]byte),
+		dirty: make(map[int]struct{}),
+	}
+	for i := range s.buckets {
+		s.buckets[i] = make(map[string]struct{})
+	}
+	return s
+}
+
+// Get returns value of key, nil if key does not exist.
+func (s *StateDB) Get(key []byte) []byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.data[string(key)]
+}
+
+// Has checks whether key exists or not.
+func (s *StateDB) Has(key []byte) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.data[string(key)]
+	return ok
+}
+
+// Put sets value of key.
+func (s *StateDB) Put(key, value []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.record(string(key))
+	s.set(string(key), value)
+}
+
+// Delete removes key.
+func (s *StateDB) Delete(key []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.record(string(key))
+	s.remove(string(key))
+}
+
+// record journals current value of key, s.mu must be held.
+func (s *StateDB) record(key string) {
+	prev, existed := s.data[key]
+	s.journal = append(s.journal, journalEntry{key: key, prev: prev, existed: existed})
+}
+
+// bucketOf returns the bucket of key.
+func bucketOf(key string) int {
+	h := sha3.Sum256([]byte(key))
+	return (int(h[0])<<8 | int(h[1])) % rootBuckets
+}
+
+// set sets value of key and marks its bucket dirty, s.mu must be held.
+func (s *StateDB) set(key string, value []byte) {
+	bucket := bucketOf(key)
+	s.data[key] = value
+	s.buckets[bucket][key] = struct{}{}
+	s.dirty[bucket] = struct{}{}
+}
+
+// remove removes key and marks its bucket dirty, s.mu must be held.
+func (s *StateDB) remove(key string) {
+	bucket := bucketOf(key)
+	delete(s.data, key)
+	delete(s.buckets[bucket], key)
+	s.dirty[bucket] = struct{}{}
+}
+
+// Snapshot returns an identifier of current state.
+func (s *StateDB) Snapshot() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.journal)
+}
+
+// RevertToSnapshot undoes all changes made after the snapshot is taken.
+func (s *StateDB) RevertToSnapshot(snapshot int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.journal) - 1; i >= snapshot; i-- {
+		entry := s.journal[i]
+		if entry.existed {
+			s.set(entry.key, entry.prev)
+		} else {
+			s.remove(entry.key)
+		}
+	}
+	s.journal = s.journal[:snapshot]
+}
+
+// Commit discards the journal, changes cannot be reverted after that.
+func (s *StateDB) Commit() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.journal = nil
+}
+
+// Root returns sha3-256 of the bucket hashes, a bucket hash is sha3-256 of
+// the key-value pairs of the bucket sorted by key.
+func (s *StateDB) Root() common.Hash {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for bucket := range s.dirty {
+		s.bucketHashes[bucket] = s.hashBucket(bucket)
+	}
+	s.dirty = make(map[int]struct{})
+
+	hasher := sha3.New256()
+	for i := range s.bucketHashes {
+		hasher.Write(s.bucketHashes[i].CloneBytes())
+	}
+
+	var root common.Hash
+	root.SetBytes(hasher.Sum(nil))
+	return root
+}
+
+// hashBucket returns the hash of bucket, an empty bucket has zero hash.
+func (s *StateDB) hashBucket(bucket int) common.Hash {
+	var hash common.Hash
+	if len(s.buckets[bucket]) == 0 {
+		return hash
+	}
+
+	keys := make([]string, 0, len(s.buckets[bucket]))
+	for key := range s.buckets[bucket] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hasher := sha3.New256()
+	for _, key := range keys {
+		hasher.Write(common.FromUint32(uint32(len(key))))
+		hasher.Write([]byte(key))
+		hasher.Write(common.FromUint32(uint32(len(s.data[key]))))
+		hasher.Write(s.data[key])
+	}
+	hash.SetBytes(hasher.Sum(nil))
+	return hash
+}
+
+func accountKey(address common.Address) []byte {
+	return append(append([]byte{}, accountPrefix...), address.CloneBytes()...)
+}
+
+// HasAccount checks whether account exists or not.
+func (s *StateDB) HasAccount(address common.Address) bool {
+	return s.Has(accountKey(address))
+}
+
+// GetAccount returns a copy of account, an empty account is returned if it
+// does not exist. Changes to the copy are saved by PutAccount.
+func (s *StateDB) GetAccount(address common.Address) (abstraction.Account, error) {
+	acc := newAccount(address)
+
+	data := s.Get(accountKey(address))
+	if data == nil {
+		return acc, nil
+	}
+	if err := acc.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	return acc, nil
+}
+
+// PutAccount saves account.
+func (s *StateDB) PutAccount(acc abstraction.Account) error {
+	data, err := acc.Marshal()
+	if err != nil {
+		return err
+	}
+	s.Put(accountKey(acc.Address()), data)
+	return nil
+}
@@ -0,0 +1,82 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccount(t *testing.T) {
+	s := NewStateDB()
+	addr := common.NewAddress(common.AddressVersionEd25519, []byte("alice"))
+
+	acc, err := s.GetAccount(addr)
+	assert.Nil(t, err)
+	assert.False(t, s.HasAccount(addr))
+	assert.Equal(t, int64(0), acc.Balance().Int64())
+
+	acc.AddToBalance(big.NewInt(100))
+	acc.IncreaseNonce()
+	acc.SetKeys([][]byte{[]byte("key")})
+	assert.Nil(t, s.PutAccount(acc))
+	assert.True(t, s.HasAccount(addr))
+
+	acc, err = s.GetAccount(addr)
+	assert.Nil(t, err)
+	assert.Equal(t, addr, acc.Address())
+	assert.Equal(t, int64(100), acc.Balance().Int64())
+	assert.Equal(t, uint64(1), acc.Nonce())
+	assert.Equal(t, [][]byte{[]byte("key")}, acc.Keys())
+	assert.Equal(t, ErrBalanceInsufficient, acc.SubFromBalance(big.NewInt(101)))
+}
+
+func TestSnapshot(t *testing.T) {
+	s := NewStateDB()
+	s.Put([]byte("a"), []byte("1"))
+	root := s.Root()
+
+	snapshot := s.Snapshot()
+	s.Put([]byte("a"), []byte("2"))
+	s.Put([]byte("b"), []byte("3"))
+	s.Delete([]byte("a"))
+	assert.False(t, s.Has([]byte("a")))
+	assert.NotEqual(t, root, s.Root())
+
+	s.RevertToSnapshot(snapshot)
+	assert.Equal(t, []byte("1"), s.Get([]byte("a")))
+	assert.False(t, s.Has([]byte("b")))
+	assert.Equal(t, root, s.Root())
+
+	s.Put([]byte("b"), []byte("3"))
+	s.Commit()
+	s.RevertToSnapshot(s.Snapshot())
+	assert.Equal(t, []byte("3"), s.Get([]byte("b")))
+}
+
+func TestRootIncremental(t *testing.T) {
+	s := NewStateDB()
+	for i := 0; i < 1000; i++ {
+		s.Put(common.FromUint64(uint64(i)), []byte{byte(i)})
+	}
+	s.Root()
+	s.Put(common.FromUint64(7), []byte("changed"))
+	s.Delete(common.FromUint64(8))
+	s.Put([]byte("new"), []byte("1"))
+
+	// the cached root matches the root of the same state built at once, in another order.
+	other := NewStateDB()
+	other.Put([]byte("new"), []byte("1"))
+	for i := 999; i >= 0; i-- {
+		if i != 8 {
+			other.Put(common.FromUint64(uint64(i)), []byte{byte(i)})
+		}
+	}
+	other.Put(common.FromUint64(7), []byte("changed"))
+	assert.Equal(t, other.Root(), s.Root())
+	assert.Equal(t, s.Root(), s.Root())
+
+	s.Put(common.FromUint64(9), []byte("changed"))
+	assert.NotEqual(t, other.Root(), s.Root())
+}
@@ -0,0 +1,124 @@
+package transaction
+
+import (
+	"errors"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/vm"
+	"github.com/ldmtam/tam-chain/proto"
+)
+
+const (
+	// MaxGasLimit is the maximum gas a contract tx can use.
+	MaxGasLimit = 500000
+	// MaxCallArgs is the maximum number of arguments of a contract call.
+	MaxCallArgs = 16
+)
+
+var (
+	errInvalidGasLimit = errors.New("gas limit is zero or above the maximum")
+	errIntrinsicGas    = errors.New("gas limit does not cover the deployment of the code")
+	errContractAddress = errors.New("`to` address must be derived from the sender and the nonce")
+	errInvalidCallArgs = errors.New("invalid contract call arguments")
+)
+
+// GasLimit returns the maximum gas the tx can use, it's 0 for txs which do not run contracts.
+func (tx *TxImpl) GasLimit() uint64 {
+	return tx.gasLimit
+}
+
+// payloadGasLimit returns the gas limit in the payload of contract txs, 0 if
+// the tx is not a contract tx or its payload is invalid.
+func payloadGasLimit(txType TxType, payload []byte) uint64 {
+	switch txType {
+	case TxTypeContractDeploy:
+		pbPayload := &corepb.ContractDeployPayload{}
+		if err := proto.Unmarshal(payload, pbPayload); err != nil {
+			return 0
+		}
+		return pbPayload.GasLimit
+	case TxTypeContractCall:
+		pbPayload := &corepb.ContractCallPayload{}
+		if err := proto.Unmarshal(payload, pbPayload); err != nil {
+			return 0
+		}
+		return pbPayload.GasLimit
+	default:
+		return 0
+	}
+}
+
+func validateContractDeploy(tx *TxImpl) error {
+	code, gasLimit, err := DecodeContractDeployPayload(tx.payload)
+	if err != nil {
+		return err
+	}
+	if uint64(len(code))*vm.GasPerCodeByte > gasLimit {
+		return errIntrinsicGas
+	}
+	if common.NewContractAddress(tx.from, tx.nonce).Equals(tx.to) == false {
+		return errContractAddress
+	}
+	return nil
+}
+
+func validateContractCall(tx *TxImpl) error {
+	_, _, err := DecodeContractCallPayload(tx.payload)
+	return err
+}
+
+func validateGasLimit(gasLimit uint64) error {
+	if gasLimit == 0 || gasLimit > MaxGasLimit {
+		return errInvalidGasLimit
+	}
+	return nil
+}
+
+// EncodeContractDeployPayload encodes the code and gas limit of a contract deployment tx.
+func EncodeContractDeployPayload(code []byte, gasLimit uint64) ([]byte, error) {
+	return proto.Marshal(&corepb.ContractDeployPayload{Code: code, GasLimit: gasLimit})
+}
+
+// DecodeContractDeployPayload decodes and validates the payload of a contract deployment tx.
+func DecodeContractDeployPayload(payload []byte) ([]byte, uint64, error) {
+	pbPayload := &corepb.ContractDeployPayload{}
+	if err := proto.Unmarshal(payload, pbPayload); err != nil {
+		return nil, 0, errInvalidTxPayload
+	}
+	if len(pbPayload.Code) == 0 {
+		return nil, 0, errInvalidTxPayload
+	}
+	if err := vm.Validate(pbPayload.Code); err != nil {
+		return nil, 0, err
+	}
+	if err := validateGasLimit(pbPayload.GasLimit); err != nil {
+		return nil, 0, err
+	}
+	return pbPayload.Code, pbPayload.GasLimit, nil
+}
+
+// EncodeContractCallPayload encodes the arguments and gas limit of a contract call tx.
+func EncodeContractCallPayload(args [][]byte, gasLimit uint64) ([]byte, error) {
+	return proto.Marshal(&corepb.ContractCallPayload{Args: args, GasLimit: gasLimit})
+}
+
+// DecodeContractCallPayload decodes and validates the payload of a contract call tx.
+func DecodeContractCallPayload(payload []byte) ([][]byte, uint64, error) {
+	pbPayload := &corepb.ContractCallPayload{}
+	if err := proto.Unmarshal(payload, pbPayload); err != nil {
+		return nil, 0, errInvalidTxPayload
+	}
+	if len(pbPayload.Args) > MaxCallArgs {
+		return nil, 0, errInvalidCallArgs
+	}
+	for _, arg := range pbPayload.Args {
+		if len(arg) > vm.MaxItemSize {
+			return nil, 0, errInvalidCallArgs
+		}
+	}
+	if err := validateGasLimit(pbPayload.GasLimit); err != nil {
+		return nil, 0, err
+	}
+	return pbPayload.Args, pbPayload.GasLimit, nil
+}
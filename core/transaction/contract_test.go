@@ -0,0 +1,74 @@
+package transaction
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/vm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContractDeployTx(t *testing.T) {
+	pubKey, _ := hex.DecodeString(fromPubKey)
+	self := common.NewAddress(common.AddressVersionEd25519, pubKey)
+	contract := common.NewContractAddress(self, 1)
+	code := []byte{byte(vm.STOP)}
+
+	payload, err := EncodeContractDeployPayload(code, 1000)
+	assert.Nil(t, err)
+	tx, err := newTypedTx(TxTypeContractDeploy, payload, contract, 0)
+	assert.Nil(t, err)
+	tx.Sign(decodeKeyPair(fromPrivKey, fromPubKey))
+	assert.Nil(t, tx.VerifyIntegrity(accounts))
+
+	// the gas limit survives encoding and weighs like bytes.
+	b, _ := tx.Marshal()
+	newTx := &TxImpl{}
+	assert.Nil(t, newTx.Unmarshal(b))
+	assert.Equal(t, uint64(1000), newTx.GasLimit())
+	assert.Equal(t, newTx.Size()+1000, newTx.Weight())
+
+	_, err = newTypedTx(TxTypeContractDeploy, payload, common.NewContractAddress(self, 2), 0)
+	assert.Equal(t, errContractAddress, err)
+
+	payload, _ = EncodeContractDeployPayload(code, vm.GasPerCodeByte-1)
+	_, err = newTypedTx(TxTypeContractDeploy, payload, contract, 0)
+	assert.Equal(t, errIntrinsicGas, err)
+
+	payload, _ = EncodeContractDeployPayload([]byte{0xff}, 1000)
+	_, err = newTypedTx(TxTypeContractDeploy, payload, contract, 0)
+	assert.NotNil(t, err)
+}
+
+func TestContractCallTx(t *testing.T) {
+	pubKey, _ := hex.DecodeString(fromPubKey)
+	contract := common.NewContractAddress(common.NewAddress(common.AddressVersionEd25519, pubKey), 1)
+
+	payload, err := EncodeContractCallPayload([][]byte{[]byte("arg")}, 5000)
+	assert.Nil(t, err)
+	tx, err := newTypedTx(TxTypeContractCall, payload, contract, 10)
+	assert.Nil(t, err)
+	args, gasLimit, err := DecodeContractCallPayload(tx.Payload())
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("arg")}, args)
+	assert.Equal(t, uint64(5000), gasLimit)
+	assert.Equal(t, gasLimit, tx.GasLimit())
+
+	// the fee pays for the gas limit.
+	_, burned, err := tx.EffectiveFee(big.NewInt(0))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), burned.Int64())
+	assert.True(t, tx.Weight() > gasLimit)
+
+	payload, _ = EncodeContractCallPayload(nil, MaxGasLimit+1)
+	_, err = newTypedTx(TxTypeContractCall, payload, contract, 0)
+	assert.Equal(t, errInvalidGasLimit, err)
+	payload, _ = EncodeContractCallPayload(nil, 0)
+	_, err = newTypedTx(TxTypeContractCall, payload, contract, 0)
+	assert.Equal(t, errInvalidGasLimit, err)
+	payload, _ = EncodeContractCallPayload([][]byte{make([]byte, vm.MaxItemSize+1)}, 5000)
+	_, err = newTypedTx(TxTypeContractCall, payload, contract, 0)
+	assert.Equal(t, errInvalidCallArgs, err)
+}
@@ -0,0 +1,183 @@
+package transaction
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/crypto/sha3"
+)
+
+/*
+Signing payload of transaction version 1:
+
+ +----------------+---------+---------+-----+---------+
+ |  Version (4)   | Field 1 | Field 2 | ... | Field n |
+ +----------------+---------+---------+-----+---------+
+
+Each field is encoded as:
+
+ +-----------+------------+-----------------------+
+ |  Tag (2)  | Length (4) |     Value (Length)    |
+ +-----------+------------+-----------------------+
+
+ * all integers are big endian.
+ * tag is the field number of the field in corepb.Transaction, fields are sorted by tag.
+ * integer values are fixed width: uint32 takes 4 bytes, uint64 and int64 take 8 bytes.
+ * big integer values are the minimal big endian bytes of the absolute value.
+ * fields with zero or empty value are omitted, so adding a new optional field
+   does not change the payload of transactions which do not use it.
+
+Tx hash is sha3-256 of the signing payload, the signature is made on the tx hash.
+*/
+
+// Tx versions
+const (
+	// TxVersionLegacy hashes the decimal strings of value and fee with the raw fields.
+	// Legacy txs are decoded but no longer pass verification.
+	TxVersionLegacy uint32 = 0
+	// TxVersion1 hashes the canonical signing payload.
+	TxVersion1 uint32 = 1
+
+	// CurrentTxVersion is the version of newly created txs.
+	CurrentTxVersion = TxVersion1
+)
+
+// tags of fields in the signing payload, they are field numbers of corepb.Transaction.
+const (
+	tagChainID    uint16 = 2
+	tagFrom       uint16 = 3
+	tagTo         uint16 = 4
+	tagValue      uint16 = 5
+	tagFee        uint16 = 6
+	tagNonce      uint16 = 7
+	tagTimestamp  uint16 = 8
+	tagPublicKey  uint16 = 10
+	tagMemo       uint16 = 11
+	tagType       uint16 = 13
+	tagPayload    uint16 = 14
+	tagValidAfter uint16 = 15
+	tagValidUntil uint16 = 16
+	tagTip        uint16 = 17
+)
+
+var (
+	errUnsupportedTxVersion = errors.New("unsupported transaction version")
+)
+
+// payloadEncoder writes tag-length-value fields of a signing payload.
+// Fields must be written in ascending order of tag.
+type payloadEncoder struct {
+	buf bytes.Buffer
+}
+
+func newPayloadEncoder(version uint32) *payloadEncoder {
+	e := &payloadEncoder{}
+	e.buf.Write(common.FromUint32(version))
+	return e
+}
+
+func (e *payloadEncoder) writeBytes(tag uint16, b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	e.buf.Write([]byte{byte(tag >> 8), byte(tag)})
+	e.buf.Write(common.FromUint32(uint32(len(b))))
+	e.buf.Write(b)
+}
+
+func (e *payloadEncoder) writeUint32(tag uint16, v uint32) {
+	if v == 0 {
+		return
+	}
+	e.writeBytes(tag, common.FromUint32(v))
+}
+
+func (e *payloadEncoder) writeUint64(tag uint16, v uint64) {
+	if v == 0 {
+		return
+	}
+	e.writeBytes(tag, common.FromUint64(v))
+}
+
+func (e *payloadEncoder) writeInt64(tag uint16, v int64) {
+	if v == 0 {
+		return
+	}
+	e.writeBytes(tag, common.FromInt64(v))
+}
+
+func (e *payloadEncoder) writeBigInt(tag uint16, v *big.Int) {
+	if v == nil {
+		return
+	}
+	e.writeBytes(tag, v.Bytes())
+}
+
+func (e *payloadEncoder) bytes() []byte {
+	return e.buf.Bytes()
+}
+
+// SigningPayload returns the canonical encoding of the tx which is hashed and signed.
+func (tx *TxImpl) SigningPayload() ([]byte, error) {
+	switch tx.version {
+	case TxVersion1:
+		e := newPayloadEncoder(tx.version)
+		e.writeUint32(tagChainID, tx.chainID)
+		e.writeBytes(tagFrom, tx.From())
+		e.writeBytes(tagTo, tx.To())
+		e.writeBigInt(tagValue, tx.value)
+		e.writeBigInt(tagFee, tx.fee)
+		e.writeUint64(tagNonce, tx.nonce)
+		e.writeInt64(tagTimestamp, tx.timestamp)
+		e.writeBytes(tagPublicKey, tx.pubKey)
+		e.writeBytes(tagMemo, tx.memo)
+		e.writeUint32(tagType, uint32(tx.txType))
+		e.writeBytes(tagPayload, tx.payload)
+		e.writeUint64(tagValidAfter, tx.validAfter)
+		e.writeUint64(tagValidUntil, tx.validUntil)
+		e.writeBigInt(tagTip, tx.tip)
+		return e.bytes(), nil
+	default:
+		return nil, errUnsupportedTxVersion
+	}
+}
+
+// calcHash calculate hash of the transaction.
+func (tx *TxImpl) calcHash() (common.Hash, error) {
+	var h common.Hash
+
+	if tx.version == TxVersionLegacy {
+		h.SetBytes(tx.calcLegacyHash())
+		return h, nil
+	}
+
+	payload, err := tx.SigningPayload()
+	if err != nil {
+		return h, err
+	}
+	sum := sha3.Sum256(payload)
+	h.SetBytes(sum[:])
+
+	return h, nil
+}
+
+// calcLegacyHash calculates hash of legacy txs, which are created before tx versions.
+func (tx *TxImpl) calcLegacyHash() []byte {
+	hasher := sha3.New256()
+
+	value := tx.value.String()
+	fee := tx.fee.String()
+
+	hasher.Write(common.FromUint32(tx.chainID))
+	hasher.Write(tx.From())
+	hasher.Write(tx.To())
+	hasher.Write([]byte(value))
+	hasher.Write([]byte(fee))
+	hasher.Write(common.FromUint64(tx.nonce))
+	hasher.Write(common.FromInt64(tx.timestamp))
+	hasher.Write(tx.memo)
+
+	return hasher.Sum(nil)
+}
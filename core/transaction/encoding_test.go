@@ -0,0 +1,121 @@
+package transaction
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// txVector is a golden test vector of the signing payload, the same file is
+// used to test wallets written in other languages.
+type txVector struct {
+	Name       string `json:"name"`
+	Version    uint32 `json:"version"`
+	Type       string `json:"type"`
+	ChainID    uint32 `json:"chain_id"`
+	PublicKey  string `json:"public_key"`
+	To         string `json:"to"`
+	Value      string `json:"value"`
+	Fee        string `json:"fee"`
+	Tip        string `json:"tip"`
+	Nonce      uint64 `json:"nonce"`
+	Timestamp  int64  `json:"timestamp"`
+	Memo       string `json:"memo"`
+	TxPayload  string `json:"tx_payload"`
+	ValidAfter uint64 `json:"valid_after"`
+	ValidUntil uint64 `json:"valid_until"`
+	Payload    string `json:"payload"`
+	Hash       string `json:"hash"`
+}
+
+func TestSigningPayloadVectors(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/tx_vectors.json")
+	assert.Nil(t, err)
+
+	var vectors []txVector
+	assert.Nil(t, json.Unmarshal(data, &vectors))
+	assert.NotEmpty(t, vectors)
+
+	for _, v := range vectors {
+		pubKey, _ := hex.DecodeString(v.PublicKey)
+		toBytes, _ := hex.DecodeString(v.To)
+		memo, _ := hex.DecodeString(v.Memo)
+		txPayload, _ := hex.DecodeString(v.TxPayload)
+		value, _ := new(big.Int).SetString(v.Value, 10)
+		fee, _ := new(big.Int).SetString(v.Fee, 10)
+		tip, _ := new(big.Int).SetString(v.Tip, 10)
+
+		var to common.Address
+		to.SetBytes(toBytes)
+
+		txType, err := ParseTxType(v.Type)
+		assert.Nil(t, err, v.Name)
+
+		tx, err := NewTypedTransaction(txType, txPayload, v.ChainID, common.NewAddress(common.AddressVersionEd25519, pubKey), pubKey, to, value, fee, v.Nonce, v.Timestamp, memo)
+		assert.Nil(t, err, v.Name)
+		assert.Nil(t, tx.SetValidityWindow(v.ValidAfter, v.ValidUntil), v.Name)
+		assert.Nil(t, tx.SetTip(tip), v.Name)
+		assert.Equal(t, v.Version, tx.Version(), v.Name)
+
+		payload, err := tx.SigningPayload()
+		assert.Nil(t, err, v.Name)
+		assert.Equal(t, v.Payload, hex.EncodeToString(payload), v.Name)
+
+		hash := tx.Hash()
+		assert.Equal(t, v.Hash, hash.String(), v.Name)
+	}
+}
+
+func TestLegacyTx(t *testing.T) {
+	tx := createTx()
+	tx.version = TxVersionLegacy
+	tx.hash, _ = tx.calcHash()
+	tx.Sign(decodeKeyPair(fromPrivKey, fromPubKey))
+
+	// legacy txs still decode but do not verify.
+	b, err := tx.Marshal()
+	assert.Nil(t, err)
+	newTx := &TxImpl{}
+	assert.Nil(t, newTx.Unmarshal(b))
+	assert.Equal(t, TxVersionLegacy, newTx.Version())
+	assert.Equal(t, errUnsupportedTxVersion, newTx.VerifyIntegrity(accounts))
+	assert.Equal(t, errUnsupportedTxVersion, newTx.VerifySignature())
+
+	// hash of a tx depends on its version.
+	newTx.version = CurrentTxVersion
+	assert.Equal(t, errInvalidTransacionHash, newTx.VerifyIntegrity(accounts))
+
+	newTx.version = 100
+	assert.Equal(t, errUnsupportedTxVersion, newTx.VerifyIntegrity(accounts))
+}
+
+func TestLegacyTxAmountsRewritten(t *testing.T) {
+	tx := createTx()
+	tx.version = TxVersionLegacy
+	tx.value, tx.fee = big.NewInt(12), big.NewInt(345)
+	tx.hash, _ = tx.calcHash()
+	tx.Sign(decodeKeyPair(fromPrivKey, fromPubKey))
+
+	// value 123 and fee 45 hash like value 12 and fee 345, so the signature
+	// stays valid when a relayer moves digits between them.
+	tx.value, tx.fee = big.NewInt(123), big.NewInt(45)
+	hash, err := tx.calcHash()
+	assert.Nil(t, err)
+	assert.True(t, hash.Equals(&tx.hash))
+	assert.True(t, tx.Verify(tx.pubKey))
+	assert.Equal(t, errUnsupportedTxVersion, tx.VerifySignature())
+
+	// the current version hashes the amounts apart.
+	tx.version = CurrentTxVersion
+	hash, err = tx.calcHash()
+	assert.Nil(t, err)
+	tx.value, tx.fee = big.NewInt(12), big.NewInt(345)
+	origHash, err := tx.calcHash()
+	assert.Nil(t, err)
+	assert.False(t, hash.Equals(&origHash))
+}
@@ -0,0 +1,83 @@
+package transaction
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/gogo/protobuf/proto"
+	"golang.org/x/crypto/ed25519"
+)
+
+var (
+	errTipAboveFee = errors.New("tip is greater than max fee")
+	errFeeTooLow   = errors.New("max fee does not cover base fee")
+)
+
+// Tip returns the maximum priority tip paid to the block producer.
+func (tx *TxImpl) Tip() *big.Int {
+	return tx.tip
+}
+
+// SetTip sets the maximum priority tip, it cannot exceed the max fee. The tx
+// hash is recalculated, so it must be called before signing.
+func (tx *TxImpl) SetTip(tip *big.Int) error {
+	if tip == nil || tip.Sign() < 0 {
+		return errTxInvalidArgument
+	}
+	if tip.Cmp(tx.fee) > 0 {
+		return errTipAboveFee
+	}
+	tx.tip = tip
+
+	hash, err := tx.calcHash()
+	if err != nil {
+		return err
+	}
+	tx.hash = hash
+	tx.signature = nil
+	return nil
+}
+
+// Size returns the size of the encoded tx. An unsigned tx is measured with a
+// signature, so its size and weight do not change when it's signed.
+func (tx *TxImpl) Size() uint64 {
+	pbTx := tx.toProto()
+	if len(pbTx.Signature) == 0 {
+		pbTx.Signature = make([]byte, ed25519.SignatureSize)
+	}
+	return uint64(proto.Size(pbTx))
+}
+
+// Weight returns the size of the encoded tx plus its gas limit, block
+// fullness and fees are measured in it. A unit of gas weighs as much as a byte.
+func (tx *TxImpl) Weight() uint64 {
+	return tx.Size() + tx.gasLimit
+}
+
+// EffectiveFee returns the fee paid by the sender in a block whose base fee
+// per weight unit is baseFee, and the burned part of it. The sender pays the
+// base fee plus the tip, but never more than the max fee.
+func (tx *TxImpl) EffectiveFee(baseFee *big.Int) (fee, burned *big.Int, err error) {
+	burned = new(big.Int).Mul(baseFee, new(big.Int).SetUint64(tx.Weight()))
+	if tx.fee.Cmp(burned) < 0 {
+		return nil, nil, errFeeTooLow
+	}
+
+	fee = new(big.Int).Add(burned, tx.tip)
+	if fee.Cmp(tx.fee) > 0 {
+		fee.Set(tx.fee)
+	}
+	return fee, burned, nil
+}
+
+// EffectiveTip returns the tip paid to the block producer in a block whose
+// base fee per weight unit is baseFee, it's negative if max fee does not
+// cover the base fee.
+func (tx *TxImpl) EffectiveTip(baseFee *big.Int) *big.Int {
+	burned := new(big.Int).Mul(baseFee, new(big.Int).SetUint64(tx.Weight()))
+	tip := new(big.Int).Sub(tx.fee, burned)
+	if tip.Cmp(tx.tip) > 0 {
+		tip.Set(tx.tip)
+	}
+	return tip
+}
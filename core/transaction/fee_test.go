@@ -0,0 +1,44 @@
+package transaction
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEffectiveFee(t *testing.T) {
+	tx := createTx()
+	tx.fee = big.NewInt(1000)
+	assert.Nil(t, tx.SetTip(big.NewInt(500)))
+	tx.Sign(decodeKeyPair(fromPrivKey, fromPubKey))
+	assert.Nil(t, tx.VerifyIntegrity(accounts))
+
+	weight := tx.Weight()
+	b, _ := tx.Marshal()
+	assert.Equal(t, uint64(len(b)), weight)
+
+	// base fee plus the whole tip.
+	fee, burned, err := tx.EffectiveFee(big.NewInt(2))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2*weight), burned.Int64())
+	assert.Equal(t, int64(2*weight+500), fee.Int64())
+	assert.Equal(t, int64(500), tx.EffectiveTip(big.NewInt(2)).Int64())
+
+	// the tip is cut by max fee.
+	baseFee := big.NewInt(int64(1000 / weight))
+	fee, burned, err = tx.EffectiveFee(baseFee)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1000), fee.Int64())
+	assert.Equal(t, 1000-burned.Int64(), tx.EffectiveTip(baseFee).Int64())
+
+	// max fee does not cover base fee.
+	baseFee = big.NewInt(int64(1000/weight + 1))
+	_, _, err = tx.EffectiveFee(baseFee)
+	assert.Equal(t, errFeeTooLow, err)
+	assert.True(t, tx.EffectiveTip(baseFee).Sign() < 0)
+
+	assert.Equal(t, errTipAboveFee, tx.SetTip(big.NewInt(1001)))
+	tx.tip = big.NewInt(1001)
+	assert.Equal(t, errTipAboveFee, tx.VerifyIntegrity(accounts))
+}
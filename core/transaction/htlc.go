@@ -0,0 +1,184 @@
+package transaction
+
+import (
+	"crypto/sha256"
+	"errors"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/crypto/sha3"
+	"github.com/ldmtam/tam-chain/proto"
+)
+
+// HashAlgorithm is the hash function of a hashlock.
+type HashAlgorithm int32
+
+// Hash algorithms
+const (
+	HashSHA3256 = HashAlgorithm(corepb.HashAlgorithm_SHA3_256)
+	HashSHA256  = HashAlgorithm(corepb.HashAlgorithm_SHA256)
+)
+
+const (
+	// MaxPreimageLength is the maximum length of a hashlock preimage.
+	MaxPreimageLength = 64
+)
+
+var (
+	errUnknownHashAlgorithm = errors.New("unknown hash algorithm")
+	errInvalidHTLCTimeout   = errors.New("htlc timeout must be a block height")
+	errInvalidPreimage      = errors.New("invalid hashlock preimage")
+)
+
+func (a HashAlgorithm) String() string {
+	return corepb.HashAlgorithm(a).String()
+}
+
+// ParseHashAlgorithm parses the name of a hash algorithm, e.g. "sha3_256" or "sha256".
+func ParseHashAlgorithm(name string) (HashAlgorithm, error) {
+	a, ok := corepb.HashAlgorithm_value[strings.ToUpper(name)]
+	if !ok {
+		return 0, errUnknownHashAlgorithm
+	}
+	return HashAlgorithm(a), nil
+}
+
+// Sum returns the hash of preimage.
+func (a HashAlgorithm) Sum(preimage []byte) (common.Hash, error) {
+	var h common.Hash
+	switch a {
+	case HashSHA3256:
+		sum := sha3.Sum256(preimage)
+		h.SetBytes(sum[:])
+	case HashSHA256:
+		sum := sha256.Sum256(preimage)
+		h.SetBytes(sum[:])
+	default:
+		return h, errUnknownHashAlgorithm
+	}
+	return h, nil
+}
+
+// HTLCLock is the payload of a HTLC lock tx: tx value is paid to `to` with
+// the preimage of HashLock before TimeoutHeight, or refunded to `from` after it.
+type HTLCLock struct {
+	HashAlgorithm HashAlgorithm
+	HashLock      common.Hash
+	TimeoutHeight uint64
+}
+
+func validateHTLCLock(tx *TxImpl) error {
+	if _, err := DecodeHTLCLockPayload(tx.payload); err != nil {
+		return err
+	}
+	if tx.value.Sign() <= 0 {
+		return errTxValueRequired
+	}
+	return nil
+}
+
+func validateHTLCClaim(tx *TxImpl) error {
+	if _, _, err := DecodeHTLCClaimPayload(tx.payload); err != nil {
+		return err
+	}
+	if tx.from.Equals(tx.to) == false {
+		return errTxNotSelfDirected
+	}
+	if tx.value.Sign() != 0 {
+		return errTxValueNotAllowed
+	}
+	return nil
+}
+
+func validateHTLCRefund(tx *TxImpl) error {
+	if _, err := DecodeHTLCRefundPayload(tx.payload); err != nil {
+		return err
+	}
+	if tx.from.Equals(tx.to) == false {
+		return errTxNotSelfDirected
+	}
+	if tx.value.Sign() != 0 {
+		return errTxValueNotAllowed
+	}
+	return nil
+}
+
+// EncodeHTLCLockPayload encodes the hashlock and timeout of a HTLC lock tx.
+func EncodeHTLCLockPayload(lock *HTLCLock) ([]byte, error) {
+	return proto.Marshal(&corepb.HTLCLockPayload{
+		HashAlgorithm: corepb.HashAlgorithm(lock.HashAlgorithm),
+		HashLock:      lock.HashLock.CloneBytes(),
+		TimeoutHeight: lock.TimeoutHeight,
+	})
+}
+
+// DecodeHTLCLockPayload decodes and validates the payload of a HTLC lock tx.
+func DecodeHTLCLockPayload(payload []byte) (*HTLCLock, error) {
+	pbPayload := &corepb.HTLCLockPayload{}
+	if err := proto.Unmarshal(payload, pbPayload); err != nil {
+		return nil, errInvalidTxPayload
+	}
+	if _, ok := corepb.HashAlgorithm_name[int32(pbPayload.HashAlgorithm)]; !ok {
+		return nil, errUnknownHashAlgorithm
+	}
+	if len(pbPayload.HashLock) != common.HashLength {
+		return nil, errInvalidTxPayload
+	}
+	if pbPayload.TimeoutHeight == 0 || isTimeBound(pbPayload.TimeoutHeight) {
+		return nil, errInvalidHTLCTimeout
+	}
+
+	lock := &HTLCLock{
+		HashAlgorithm: HashAlgorithm(pbPayload.HashAlgorithm),
+		TimeoutHeight: pbPayload.TimeoutHeight,
+	}
+	lock.HashLock.SetBytes(pbPayload.HashLock)
+	return lock, nil
+}
+
+// EncodeHTLCClaimPayload encodes the contract id and preimage of a HTLC claim tx.
+func EncodeHTLCClaimPayload(contractID common.Hash, preimage []byte) ([]byte, error) {
+	return proto.Marshal(&corepb.HTLCClaimPayload{
+		ContractId: contractID.CloneBytes(),
+		Preimage:   preimage,
+	})
+}
+
+// DecodeHTLCClaimPayload decodes the payload of a HTLC claim tx.
+func DecodeHTLCClaimPayload(payload []byte) (common.Hash, []byte, error) {
+	var contractID common.Hash
+
+	pbPayload := &corepb.HTLCClaimPayload{}
+	if err := proto.Unmarshal(payload, pbPayload); err != nil {
+		return contractID, nil, errInvalidTxPayload
+	}
+	if len(pbPayload.ContractId) != common.HashLength {
+		return contractID, nil, errInvalidTxPayload
+	}
+	if len(pbPayload.Preimage) == 0 || len(pbPayload.Preimage) > MaxPreimageLength {
+		return contractID, nil, errInvalidPreimage
+	}
+	contractID.SetBytes(pbPayload.ContractId)
+	return contractID, pbPayload.Preimage, nil
+}
+
+// EncodeHTLCRefundPayload encodes the contract id of a HTLC refund tx.
+func EncodeHTLCRefundPayload(contractID common.Hash) ([]byte, error) {
+	return proto.Marshal(&corepb.HTLCRefundPayload{ContractId: contractID.CloneBytes()})
+}
+
+// DecodeHTLCRefundPayload decodes the payload of a HTLC refund tx.
+func DecodeHTLCRefundPayload(payload []byte) (common.Hash, error) {
+	var contractID common.Hash
+
+	pbPayload := &corepb.HTLCRefundPayload{}
+	if err := proto.Unmarshal(payload, pbPayload); err != nil {
+		return contractID, errInvalidTxPayload
+	}
+	if len(pbPayload.ContractId) != common.HashLength {
+		return contractID, errInvalidTxPayload
+	}
+	contractID.SetBytes(pbPayload.ContractId)
+	return contractID, nil
+}
@@ -0,0 +1,71 @@
+package transaction
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashAlgorithm(t *testing.T) {
+	a, err := ParseHashAlgorithm("sha256")
+	assert.Nil(t, err)
+	assert.Equal(t, HashSHA256, a)
+
+	// well known digests of "abc".
+	h, err := HashSHA256.Sum([]byte("abc"))
+	assert.Nil(t, err)
+	assert.Equal(t, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", hex.EncodeToString(h.CloneBytes()))
+	h, err = HashSHA3256.Sum([]byte("abc"))
+	assert.Nil(t, err)
+	assert.Equal(t, "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532", hex.EncodeToString(h.CloneBytes()))
+
+	_, err = ParseHashAlgorithm("md5")
+	assert.Equal(t, errUnknownHashAlgorithm, err)
+	_, err = HashAlgorithm(100).Sum([]byte("abc"))
+	assert.Equal(t, errUnknownHashAlgorithm, err)
+}
+
+func TestHTLCTxs(t *testing.T) {
+	pubKey, _ := hex.DecodeString(fromPubKey)
+	self := common.NewAddress(common.AddressVersionEd25519, pubKey)
+	to, _ := hex.DecodeString(toPubKey)
+	recipient := common.NewAddress(common.AddressVersionEd25519, to)
+
+	hashLock, _ := HashSHA256.Sum([]byte("secret"))
+	lock := &HTLCLock{HashAlgorithm: HashSHA256, HashLock: hashLock, TimeoutHeight: 100}
+	payload, err := EncodeHTLCLockPayload(lock)
+	assert.Nil(t, err)
+	tx, err := newTypedTx(TxTypeHTLCLock, payload, recipient, 10)
+	assert.Nil(t, err)
+	decoded, err := DecodeHTLCLockPayload(tx.Payload())
+	assert.Nil(t, err)
+	assert.Equal(t, lock, decoded)
+
+	_, err = newTypedTx(TxTypeHTLCLock, payload, recipient, 0)
+	assert.Equal(t, errTxValueRequired, err)
+
+	// the timeout is a block height.
+	payload, _ = EncodeHTLCLockPayload(&HTLCLock{HashAlgorithm: HashSHA256, HashLock: hashLock, TimeoutHeight: ValidityTimeThreshold})
+	_, err = newTypedTx(TxTypeHTLCLock, payload, recipient, 10)
+	assert.Equal(t, errInvalidHTLCTimeout, err)
+	payload, _ = EncodeHTLCLockPayload(&HTLCLock{HashAlgorithm: HashAlgorithm(100), HashLock: hashLock, TimeoutHeight: 100})
+	_, err = newTypedTx(TxTypeHTLCLock, payload, recipient, 10)
+	assert.Equal(t, errUnknownHashAlgorithm, err)
+
+	payload, _ = EncodeHTLCClaimPayload(tx.Hash(), []byte("secret"))
+	_, err = newTypedTx(TxTypeHTLCClaim, payload, self, 0)
+	assert.Nil(t, err)
+	_, err = newTypedTx(TxTypeHTLCClaim, payload, recipient, 0)
+	assert.Equal(t, errTxNotSelfDirected, err)
+	payload, _ = EncodeHTLCClaimPayload(tx.Hash(), make([]byte, MaxPreimageLength+1))
+	_, err = newTypedTx(TxTypeHTLCClaim, payload, self, 0)
+	assert.Equal(t, errInvalidPreimage, err)
+
+	payload, _ = EncodeHTLCRefundPayload(tx.Hash())
+	_, err = newTypedTx(TxTypeHTLCRefund, payload, self, 0)
+	assert.Nil(t, err)
+	_, err = newTypedTx(TxTypeHTLCRefund, payload, self, 10)
+	assert.Equal(t, errTxValueNotAllowed, err)
+}
@@ -0,0 +1,301 @@
+package transaction
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/crypto/bls"
+	"github.com/ldmtam/tam-chain/proto"
+	"golang.org/x/crypto/ed25519"
+)
+
+// TxType is the type of a transaction.
+type TxType int32
+
+// Tx types
+const (
+	// TxTypeTransfer transfers value from `from` to the existing account `to`.
+	TxTypeTransfer = TxType(corepb.TxType_TRANSFER)
+	// TxTypeCreateAccount creates account `to` with initial keys and funds it with value.
+	TxTypeCreateAccount = TxType(corepb.TxType_CREATE_ACCOUNT)
+	// TxTypeRotateKey replaces the keys of `from` account.
+	TxTypeRotateKey = TxType(corepb.TxType_ROTATE_KEY)
+	// TxTypeRegisterValidator registers `from` as a validator staking value.
+	TxTypeRegisterValidator = TxType(corepb.TxType_REGISTER_VALIDATOR)
+	// TxTypeAnchorData records a hash of arbitrary data on chain.
+	TxTypeAnchorData = TxType(corepb.TxType_ANCHOR_DATA)
+	// TxTypeTimeLockTransfer escrows value from `from` until it's released to `to` at the unlock bound.
+	TxTypeTimeLockTransfer = TxType(corepb.TxType_TIME_LOCK_TRANSFER)
+	// TxTypeCancelTimeLock returns the value of a time lock created by `from` before it's released.
+	TxTypeCancelTimeLock = TxType(corepb.TxType_CANCEL_TIME_LOCK)
+	// TxTypeHTLCLock locks value to `to` under a hashlock and a timeout, see HTLCLock.
+	TxTypeHTLCLock = TxType(corepb.TxType_HTLC_LOCK)
+	// TxTypeHTLCClaim pays a HTLC to its recipient with the preimage of the hashlock.
+	TxTypeHTLCClaim = TxType(corepb.TxType_HTLC_CLAIM)
+	// TxTypeHTLCRefund returns a timed out HTLC to its sender.
+	TxTypeHTLCRefund = TxType(corepb.TxType_HTLC_REFUND)
+	// TxTypeContractDeploy deploys contract code at `to`, which is derived from `from` and the nonce.
+	TxTypeContractDeploy = TxType(corepb.TxType_CONTRACT_DEPLOY)
+	// TxTypeContractCall calls the contract at `to` with value.
+	TxTypeContractCall = TxType(corepb.TxType_CONTRACT_CALL)
+)
+
+const (
+	// MaxAccountKeys is the maximum number of keys an account can have.
+	MaxAccountKeys = 8
+)
+
+var (
+	errUnknownTxType         = errors.New("unknown transaction type")
+	errInvalidTxPayload      = errors.New("invalid transaction payload")
+	errInvalidAccountKeys    = errors.New("invalid account keys")
+	errTxNotSelfDirected     = errors.New("`to` address must be the sender's address")
+	errTxValueNotAllowed     = errors.New("transaction type does not take value")
+	errTxStakeRequired       = errors.New("validator registration requires stake")
+	errInvalidValidatorProof = errors.New("invalid proof of possession of validator key")
+	errNewAccountAddress     = errors.New("`to` address must be derived from the first key of the new account")
+	errTxValueRequired       = errors.New("transaction type requires value")
+)
+
+func (t TxType) String() string {
+	return corepb.TxType(t).String()
+}
+
+// ParseTxType parses the name of a tx type, e.g. "transfer" or "anchor_data".
+func ParseTxType(name string) (TxType, error) {
+	t, ok := corepb.TxType_value[strings.ToUpper(name)]
+	if !ok {
+		return 0, errUnknownTxType
+	}
+	return TxType(t), nil
+}
+
+// payloadValidators check the type-specific fields of txs, they are run by VerifyIntegrity.
+var payloadValidators = map[TxType]func(tx *TxImpl) error{
+	TxTypeTransfer:          validateTransfer,
+	TxTypeCreateAccount:     validateCreateAccount,
+	TxTypeRotateKey:         validateRotateKey,
+	TxTypeRegisterValidator: validateRegisterValidator,
+	TxTypeAnchorData:        validateAnchorData,
+	TxTypeTimeLockTransfer:  validateTimeLockTransfer,
+	TxTypeCancelTimeLock:    validateCancelTimeLock,
+	TxTypeHTLCLock:          validateHTLCLock,
+	TxTypeHTLCClaim:         validateHTLCClaim,
+	TxTypeHTLCRefund:        validateHTLCRefund,
+	TxTypeContractDeploy:    validateContractDeploy,
+	TxTypeContractCall:      validateContractCall,
+}
+
+func (tx *TxImpl) validatePayload() error {
+	validate, ok := payloadValidators[tx.txType]
+	if !ok {
+		return errUnknownTxType
+	}
+	return validate(tx)
+}
+
+func validateTransfer(tx *TxImpl) error {
+	if len(tx.payload) != 0 {
+		return errInvalidTxPayload
+	}
+	return nil
+}
+
+func validateCreateAccount(tx *TxImpl) error {
+	keys, err := DecodeKeysPayload(tx.payload)
+	if err != nil {
+		return err
+	}
+	if common.NewAddress(common.AddressVersionEd25519, keys[0]).Equals(tx.to) == false {
+		return errNewAccountAddress
+	}
+	return nil
+}
+
+func validateRotateKey(tx *TxImpl) error {
+	if _, err := DecodeKeysPayload(tx.payload); err != nil {
+		return err
+	}
+	if tx.from.Equals(tx.to) == false {
+		return errTxNotSelfDirected
+	}
+	if tx.value.Sign() != 0 {
+		return errTxValueNotAllowed
+	}
+	return nil
+}
+
+func validateRegisterValidator(tx *TxImpl) error {
+	pubKey, proof, err := DecodeValidatorPayload(tx.payload)
+	if err != nil {
+		return err
+	}
+	if pubKey.VerifyPossession(proof) == false {
+		return errInvalidValidatorProof
+	}
+	if tx.from.Equals(tx.to) == false {
+		return errTxNotSelfDirected
+	}
+	if tx.value.Sign() <= 0 {
+		return errTxStakeRequired
+	}
+	return nil
+}
+
+func validateAnchorData(tx *TxImpl) error {
+	if _, err := DecodeAnchorPayload(tx.payload); err != nil {
+		return err
+	}
+	if tx.from.Equals(tx.to) == false {
+		return errTxNotSelfDirected
+	}
+	if tx.value.Sign() != 0 {
+		return errTxValueNotAllowed
+	}
+	return nil
+}
+
+func validateTimeLockTransfer(tx *TxImpl) error {
+	if _, err := DecodeTimeLockPayload(tx.payload); err != nil {
+		return err
+	}
+	if tx.value.Sign() <= 0 {
+		return errTxValueRequired
+	}
+	return nil
+}
+
+func validateCancelTimeLock(tx *TxImpl) error {
+	if _, err := DecodeCancelTimeLockPayload(tx.payload); err != nil {
+		return err
+	}
+	if tx.from.Equals(tx.to) == false {
+		return errTxNotSelfDirected
+	}
+	if tx.value.Sign() != 0 {
+		return errTxValueNotAllowed
+	}
+	return nil
+}
+
+// EncodeKeysPayload encodes the ed25519 public keys of an account creation or key rotation tx.
+func EncodeKeysPayload(keys [][]byte) ([]byte, error) {
+	if err := validateKeys(keys); err != nil {
+		return nil, err
+	}
+	return proto.Marshal(&corepb.KeysPayload{Keys: keys})
+}
+
+// DecodeKeysPayload decodes and validates the payload of an account creation or key rotation tx.
+func DecodeKeysPayload(payload []byte) ([][]byte, error) {
+	pbPayload := &corepb.KeysPayload{}
+	if err := proto.Unmarshal(payload, pbPayload); err != nil {
+		return nil, errInvalidTxPayload
+	}
+	if err := validateKeys(pbPayload.Keys); err != nil {
+		return nil, err
+	}
+	return pbPayload.Keys, nil
+}
+
+// validateKeys checks keys are distinct ed25519 public keys.
+func validateKeys(keys [][]byte) error {
+	if len(keys) == 0 || len(keys) > MaxAccountKeys {
+		return errInvalidAccountKeys
+	}
+	for i, key := range keys {
+		if len(key) != ed25519.PublicKeySize {
+			return errInvalidAccountKeys
+		}
+		for _, other := range keys[:i] {
+			if common.Equal(key, other) {
+				return errInvalidAccountKeys
+			}
+		}
+	}
+	return nil
+}
+
+// EncodeValidatorPayload encodes the bls key and its proof of possession of a validator registration tx.
+func EncodeValidatorPayload(pubKey *bls.PublicKey, proof *bls.Signature) ([]byte, error) {
+	return proto.Marshal(&corepb.ValidatorPayload{
+		BlsPublicKey:      pubKey.Marshal(),
+		ProofOfPossession: proof.Marshal(),
+	})
+}
+
+// DecodeValidatorPayload decodes the payload of a validator registration tx.
+func DecodeValidatorPayload(payload []byte) (*bls.PublicKey, *bls.Signature, error) {
+	pbPayload := &corepb.ValidatorPayload{}
+	if err := proto.Unmarshal(payload, pbPayload); err != nil {
+		return nil, nil, errInvalidTxPayload
+	}
+	pubKey, err := bls.UnmarshalPublicKey(pbPayload.BlsPublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	proof, err := bls.UnmarshalSignature(pbPayload.ProofOfPossession)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pubKey, proof, nil
+}
+
+// EncodeAnchorPayload encodes the data hash of a data anchor tx.
+func EncodeAnchorPayload(dataHash common.Hash) ([]byte, error) {
+	return proto.Marshal(&corepb.AnchorPayload{DataHash: dataHash.CloneBytes()})
+}
+
+// DecodeAnchorPayload decodes the payload of a data anchor tx.
+func DecodeAnchorPayload(payload []byte) (common.Hash, error) {
+	var dataHash common.Hash
+
+	pbPayload := &corepb.AnchorPayload{}
+	if err := proto.Unmarshal(payload, pbPayload); err != nil {
+		return dataHash, errInvalidTxPayload
+	}
+	if len(pbPayload.DataHash) != common.HashLength {
+		return dataHash, errInvalidTxPayload
+	}
+	dataHash.SetBytes(pbPayload.DataHash)
+	return dataHash, nil
+}
+
+// EncodeTimeLockPayload encodes the unlock bound of a time-locked transfer tx.
+func EncodeTimeLockPayload(unlockAt uint64) ([]byte, error) {
+	return proto.Marshal(&corepb.TimeLockPayload{UnlockAt: unlockAt})
+}
+
+// DecodeTimeLockPayload decodes the payload of a time-locked transfer tx.
+func DecodeTimeLockPayload(payload []byte) (uint64, error) {
+	pbPayload := &corepb.TimeLockPayload{}
+	if err := proto.Unmarshal(payload, pbPayload); err != nil {
+		return 0, errInvalidTxPayload
+	}
+	if pbPayload.UnlockAt == 0 {
+		return 0, errInvalidTxPayload
+	}
+	return pbPayload.UnlockAt, nil
+}
+
+// EncodeCancelTimeLockPayload encodes the time lock id of a time lock cancellation tx.
+func EncodeCancelTimeLockPayload(lockID common.Hash) ([]byte, error) {
+	return proto.Marshal(&corepb.CancelTimeLockPayload{LockId: lockID.CloneBytes()})
+}
+
+// DecodeCancelTimeLockPayload decodes the payload of a time lock cancellation tx.
+func DecodeCancelTimeLockPayload(payload []byte) (common.Hash, error) {
+	var lockID common.Hash
+
+	pbPayload := &corepb.CancelTimeLockPayload{}
+	if err := proto.Unmarshal(payload, pbPayload); err != nil {
+		return lockID, errInvalidTxPayload
+	}
+	if len(pbPayload.LockId) != common.HashLength {
+		return lockID, errInvalidTxPayload
+	}
+	lockID.SetBytes(pbPayload.LockId)
+	return lockID, nil
+}
@@ -0,0 +1,179 @@
+package transaction
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/crypto/bls"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTypedTx(txType TxType, payload []byte, to common.Address, value int64) (*TxImpl, error) {
+	pubKey, _ := hex.DecodeString(fromPubKey)
+	from := common.NewAddress(common.AddressVersionEd25519, pubKey)
+	return NewTypedTransaction(txType, payload, chainID, from, pubKey, to, big.NewInt(value), big.NewInt(1), 1, 1557360000, nil)
+}
+
+func TestParseTxType(t *testing.T) {
+	txType, err := ParseTxType("anchor_data")
+	assert.Nil(t, err)
+	assert.Equal(t, TxTypeAnchorData, txType)
+	assert.Equal(t, "ANCHOR_DATA", txType.String())
+
+	_, err = ParseTxType("mint")
+	assert.Equal(t, errUnknownTxType, err)
+}
+
+func TestCreateAccountTx(t *testing.T) {
+	newKey, _ := hex.DecodeString(toPubKey)
+	otherKey, _ := hex.DecodeString(fromPubKey)
+	newAddr := common.NewAddress(common.AddressVersionEd25519, newKey)
+
+	payload, err := EncodeKeysPayload([][]byte{newKey, otherKey})
+	assert.Nil(t, err)
+
+	tx, err := newTypedTx(TxTypeCreateAccount, payload, newAddr, 10)
+	assert.Nil(t, err)
+	tx.Sign(decodeKeyPair(fromPrivKey, fromPubKey))
+	assert.Nil(t, tx.VerifyIntegrity(accounts))
+
+	keys, err := DecodeKeysPayload(tx.Payload())
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{newKey, otherKey}, keys)
+
+	// `to` must be derived from the first key.
+	_, err = newTypedTx(TxTypeCreateAccount, payload, common.NewAddress(common.AddressVersionEd25519, otherKey), 10)
+	assert.Equal(t, errNewAccountAddress, err)
+
+	_, err = EncodeKeysPayload([][]byte{newKey, newKey})
+	assert.Equal(t, errInvalidAccountKeys, err)
+	_, err = EncodeKeysPayload([][]byte{newKey[1:]})
+	assert.Equal(t, errInvalidAccountKeys, err)
+	_, err = EncodeKeysPayload(nil)
+	assert.Equal(t, errInvalidAccountKeys, err)
+}
+
+func TestRotateKeyTx(t *testing.T) {
+	pubKey, _ := hex.DecodeString(fromPubKey)
+	newKey, _ := hex.DecodeString(toPubKey)
+	self := common.NewAddress(common.AddressVersionEd25519, pubKey)
+
+	payload, err := EncodeKeysPayload([][]byte{newKey})
+	assert.Nil(t, err)
+
+	tx, err := newTypedTx(TxTypeRotateKey, payload, self, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, TxTypeRotateKey, tx.Type())
+
+	_, err = newTypedTx(TxTypeRotateKey, payload, self, 1)
+	assert.Equal(t, errTxValueNotAllowed, err)
+
+	_, err = newTypedTx(TxTypeRotateKey, payload, common.NewAddress(common.AddressVersionEd25519, newKey), 0)
+	assert.Equal(t, errTxNotSelfDirected, err)
+
+	_, err = newTypedTx(TxTypeRotateKey, []byte{0xff}, self, 0)
+	assert.Equal(t, errInvalidTxPayload, err)
+}
+
+func TestRegisterValidatorTx(t *testing.T) {
+	pubKey, _ := hex.DecodeString(fromPubKey)
+	self := common.NewAddress(common.AddressVersionEd25519, pubKey)
+
+	sk, err := bls.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+	other, err := bls.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+
+	payload, err := EncodeValidatorPayload(sk.PublicKey(), sk.ProvePossession())
+	assert.Nil(t, err)
+
+	tx, err := newTypedTx(TxTypeRegisterValidator, payload, self, 1000)
+	assert.Nil(t, err)
+
+	blsKey, _, err := DecodeValidatorPayload(tx.Payload())
+	assert.Nil(t, err)
+	assert.Equal(t, sk.PublicKey().Marshal(), blsKey.Marshal())
+
+	_, err = newTypedTx(TxTypeRegisterValidator, payload, self, 0)
+	assert.Equal(t, errTxStakeRequired, err)
+
+	// proof made by another key is rejected.
+	payload, err = EncodeValidatorPayload(sk.PublicKey(), other.ProvePossession())
+	assert.Nil(t, err)
+	_, err = newTypedTx(TxTypeRegisterValidator, payload, self, 1000)
+	assert.Equal(t, errInvalidValidatorProof, err)
+}
+
+func TestAnchorDataTx(t *testing.T) {
+	pubKey, _ := hex.DecodeString(fromPubKey)
+	self := common.NewAddress(common.AddressVersionEd25519, pubKey)
+
+	var dataHash common.Hash
+	dataHash.SetBytes(make([]byte, common.HashLength))
+	dataHash[0] = 1
+
+	payload, err := EncodeAnchorPayload(dataHash)
+	assert.Nil(t, err)
+
+	tx, err := newTypedTx(TxTypeAnchorData, payload, self, 0)
+	assert.Nil(t, err)
+	tx.Sign(decodeKeyPair(fromPrivKey, fromPubKey))
+
+	// type and payload survive encoding and are covered by the hash.
+	b, err := tx.Marshal()
+	assert.Nil(t, err)
+	newTx := &TxImpl{}
+	assert.Nil(t, newTx.Unmarshal(b))
+	assert.Nil(t, newTx.VerifyIntegrity(accounts))
+	anchored, err := DecodeAnchorPayload(newTx.Payload())
+	assert.Nil(t, err)
+	assert.Equal(t, dataHash, anchored)
+
+	newTx.txType = TxTypeRotateKey
+	assert.NotNil(t, newTx.VerifyIntegrity(accounts))
+
+	newTx.txType = TxType(100)
+	assert.Equal(t, errUnknownTxType, newTx.VerifyIntegrity(accounts))
+
+	_, err = newTypedTx(TxTypeTransfer, payload, self, 0)
+	assert.Equal(t, errInvalidTxPayload, err)
+}
+
+func TestTimeLockTxs(t *testing.T) {
+	pubKey, _ := hex.DecodeString(fromPubKey)
+	self := common.NewAddress(common.AddressVersionEd25519, pubKey)
+	to, _ := hex.DecodeString(toPubKey)
+	recipient := common.NewAddress(common.AddressVersionEd25519, to)
+
+	payload, err := EncodeTimeLockPayload(100)
+	assert.Nil(t, err)
+	tx, err := newTypedTx(TxTypeTimeLockTransfer, payload, recipient, 10)
+	assert.Nil(t, err)
+	tx.Sign(decodeKeyPair(fromPrivKey, fromPubKey))
+	assert.Nil(t, tx.VerifyIntegrity(accounts))
+	unlockAt, err := DecodeTimeLockPayload(tx.Payload())
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), unlockAt)
+
+	_, err = newTypedTx(TxTypeTimeLockTransfer, payload, recipient, 0)
+	assert.Equal(t, errTxValueRequired, err)
+	noBound, _ := EncodeTimeLockPayload(0)
+	_, err = newTypedTx(TxTypeTimeLockTransfer, noBound, recipient, 10)
+	assert.Equal(t, errInvalidTxPayload, err)
+
+	payload, err = EncodeCancelTimeLockPayload(tx.Hash())
+	assert.Nil(t, err)
+	cancel, err := newTypedTx(TxTypeCancelTimeLock, payload, self, 0)
+	assert.Nil(t, err)
+	lockID, err := DecodeCancelTimeLockPayload(cancel.Payload())
+	assert.Nil(t, err)
+	assert.Equal(t, tx.Hash(), lockID)
+
+	_, err = newTypedTx(TxTypeCancelTimeLock, payload, recipient, 0)
+	assert.Equal(t, errTxNotSelfDirected, err)
+	_, err = newTypedTx(TxTypeCancelTimeLock, payload, self, 10)
+	assert.Equal(t, errTxValueNotAllowed, err)
+}
@@ -9,9 +9,7 @@ import (
 	"github.com/ldmtam/tam-chain/abstraction"
 	"github.com/ldmtam/tam-chain/account"
 	"github.com/ldmtam/tam-chain/common"
-	"github.com/ldmtam/tam-chain/crypto/sha3"
 	"github.com/ldmtam/tam-chain/proto"
-	"github.com/mr-tron/base58/base58"
 	"golang.org/x/crypto/ed25519"
 )
 
@@ -21,36 +19,82 @@ var (
 	errInvalidTransactionToProto   = errors.New("transaction cannot be converted to protobuf message")
 	errInvalidTransacionHash       = errors.New("invalid transaction hash")
 	errInvalidTransactionSignature = errors.New("invalid transaction signature")
+	errInvalidTransactionPublicKey = errors.New("public key is not authorized by `from` account")
+	errTxMemoTooLong               = errors.New("transaction memo is too long")
+	errTxTooLarge                  = errors.New("encoded transaction is too large")
+)
+
+const (
+	// MaxMemoLength is the maximum length of tx memo.
+	MaxMemoLength = 512
+	// MaxTxSize is the maximum size of an encoded tx in bytes.
+	MaxTxSize = 32 * 1024
 )
 
 // TxImpl struct of a transaction
 type TxImpl struct {
 	hash      common.Hash
+	version   uint32
 	chainID   uint32
 	from      common.Address
 	to        common.Address
 	value     *big.Int
 	fee       *big.Int
+	tip       *big.Int
 	nonce     uint64
 	timestamp int64
+	memo      []byte
+	txType    TxType
+	payload   []byte
+	gasLimit  uint64 // derived from the payload of contract txs
+
+	validAfter uint64
+	validUntil uint64
 
 	signature []byte
+	pubKey    []byte
+}
+
+// NewTransaction returns new transfer transaction, `from` address is derived from the sender's public key.
+func NewTransaction(chainID uint32, pubKey []byte, to common.Address, value, fee *big.Int, nonce uint64, timestamp int64, memo []byte) (*TxImpl, error) {
+	from := common.NewAddress(common.AddressVersionEd25519, pubKey)
+	return NewTypedTransaction(TxTypeTransfer, nil, chainID, from, pubKey, to, value, fee, nonce, timestamp, memo)
 }
 
-// NewTransaction returns new transaction
-func NewTransaction(chainID uint32, from, to common.Address, value, fee *big.Int, nonce uint64, timestamp int64) (*TxImpl, error) {
-	if chainID == 0 || from.CloneBytes() == nil || to.CloneBytes() == nil || value == nil || fee == nil {
+// NewTypedTransaction returns new transaction of the given type with its type-specific payload.
+// pubKey is the key signing the tx, it must be one of the current keys of `from` account.
+func NewTypedTransaction(txType TxType, payload []byte, chainID uint32, from common.Address, pubKey []byte, to common.Address, value, fee *big.Int, nonce uint64, timestamp int64, memo []byte) (*TxImpl, error) {
+	if chainID == 0 || !from.IsValid() || len(pubKey) != ed25519.PublicKeySize || !to.IsValid() || value == nil || fee == nil {
 		return nil, errTxInvalidArgument
 	}
+	if value.Sign() < 0 || fee.Sign() < 0 {
+		return nil, errTxInvalidArgument
+	}
+	if len(memo) > MaxMemoLength {
+		return nil, errTxMemoTooLong
+	}
 
 	txImpl := &TxImpl{
+		version:   CurrentTxVersion,
 		chainID:   chainID,
 		from:      from,
+		pubKey:    pubKey,
 		to:        to,
 		value:     value,
 		fee:       fee,
+		tip:       new(big.Int),
 		nonce:     nonce,
 		timestamp: timestamp,
+		memo:      memo,
+		txType:    txType,
+		payload:   payload,
+		gasLimit:  payloadGasLimit(txType, payload),
+	}
+	if err := txImpl.validatePayload(); err != nil {
+		return nil, err
+	}
+	if txImpl.Size() > MaxTxSize {
+		return nil, errTxTooLarge
 	}
 	hash, err := txImpl.calcHash()
 	if err != nil {
@@ -60,6 +104,11 @@ func NewTransaction(chainID uint32, from, to common.Address, value, fee *big.Int
 	return txImpl, nil
 }
 
+// Version returns tx version.
+func (tx *TxImpl) Version() uint32 {
+	return tx.version
+}
+
 // ChainID returns `chainID`.
 func (tx *TxImpl) ChainID() uint32 {
 	return tx.chainID
@@ -80,7 +129,7 @@ func (tx *TxImpl) Value() *big.Int {
 	return tx.value
 }
 
-// Fee returns tx fee value
+// Fee returns the maximum fee the sender pays for the tx.
 func (tx *TxImpl) Fee() *big.Int {
 	return tx.fee
 }
@@ -95,18 +144,38 @@ func (tx *TxImpl) Timestamp() int64 {
 	return tx.timestamp
 }
 
+// Memo returns memo of the tx, it's usually encrypted to the recipient.
+func (tx *TxImpl) Memo() []byte {
+	return tx.memo
+}
+
+// Type returns tx type.
+func (tx *TxImpl) Type() TxType {
+	return tx.txType
+}
+
+// Payload returns the type-specific payload of the tx.
+func (tx *TxImpl) Payload() []byte {
+	return tx.payload
+}
+
 // Signature returns signature of the tx.
 func (tx *TxImpl) Signature() []byte {
 	return tx.signature
 }
 
+// PublicKey returns public key of the sender.
+func (tx *TxImpl) PublicKey() []byte {
+	return tx.pubKey
+}
+
 // Hash returns hash of transaction.
 func (tx *TxImpl) Hash() common.Hash {
 	return tx.hash
 }
 
-// Marshal encodes tx using protobuf
-func (tx *TxImpl) Marshal() ([]byte, error) {
+// toProto converts tx to protobuf message.
+func (tx *TxImpl) toProto() *corepb.Transaction {
 	txFrom := tx.from.CloneBytes()
 	txTo := tx.to.CloneBytes()
 	txHash := tx.hash.CloneBytes()
@@ -123,9 +192,22 @@ func (tx *TxImpl) Marshal() ([]byte, error) {
 		Nonce:     tx.nonce,
 		Timestamp: tx.timestamp,
 		Signature: tx.signature,
+		PublicKey: tx.pubKey,
+		Memo:      tx.memo,
+		Version:   tx.version,
+		Type:      corepb.TxType(tx.txType),
+		Payload:   tx.payload,
+
+		ValidAfter: tx.validAfter,
+		ValidUntil: tx.validUntil,
+		Tip:        tx.tip.Bytes(),
 	}
+	return pbTx
+}
 
-	serializedData, err := proto.Marshal(pbTx)
+// Marshal encodes tx using protobuf
+func (tx *TxImpl) Marshal() ([]byte, error) {
+	serializedData, err := proto.Marshal(tx.toProto())
 	if err != nil {
 		return nil, errInvalidTransactionToProto
 	}
@@ -134,6 +216,9 @@ func (tx *TxImpl) Marshal() ([]byte, error) {
 
 // Unmarshal decode tx using protobuf
 func (tx *TxImpl) Unmarshal(data []byte) error {
+	if len(data) > MaxTxSize {
+		return errTxTooLarge
+	}
 	pbTx := &corepb.Transaction{}
 	err := proto.Unmarshal(data, pbTx)
 	if err != nil {
@@ -141,6 +226,8 @@ func (tx *TxImpl) Unmarshal(data []byte) error {
 	}
 	tx.hash.SetBytes(pbTx.Hash)
 
+	tx.version = pbTx.Version
+
 	tx.chainID = pbTx.Chainid
 
 	tx.from.SetBytes(pbTx.From)
@@ -154,20 +241,39 @@ func (tx *TxImpl) Unmarshal(data []byte) error {
 	tx.fee = new(big.Int)
 	tx.fee.SetBytes(pbTx.Fee)
 
+	tx.tip = new(big.Int)
+	tx.tip.SetBytes(pbTx.Tip)
+
 	tx.nonce = pbTx.Nonce
 
 	tx.timestamp = pbTx.Timestamp
 
+	tx.memo = pbTx.Memo
+
+	tx.txType = TxType(pbTx.Type)
+
+	tx.payload = pbTx.Payload
+
+	tx.gasLimit = payloadGasLimit(tx.txType, tx.payload)
+
+	tx.validAfter = pbTx.ValidAfter
+
+	tx.validUntil = pbTx.ValidUntil
+
 	tx.signature = pbTx.Signature
+
+	tx.pubKey = pbTx.PublicKey
 	return nil
 }
 
 func (tx *TxImpl) String() string {
-	return fmt.Sprintf(`{"hash":"%s", "chain id":"%v", "from":"%s", "to":"%s", "value":"%s", "fee":"%s", "nonce":"%v", "timestamp":"%v"}`,
+	return fmt.Sprintf(`{"hash":"%s", "version":"%v", "type":"%s", "chain id":"%v", "from":"%s", "to":"%s", "value":"%s", "fee":"%s", "nonce":"%v", "timestamp":"%v"}`,
 		tx.hash.String(),
+		tx.version,
+		tx.txType,
 		tx.chainID,
-		base58.Encode(tx.From()),
-		base58.Encode(tx.To()),
+		tx.from.String(),
+		tx.to.String(),
 		tx.value,
 		tx.fee,
 		tx.nonce,
@@ -189,29 +295,62 @@ func (tx *TxImpl) Verify(pubKey []byte) bool {
 	return kp.Verify(tx.signature, tx.hash.CloneBytes())
 }
 
-// calcHash calculate hash of the transaction.
-func (tx *TxImpl) calcHash() (common.Hash, error) {
-	hasher := sha3.New256()
+// VerifyIntegrity verifies transaction information, the signing key is checked
+// against the keys of `from` account read from accounts.
+func (tx *TxImpl) VerifyIntegrity(accounts abstraction.AccountReader) error {
+	if err := tx.VerifySignature(); err != nil {
+		return err
+	}
 
-	value := tx.value.String()
-	fee := tx.fee.String()
+	// verify public key is authorized by `from` account
+	return tx.VerifyPublicKey(accounts)
+}
 
-	hasher.Write(common.FromUint32(tx.chainID))
-	hasher.Write(tx.From())
-	hasher.Write(tx.To())
-	hasher.Write([]byte(value))
-	hasher.Write([]byte(fee))
-	hasher.Write(common.FromUint64(tx.nonce))
-	hasher.Write(common.FromInt64(tx.timestamp))
+// VerifySignature verifies transaction information and the signature by the
+// signing key without reading any state, so the key may not be authorized by
+// `from` account.
+func (tx *TxImpl) VerifySignature() error {
+	if err := tx.VerifyData(); err != nil {
+		return err
+	}
 
-	var h common.Hash
-	h.SetBytes(hasher.Sum(nil))
+	// verify signature
+	if isValidSignature := tx.Verify(tx.pubKey); isValidSignature == false {
+		return errInvalidTransactionSignature
+	}
 
-	return h, nil
+	return nil
 }
 
-// VerifyIntegrity verifies transaction information
-func (tx *TxImpl) VerifyIntegrity() error {
+// VerifyData verifies transaction information except the signature, which
+// is left to the caller so signatures of many txs can be verified at once.
+func (tx *TxImpl) VerifyData() error {
+	if tx.Size() > MaxTxSize {
+		return errTxTooLarge
+	}
+	if len(tx.memo) > MaxMemoLength {
+		return errTxMemoTooLong
+	}
+
+	// legacy txs are hashed without separators between value and fee, so
+	// their signature does not bind the amounts. They are only decoded.
+	if tx.version == TxVersionLegacy {
+		return errUnsupportedTxVersion
+	}
+
+	if err := tx.validateWindow(); err != nil {
+		return err
+	}
+
+	if tx.tip.Cmp(tx.fee) > 0 {
+		return errTipAboveFee
+	}
+
+	// verify type-specific fields
+	if err := tx.validatePayload(); err != nil {
+		return err
+	}
+
 	// verify tx hash
 	wantedHash, err := tx.calcHash()
 	if err != nil {
@@ -221,10 +360,37 @@ func (tx *TxImpl) VerifyIntegrity() error {
 		return errInvalidTransacionHash
 	}
 
-	// verify signature
-	if isValidSignature := tx.Verify(tx.From()); isValidSignature == false {
-		return errInvalidTransactionSignature
+	if len(tx.pubKey) != ed25519.PublicKeySize {
+		return errInvalidTransactionPublicKey
 	}
 
 	return nil
 }
+
+// VerifyPublicKey checks the signing key is one of the current keys of `from`
+// account. Accounts which never set their keys are controlled by the key
+// their address is derived from.
+func (tx *TxImpl) VerifyPublicKey(accounts abstraction.AccountReader) error {
+	if len(tx.pubKey) != ed25519.PublicKeySize {
+		return errInvalidTransactionPublicKey
+	}
+
+	acc, err := accounts.GetAccount(tx.from)
+	if err != nil {
+		return err
+	}
+	keys := acc.Keys()
+	if len(keys) == 0 {
+		if common.NewAddress(common.AddressVersionEd25519, tx.pubKey).Equals(tx.from) {
+			return nil
+		}
+		return errInvalidTransactionPublicKey
+	}
+
+	for _, key := range keys {
+		if common.Equal(key, tx.pubKey) {
+			return nil
+		}
+	}
+	return errInvalidTransactionPublicKey
+}
@@ -7,10 +7,15 @@ import (
 	"time"
 
 	"github.com/ldmtam/tam-chain/account"
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/state"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ed25519"
 )
 
 const (
+	chainID = uint32(1)
+
 	fromPrivKey = "272e1da3327afa205dffe5acf8794b1ce79278b3ef44245b49754bdf0bbe8a77e05f3e24f866e33929120458507ee42c3cc2b3ab86dffe147ed0cbb9ba3d06c2"
 	fromPubKey  = "e05f3e24f866e33929120458507ee42c3cc2b3ab86dffe147ed0cbb9ba3d06c2"
 
@@ -18,6 +23,11 @@ const (
 	toPubKey  = "2af9c075359c199ec85c69d5f737149e4048c6c7b3f69a8ed917903192c73a1d"
 )
 
+var (
+	// accounts is an empty state, so signing keys are checked against addresses.
+	accounts = state.NewStateDB()
+)
+
 func TestCreateTransaction(t *testing.T) {
 	from, err := hex.DecodeString(fromPubKey)
 	assert.Nil(t, err)
@@ -30,12 +40,20 @@ func TestCreateTransaction(t *testing.T) {
 	value := big.NewInt(int64(20))
 	assert.NotNil(t, value)
 
+	fee := big.NewInt(int64(1))
+	assert.NotNil(t, fee)
+
 	nonce := uint64(1)
 	timestamp := time.Now().Unix()
 
-	tx, _ := NewTransaction(from, to, value, nonce, timestamp)
+	toAddr := common.NewAddress(common.AddressVersionEd25519, to)
+	tx, err := NewTransaction(chainID, from, toAddr, value, fee, nonce, timestamp, nil)
 	assert.Nil(t, err)
 	assert.NotNil(t, tx)
+	assert.Equal(t, common.NewAddress(common.AddressVersionEd25519, from), tx.from)
+
+	_, err = NewTransaction(chainID, from[1:], toAddr, value, fee, nonce, timestamp, nil)
+	assert.Equal(t, errTxInvalidArgument, err)
 }
 
 func createTx() *TxImpl {
@@ -45,24 +63,35 @@ func createTx() *TxImpl {
 
 	value := big.NewInt(int64(20))
 
+	fee := big.NewInt(int64(1))
+
 	nonce := uint64(1)
 
 	timestamp := time.Now().Unix()
 
-	tx, _ := NewTransaction(from, to, value, nonce, timestamp)
+	tx, _ := NewTransaction(chainID, from, common.NewAddress(common.AddressVersionEd25519, to), value, fee, nonce, timestamp, nil)
 	return tx
 }
 
+func decodeKeyPair(privKey, pubKey string) *account.KeyPairImpl {
+	priv, _ := hex.DecodeString(privKey)
+	pub, _ := hex.DecodeString(pubKey)
+	return &account.KeyPairImpl{
+		PrivateKey: ed25519.PrivateKey(priv),
+		PublicKey:  ed25519.PublicKey(pub),
+	}
+}
+
 func TestGetFrom(t *testing.T) {
 	tx := createTx()
 
-	assert.Equal(t, tx.from, tx.From())
+	assert.Equal(t, tx.from.CloneBytes(), tx.From())
 }
 
 func TestGetTo(t *testing.T) {
 	tx := createTx()
 
-	assert.Equal(t, tx.to, tx.To())
+	assert.Equal(t, tx.to.CloneBytes(), tx.To())
 }
 
 func TestGetValue(t *testing.T) {
@@ -92,19 +121,108 @@ func TestGetHash(t *testing.T) {
 func TestSignTx(t *testing.T) {
 	tx := createTx()
 
-	fromKp := &account.KeyPairImpl{}
-	err := fromKp.DecodePrivateKey(fromPrivKey)
-	assert.Nil(t, err)
-	err = fromKp.DecodePublicKey(fromPubKey)
-	assert.Nil(t, err)
+	fromKp := decodeKeyPair(fromPrivKey, fromPubKey)
 
 	tx.Sign(fromKp)
-	assert.Nil(t, err)
 	assert.NotNil(t, tx.signature)
+	assert.Nil(t, tx.VerifyIntegrity(accounts))
+
+	toKp := decodeKeyPair(toPrivKey, toPubKey)
+
+	tx.Sign(toKp)
+	assert.Equal(t, errInvalidTransactionSignature, tx.VerifyIntegrity(accounts))
+	assert.Equal(t, errInvalidTransactionSignature, tx.VerifySignature())
+}
+
+func TestVerifyPublicKey(t *testing.T) {
+	tx := createTx()
+
+	tx.pubKey, _ = hex.DecodeString(toPubKey)
+	tx.hash, _ = tx.calcHash()
+	tx.Sign(decodeKeyPair(toPrivKey, toPubKey))
+	assert.Equal(t, errInvalidTransactionPublicKey, tx.VerifyIntegrity(accounts))
+	// the signature is valid, the key is not authorized.
+	assert.Nil(t, tx.VerifySignature())
+}
+
+func TestVerifyRotatedKey(t *testing.T) {
+	fromKp := decodeKeyPair(fromPrivKey, fromPubKey)
+	toKp := decodeKeyPair(toPrivKey, toPubKey)
+	to, _ := hex.DecodeString(toPubKey)
+	toAddr := common.NewAddress(common.AddressVersionEd25519, to)
+
+	// `from` account rotated its key to `to` key.
+	rotated := state.NewStateDB()
+	acc, _ := rotated.GetAccount(fromKp.Address())
+	acc.SetKeys([][]byte{toKp.PublicKey})
+	assert.Nil(t, rotated.PutAccount(acc))
+
+	tx := createTx()
+	tx.Sign(fromKp)
+	assert.Nil(t, tx.VerifyIntegrity(accounts))
+	assert.Equal(t, errInvalidTransactionPublicKey, tx.VerifyIntegrity(rotated))
 
-	toKp := &account.KeyPairImpl{}
-	err = toKp.DecodePrivateKey(toPrivKey)
+	// the new key signs for the same address.
+	tx, err := NewTypedTransaction(TxTypeTransfer, nil, chainID, fromKp.Address(), toKp.PublicKey, toAddr, big.NewInt(20), big.NewInt(1), 2, time.Now().Unix(), nil)
 	assert.Nil(t, err)
-	err = toKp.DecodePublicKey(toPubKey)
+	tx.Sign(toKp)
+	assert.Equal(t, fromKp.Address().CloneBytes(), tx.From())
+	assert.Nil(t, tx.VerifyIntegrity(rotated))
+	assert.Equal(t, errInvalidTransactionPublicKey, tx.VerifyIntegrity(accounts))
+}
+
+func TestMarshalTx(t *testing.T) {
+	tx := createTx()
+	tx.Sign(decodeKeyPair(fromPrivKey, fromPubKey))
+
+	b, err := tx.Marshal()
+	assert.Nil(t, err)
+
+	newTx := &TxImpl{}
+	assert.Nil(t, newTx.Unmarshal(b))
+	assert.Equal(t, tx.pubKey, newTx.PublicKey())
+	assert.Nil(t, newTx.VerifyIntegrity(accounts))
+}
+
+func TestTxMemo(t *testing.T) {
+	from, _ := hex.DecodeString(fromPubKey)
+	to, _ := hex.DecodeString(toPubKey)
+	toAddr := common.NewAddress(common.AddressVersionEd25519, to)
+	timestamp := time.Now().Unix()
+
+	tx, err := NewTransaction(chainID, from, toAddr, big.NewInt(20), big.NewInt(1), 1, timestamp, []byte("memo"))
 	assert.Nil(t, err)
+	assert.Equal(t, []byte("memo"), tx.Memo())
+
+	// memo is covered by tx hash.
+	txWithoutMemo, _ := NewTransaction(chainID, from, toAddr, big.NewInt(20), big.NewInt(1), 1, timestamp, nil)
+	assert.NotEqual(t, txWithoutMemo.Hash(), tx.Hash())
+
+	_, err = NewTransaction(chainID, from, toAddr, big.NewInt(20), big.NewInt(1), 1, timestamp, make([]byte, MaxMemoLength+1))
+	assert.Equal(t, errTxMemoTooLong, err)
+}
+
+func TestTxSize(t *testing.T) {
+	tx := createTx()
+	tx.Sign(decodeKeyPair(fromPrivKey, fromPubKey))
+	assert.Nil(t, tx.VerifyIntegrity(accounts))
+
+	// oversized txs are rejected before they are decoded or verified.
+	assert.Equal(t, errTxTooLarge, (&TxImpl{}).Unmarshal(make([]byte, MaxTxSize+1)))
+
+	tx.payload = make([]byte, MaxTxSize)
+	assert.True(t, tx.Size() > MaxTxSize)
+	assert.Equal(t, errTxTooLarge, tx.VerifyIntegrity(accounts))
+}
+
+func TestTxSizeSignature(t *testing.T) {
+	kp := decodeKeyPair(fromPrivKey, fromPubKey)
+	tx := createTx()
+	size, weight := tx.Size(), tx.Weight()
+	tx.Sign(kp)
+	assert.Equal(t, size, tx.Size())
+	assert.Equal(t, weight, tx.Weight())
+
+	b, _ := tx.Marshal()
+	assert.Equal(t, uint64(len(b)), size)
 }
@@ -0,0 +1,106 @@
+package transaction
+
+import (
+	"errors"
+)
+
+const (
+	// ValidityTimeThreshold separates the two kinds of validity window bounds:
+	// bounds below it are block heights, others are unix timestamps.
+	ValidityTimeThreshold uint64 = 500000000
+)
+
+var (
+	errInvalidValidityWindow = errors.New("valid_after is later than valid_until")
+	errTxNotYetValid         = errors.New("transaction is not valid yet")
+	errTxExpired             = errors.New("transaction is expired")
+)
+
+// ValidAfter returns the bound before which the tx cannot be included, 0 if there is none.
+func (tx *TxImpl) ValidAfter() uint64 {
+	return tx.validAfter
+}
+
+// ValidUntil returns the bound after which the tx cannot be included, 0 if there is none.
+func (tx *TxImpl) ValidUntil() uint64 {
+	return tx.validUntil
+}
+
+// SetValidityWindow restricts the tx to blocks from validAfter to validUntil
+// inclusive, 0 means unbounded. The tx hash is recalculated, so it must be
+// called before signing.
+func (tx *TxImpl) SetValidityWindow(validAfter, validUntil uint64) error {
+	tx.validAfter, tx.validUntil = validAfter, validUntil
+	if err := tx.validateWindow(); err != nil {
+		return err
+	}
+
+	hash, err := tx.calcHash()
+	if err != nil {
+		return err
+	}
+	tx.hash = hash
+	tx.signature = nil
+	return nil
+}
+
+// validateWindow checks bounds of the same kind are in order.
+func (tx *TxImpl) validateWindow() error {
+	if tx.validAfter == 0 || tx.validUntil == 0 {
+		return nil
+	}
+	if isTimeBound(tx.validAfter) != isTimeBound(tx.validUntil) {
+		return nil
+	}
+	if tx.validAfter > tx.validUntil {
+		return errInvalidValidityWindow
+	}
+	return nil
+}
+
+// VerifyWindow checks whether the tx can be included in a block of the given height and time.
+func (tx *TxImpl) VerifyWindow(height uint64, timestamp int64) error {
+	if tx.validAfter != 0 && compareBound(tx.validAfter, height, timestamp) < 0 {
+		return errTxNotYetValid
+	}
+	if tx.Expired(height, timestamp) {
+		return errTxExpired
+	}
+	return nil
+}
+
+// Expired checks whether the validity window of the tx is closed at the given height and time.
+func (tx *TxImpl) Expired(height uint64, timestamp int64) bool {
+	return tx.validUntil != 0 && compareBound(tx.validUntil, height, timestamp) > 0
+}
+
+// BoundReached checks whether a block of the given height and time is at or
+// after bound, bound is interpreted like the validity window bounds.
+func BoundReached(bound, height uint64, timestamp int64) bool {
+	return compareBound(bound, height, timestamp) >= 0
+}
+
+func isTimeBound(bound uint64) bool {
+	return bound >= ValidityTimeThreshold
+}
+
+// compareBound compares the block height or time to bound, a < bound -> -1,
+// a == bound -> 0, a > bound -> 1.
+func compareBound(bound, height uint64, timestamp int64) int {
+	a := height
+	if isTimeBound(bound) {
+		if timestamp < 0 {
+			return -1
+		}
+		a = uint64(timestamp)
+	}
+
+	switch {
+	case a < bound:
+		return -1
+	case a > bound:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,51 @@
+package transaction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeightWindow(t *testing.T) {
+	tx := createTx()
+	hash := tx.Hash()
+
+	assert.Nil(t, tx.SetValidityWindow(10, 20))
+	assert.NotEqual(t, hash, tx.Hash())
+	tx.Sign(decodeKeyPair(fromPrivKey, fromPubKey))
+	assert.Nil(t, tx.VerifyIntegrity(accounts))
+
+	assert.Equal(t, errTxNotYetValid, tx.VerifyWindow(9, 0))
+	assert.Nil(t, tx.VerifyWindow(10, 0))
+	assert.Nil(t, tx.VerifyWindow(20, 0))
+	assert.Equal(t, errTxExpired, tx.VerifyWindow(21, 0))
+	assert.False(t, tx.Expired(20, 0))
+	assert.True(t, tx.Expired(21, 0))
+
+	// the window is covered by tx hash.
+	b, err := tx.Marshal()
+	assert.Nil(t, err)
+	newTx := &TxImpl{}
+	assert.Nil(t, newTx.Unmarshal(b))
+	assert.Equal(t, uint64(10), newTx.ValidAfter())
+	assert.Equal(t, uint64(20), newTx.ValidUntil())
+	newTx.validUntil = 30
+	assert.Equal(t, errInvalidTransacionHash, newTx.VerifyIntegrity(accounts))
+
+	assert.Equal(t, errInvalidValidityWindow, tx.SetValidityWindow(20, 10))
+}
+
+func TestTimeWindow(t *testing.T) {
+	tx := createTx()
+	validUntil := ValidityTimeThreshold + 1000
+
+	// a height lower bound with a time upper bound.
+	assert.Nil(t, tx.SetValidityWindow(5, validUntil))
+	assert.Equal(t, errTxNotYetValid, tx.VerifyWindow(4, int64(validUntil)))
+	assert.Nil(t, tx.VerifyWindow(5, int64(validUntil)))
+	assert.Equal(t, errTxExpired, tx.VerifyWindow(5, int64(validUntil)+1))
+
+	// no window at all.
+	assert.Nil(t, tx.SetValidityWindow(0, 0))
+	assert.Nil(t, tx.VerifyWindow(0, -1))
+}
@@ -0,0 +1,77 @@
+package txpool
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/ldmtam/tam-chain/abstraction"
+	"github.com/ldmtam/tam-chain/core/block"
+)
+
+const (
+	// feeHistoryBlocks is the number of recent blocks whose tips are used to estimate fees.
+	feeHistoryBlocks = 20
+	// tipPercentile of tips in recent blocks is suggested.
+	tipPercentile = 60
+	// defaultTxWeight is the weight of a plain signed transfer.
+	defaultTxWeight = 200
+)
+
+// tipPerWeight returns the tip per weight unit paid by tx in a block of baseFee.
+func tipPerWeight(tx abstraction.Transaction, baseFee *big.Int) *big.Int {
+	tip := tx.EffectiveTip(baseFee)
+	if tip.Sign() < 0 {
+		return new(big.Int)
+	}
+	return tip.Div(tip, new(big.Int).SetUint64(tx.Weight()))
+}
+
+// EstimateFee suggests the tip and max fee of a tx of weight, a plain
+// transfer is assumed if weight is 0.
+//
+// The tip per weight unit is a percentile of tips paid in recent blocks. If
+// the pool holds more txs than the next block can take at the target weight,
+// the suggested tip outbids the last tx which fits. The max fee leaves room
+// for the base fee to double.
+func (pool *TxPImpl) EstimateFee(weight uint64) *abstraction.FeeEstimate {
+	if weight == 0 {
+		weight = defaultTxWeight
+	}
+
+	pool.headMu.RLock()
+	baseFee := pool.baseFee
+	var tips []*big.Int
+	for _, blockTips := range pool.feeHistory {
+		tips = append(tips, blockTips...)
+	}
+	pool.headMu.RUnlock()
+
+	tip := new(big.Int)
+	if len(tips) > 0 {
+		sort.Slice(tips, func(i, j int) bool { return tips[i].Cmp(tips[j]) < 0 })
+		tip.Set(tips[(len(tips)-1)*tipPercentile/100])
+	}
+
+	var pending uint64
+	for _, tx := range pool.fee.Slice() {
+		pending += tx.Weight()
+		if pending > block.TargetBlockWeight {
+			marginal := tipPerWeight(tx, baseFee)
+			if marginal.Cmp(tip) >= 0 {
+				tip.Add(marginal, big.NewInt(1))
+			}
+			break
+		}
+	}
+
+	w := new(big.Int).SetUint64(weight)
+	maxFee := new(big.Int).Mul(baseFee, big.NewInt(2))
+	maxFee.Add(maxFee, tip)
+
+	return &abstraction.FeeEstimate{
+		Weight:  weight,
+		BaseFee: baseFee,
+		Tip:     new(big.Int).Mul(tip, w),
+		MaxFee:  maxFee.Mul(maxFee, w),
+	}
+}
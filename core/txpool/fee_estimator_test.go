@@ -0,0 +1,108 @@
+package txpool
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ldmtam/tam-chain/abstraction"
+	"github.com/ldmtam/tam-chain/account"
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/block"
+	"github.com/ldmtam/tam-chain/core/state"
+	"github.com/ldmtam/tam-chain/core/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTippedTx(t *testing.T, from *account.KeyPairImpl, nonce uint64, fee, tip int64) *transaction.TxImpl {
+	to, _ := account.NewKeyPair()
+	tx, err := transaction.NewTransaction(common.MainnetConfig.ChainID, from.PublicKey, to.Address(), big.NewInt(20), big.NewInt(fee), nonce, time.Now().Unix(), nil)
+	assert.Nil(t, err)
+	assert.Nil(t, tx.SetTip(big.NewInt(tip)))
+	tx.Sign(from)
+	return tx
+}
+
+func TestBaseFee(t *testing.T) {
+	pool := NewTxPImpl(common.MainnetConfig, state.NewStateDB())
+	pool.Start()
+	defer pool.Stop()
+
+	from, _ := account.NewKeyPair()
+	assert.Equal(t, errTxFeeTooLow, pool.AddTx(createTippedTx(t, from, 1, 1000, 0), true))
+
+	low := createTippedTx(t, from, 1, txFee, 1000)
+	high := createTippedTx(t, from, 2, 2*txFee, 2000)
+	assert.Nil(t, pool.AddTx(low, true))
+	assert.Nil(t, pool.AddTx(high, true))
+	assert.Equal(t, []common.Hash{high.Hash(), low.Hash()}, txHashes(pool.fee.Slice()))
+
+	// high base fee cuts the tip of `low` by its max fee.
+	baseFee := new(big.Int).SetUint64(txFee / low.Weight())
+	pool.fee.SetBaseFee(baseFee)
+	assert.True(t, low.EffectiveTip(baseFee).Cmp(low.Tip()) < 0)
+	assert.Equal(t, []common.Hash{high.Hash(), low.Hash()}, txHashes(pool.fee.Slice()))
+
+	// included txs are removed.
+	head := newHead(1)
+	head.Txs = []*transaction.TxImpl{high}
+	pool.SetHead(head)
+	assert.Equal(t, 1, pool.all.Count())
+	assert.Equal(t, []common.Hash{low.Hash()}, txHashes(pool.fee.Slice()))
+}
+
+func TestEstimateFee(t *testing.T) {
+	pool := NewTxPImpl(common.MainnetConfig, state.NewStateDB())
+
+	// no history, the tip is zero.
+	estimate := pool.EstimateFee(0)
+	assert.Equal(t, uint64(defaultTxWeight), estimate.Weight)
+	assert.Equal(t, block.InitialBaseFee, estimate.BaseFee)
+	assert.Equal(t, int64(0), estimate.Tip.Int64())
+	assert.Equal(t, int64(2*10*defaultTxWeight), estimate.MaxFee.Int64())
+
+	// tips of recent blocks.
+	from, _ := account.NewKeyPair()
+	head := newHead(1)
+	for i := 0; i < 10; i++ {
+		head.Txs = append(head.Txs, createTippedTx(t, from, uint64(i+1), txFee, int64(i+1)*1000))
+	}
+	pool.SetHead(head)
+
+	// 60th percentile of tips per weight unit.
+	tip := tipPerWeight(head.Txs[5], block.InitialBaseFee)
+	assert.True(t, tip.Sign() > 0)
+
+	estimate = pool.EstimateFee(1000)
+	assert.Equal(t, tip.Int64()*1000, estimate.Tip.Int64())
+	assert.Equal(t, (2*10+tip.Int64())*1000, estimate.MaxFee.Int64())
+}
+
+func TestEstimateFeeCongested(t *testing.T) {
+	pool := NewTxPImpl(common.MainnetConfig, state.NewStateDB())
+	pool.Start()
+	defer pool.Stop()
+
+	// pending txs do not fit into a block of target weight.
+	from, _ := account.NewKeyPair()
+	var weight uint64
+	var tx *transaction.TxImpl
+	for nonce := uint64(1); weight <= block.TargetBlockWeight; nonce++ {
+		tx = createTippedTx(t, from, nonce, txFee, 1000)
+		weight += tx.Weight()
+		pool.fee.Push(tx)
+	}
+
+	// outbid the txs which do not fit.
+	tip := tipPerWeight(tx, block.InitialBaseFee)
+	estimate := pool.EstimateFee(defaultTxWeight)
+	assert.Equal(t, (tip.Int64()+1)*defaultTxWeight, estimate.Tip.Int64())
+}
+
+func txHashes(txs []abstraction.Transaction) []common.Hash {
+	hashes := make([]common.Hash, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.Hash()
+	}
+	return hashes
+}
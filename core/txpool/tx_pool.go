@@ -1,75 +1,265 @@
 package txpool
 
 import (
+	"errors"
+	"math/big"
 	"sync"
+	"time"
 
 	log "github.com/inconshreveable/log15"
 	"github.com/ldmtam/tam-chain/abstraction"
 	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/block"
+	"github.com/ldmtam/tam-chain/core/transaction"
+)
+
+// errors
+var (
+	ErrTxAlreadyKnown    = errors.New("transaction is already in tx pool")
+	ErrTxChainIDMismatch = errors.New("transaction chain id does not match")
+)
+
+var (
+	errTxPoolStopped    = errors.New("tx pool is stopped")
+	errTxPoolNotStarted = errors.New("tx pool is not started")
+
+	errTxFeeTooLow        = errors.New("transaction fee does not cover base fee of the next block")
+	errTxTooLarge         = errors.New("transaction exceeds the maximum size")
+	errTxFeeBelowMinimum  = errors.New("transaction fee is below the minimum fee of its size")
+	errTxInvalidSignature = errors.New("transaction signature is invalid")
+)
+
+const (
+	pruneInterval = 10 * time.Second
+
+	// minFeePerByte is the minimum max fee per byte of encoded tx, so large
+	// txs cannot be relayed for the price of small ones even when the base
+	// fee is at its lower bound.
+	minFeePerByte = 2
 )
 
 // TxPImpl ...
 type TxPImpl struct {
 	all    *txLookup // All transaction to look up
-	fee    *sortedTx // All transaction sorted by fee
+	fee    *sortedTx // All transaction sorted by tip
 	locals map[common.Hash]abstraction.Transaction
 
+	// chain head, txs are verified against the next block.
+	headHeight uint64
+	baseFee    *big.Int     // base fee of the next block
+	feeHistory [][]*big.Int // tips per weight unit of txs in recent blocks
+	headMu     sync.RWMutex
+
+	config   *common.ChainConfig
+	accounts abstraction.AccountReader
+	verifier *txVerifier
+
 	mu     sync.RWMutex
 	quitCh chan struct{}
 }
 
-// NewTxPImpl returns a new TxPImpl instance.
-func NewTxPImpl() *TxPImpl {
-	return &TxPImpl{
-		all:    newTxLookup(),
-		fee:    newSortedTx(),
-		locals: make(map[common.Hash]abstraction.Transaction),
-		quitCh: make(chan struct{}),
+// NewTxPImpl returns a new TxPImpl instance, signing keys of txs are checked against accounts.
+func NewTxPImpl(config *common.ChainConfig, accounts abstraction.AccountReader) *TxPImpl {
+	pool := &TxPImpl{
+		all:      newTxLookup(),
+		fee:      newSortedTx(block.InitialBaseFee),
+		locals:   make(map[common.Hash]abstraction.Transaction),
+		baseFee:  block.InitialBaseFee,
+		config:   config,
+		accounts: accounts,
+		quitCh:   make(chan struct{}),
 	}
+	pool.verifier = newTxVerifier(pool)
+	return pool
 }
 
 // Start starts the tx pool.
 func (pool *TxPImpl) Start() {
+	pool.verifier.start()
 	go pool.loop()
 }
 
 // Stop stops the tx pool.
 func (pool *TxPImpl) Stop() {
 	log.Info("Tx pool stop")
+	pool.verifier.stop()
 	close(pool.quitCh)
 }
 
 func (pool *TxPImpl) loop() {
+	pruneTicker := time.NewTicker(pruneInterval)
+	defer pruneTicker.Stop()
+
 	for {
 		select {
 		case <-pool.quitCh:
 			return
+		case <-pruneTicker.C:
+			pool.prune()
+		}
+	}
+}
+
+// SetHead updates the chain head, txs included in the head and txs which
+// cannot be included in the next block anymore are removed.
+func (pool *TxPImpl) SetHead(head *block.Block) {
+	baseFee := block.CalcBaseFee(head.Header)
+	tips := make([]*big.Int, len(head.Txs))
+	for i, tx := range head.Txs {
+		tips[i] = tipPerWeight(tx, head.Header.BaseFee)
+	}
+
+	// the head is moved under pool.mu, so txs are never added against a stale head.
+	pool.mu.Lock()
+	pool.headMu.Lock()
+	pool.headHeight = head.Header.Height
+	pool.baseFee = baseFee
+	pool.feeHistory = append(pool.feeHistory, tips)
+	if len(pool.feeHistory) > feeHistoryBlocks {
+		pool.feeHistory = pool.feeHistory[1:]
+	}
+	pool.headMu.Unlock()
+
+	for _, tx := range head.Txs {
+		pool.removeTxLocked(tx.Hash())
+	}
+	pool.fee.SetBaseFee(baseFee)
+	pool.mu.Unlock()
+
+	pool.prune()
+}
+
+// nextBlock returns height and time of the next block.
+func (pool *TxPImpl) nextBlock() (uint64, int64) {
+	pool.headMu.RLock()
+	defer pool.headMu.RUnlock()
+
+	return pool.headHeight + 1, time.Now().Unix()
+}
+
+// nextBaseFee returns base fee of the next block.
+func (pool *TxPImpl) nextBaseFee() *big.Int {
+	pool.headMu.RLock()
+	defer pool.headMu.RUnlock()
+
+	return pool.baseFee
+}
+
+// prune removes txs whose validity window is closed.
+func (pool *TxPImpl) prune() {
+	height, timestamp := pool.nextBlock()
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	for _, tx := range pool.all.Slice() {
+		if tx.Expired(height, timestamp) {
+			hash := tx.Hash()
+			log.Debug("Prune expired tx", "hash", hash.String())
+			pool.removeTxLocked(hash)
 		}
 	}
 }
 
-// verifyTx verifies tx before adding it to tx pool.
+// verifyTx verifies the parts of tx which do not depend on the chain head.
+func (pool *TxPImpl) verifyTx(tx abstraction.Transaction) error {
+	if err := pool.verifyTxData(tx); err != nil {
+		return err
+	}
+	if !tx.Verify(tx.PublicKey()) {
+		return errTxInvalidSignature
+	}
+	return nil
+}
+
+// verifyTxData verifies the parts of tx which do not depend on the chain head
+// except the signature, it runs in the verifier workers without holding pool.mu.
 //
-// [DONE] step 1: check whether the signature belongs to `from` address or not.
+// [DONE] step 0: check whether the tx belongs to our chain or not, so txs of other networks cannot be replayed.
+// [DONE] step 1: check whether the encoded tx is within the maximum size and pays the minimum fee of its size or not.
 // [DONE] step 2: recalculate the tx hash and check if it matches with the tx hash sent by user.
-// [TODO] step 3: check whether tx nonce = `from` nonce + 1 or not
-// [TODO] step 4: check whether `from` balance is greater than or equal to (tx value + tx fee) or not.
-func (pool *TxPImpl) verifyTx(tx abstraction.Transaction) error {
-	// step 1 & 2.
-	if err := tx.VerifyIntegrity(); err != nil {
+// [DONE] step 3: check whether the signing key is authorized by `from` account or not.
+func (pool *TxPImpl) verifyTxData(tx abstraction.Transaction) error {
+	// step 0.
+	if tx.ChainID() != pool.config.ChainID {
+		return ErrTxChainIDMismatch
+	}
+
+	// step 1.
+	size := tx.Size()
+	if size > transaction.MaxTxSize {
+		return errTxTooLarge
+	}
+	minFee := new(big.Int).SetUint64(size)
+	minFee.Mul(minFee, big.NewInt(minFeePerByte))
+	if tx.Fee().Cmp(minFee) < 0 {
+		return errTxFeeBelowMinimum
+	}
+
+	// step 2.
+	if err := tx.VerifyData(); err != nil {
 		return err
 	}
 
+	// step 3.
+	return tx.VerifyPublicKey(pool.accounts)
+}
+
+// verifyTxHeadLocked verifies tx against the next block, pool.mu must be held
+// so the head cannot move between the check and adding the tx.
+//
+// [DONE] step 0: check whether the tx can be included in the next block according to its validity window or not.
+// [DONE] step 1: check whether tx fee covers the base fee of the next block or not.
+// [TODO] step 2: check whether tx nonce = `from` nonce + 1 or not
+// [TODO] step 3: check whether `from` balance is greater than or equal to (tx value + tx fee) or not.
+func (pool *TxPImpl) verifyTxHeadLocked(tx abstraction.Transaction) error {
+	// step 0.
+	if err := tx.VerifyWindow(pool.nextBlock()); err != nil {
+		return err
+	}
+
+	// step 1.
+	if tx.EffectiveTip(pool.nextBaseFee()).Sign() < 0 {
+		return errTxFeeTooLow
+	}
+
 	return nil
 }
 
-// AddTx add transaction to tx pool.
+// VerifyTxHead checks whether tx can be included in the next block or not,
+// without changing the tx pool.
+func (pool *TxPImpl) VerifyTxHead(tx abstraction.Transaction) error {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return pool.verifyTxHeadLocked(tx)
+}
+
+// AddTx add transaction to tx pool. The tx is verified in parallel with other
+// incoming txs, AddTx blocks until the tx is verified.
 func (pool *TxPImpl) AddTx(tx abstraction.Transaction, local bool) error {
-	pool.mu.Lock()
-	defer pool.mu.Unlock()
+	return pool.verifier.wait(pool.verifier.submit([]abstraction.Transaction{tx}, local)[0])
+}
+
+// AddTxs add a batch of transactions to tx pool, e.g. txs received from network.
+// It returns the result of each tx.
+func (pool *TxPImpl) AddTxs(txs []abstraction.Transaction, local bool) []error {
+	results := pool.verifier.submit(txs, local)
+
+	errs := make([]error, len(txs))
+	for i, result := range results {
+		errs[i] = pool.verifier.wait(result)
+	}
+	return errs
+}
 
-	err := pool.verifyTx(tx)
-	if err != nil {
+// addTxLocked verifies tx against the next block and adds it to tx pool,
+// verifyTx must have passed and pool.mu must be held.
+func (pool *TxPImpl) addTxLocked(tx abstraction.Transaction, local bool) error {
+	if pool.all.Get(tx.Hash()) != nil {
+		return ErrTxAlreadyKnown
+	}
+	if err := pool.verifyTxHeadLocked(tx); err != nil {
 		return err
 	}
 
@@ -84,6 +274,19 @@ func (pool *TxPImpl) AddTx(tx abstraction.Transaction, local bool) error {
 
 // DelTx remove a tx from the tx pool.
 func (pool *TxPImpl) DelTx(hash common.Hash) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.removeTxLocked(hash)
+}
+
+// removeTxLocked removes a tx from tx pool, pool.mu must be held.
+func (pool *TxPImpl) removeTxLocked(hash common.Hash) {
+	tx := pool.all.Get(hash)
+	if tx == nil {
+		return
+	}
 	pool.all.Remove(hash)
-	pool.fee.Delete(pool.all.Get(hash))
+	pool.fee.Delete(tx)
+	delete(pool.locals, hash)
 }
@@ -0,0 +1,264 @@
+package txpool
+
+import (
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ldmtam/tam-chain/abstraction"
+	"github.com/ldmtam/tam-chain/account"
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/block"
+	"github.com/ldmtam/tam-chain/core/state"
+	"github.com/ldmtam/tam-chain/core/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	// txFee covers the base fee of a plain transfer.
+	txFee = 10000
+)
+
+// newHead returns an empty block which keeps the base fee unchanged.
+func newHead(height uint64) *block.Block {
+	return &block.Block{
+		Header: &block.Header{
+			Height:  height,
+			BaseFee: block.InitialBaseFee,
+			Weight:  block.TargetBlockWeight,
+		},
+	}
+}
+
+func createSignedTxs(t testing.TB, n int) []abstraction.Transaction {
+	return createChainSignedTxs(t, common.MainnetConfig.ChainID, n)
+}
+
+func createChainSignedTxs(t testing.TB, chainID uint32, n int) []abstraction.Transaction {
+	from, err := account.NewKeyPair()
+	assert.Nil(t, err)
+	to, err := account.NewKeyPair()
+	assert.Nil(t, err)
+
+	timestamp := time.Now().Unix()
+	txs := make([]abstraction.Transaction, n)
+	for i := range txs {
+		tx, err := transaction.NewTransaction(chainID, from.PublicKey, to.Address(), big.NewInt(20), big.NewInt(int64(txFee+i)), uint64(i+1), timestamp, nil)
+		assert.Nil(t, err)
+		tx.Sign(from)
+		txs[i] = tx
+	}
+	return txs
+}
+
+func TestAddTx(t *testing.T) {
+	pool := NewTxPImpl(common.MainnetConfig, state.NewStateDB())
+	pool.Start()
+	defer pool.Stop()
+
+	txs := createSignedTxs(t, 2)
+
+	assert.Nil(t, pool.AddTx(txs[0], true))
+	assert.Equal(t, ErrTxAlreadyKnown, pool.AddTx(txs[0], true))
+	assert.Equal(t, 1, pool.all.Count())
+	assert.Equal(t, 1, pool.fee.Len())
+
+	// signed by another key.
+	other, _ := account.NewKeyPair()
+	txs[1].Sign(other)
+	assert.NotNil(t, pool.AddTx(txs[1], true))
+	assert.Equal(t, 1, pool.all.Count())
+}
+
+func TestAddTxRotatedKey(t *testing.T) {
+	accounts := state.NewStateDB()
+	pool := NewTxPImpl(common.MainnetConfig, accounts)
+	pool.Start()
+	defer pool.Stop()
+
+	from, _ := account.NewKeyPair()
+	newKey, _ := account.NewKeyPair()
+	to, _ := account.NewKeyPair()
+
+	acc, _ := accounts.GetAccount(from.Address())
+	acc.SetKeys([][]byte{newKey.PublicKey})
+	assert.Nil(t, accounts.PutAccount(acc))
+
+	tx, err := transaction.NewTransaction(common.MainnetConfig.ChainID, from.PublicKey, to.Address(), big.NewInt(20), big.NewInt(txFee), 1, time.Now().Unix(), nil)
+	assert.Nil(t, err)
+	tx.Sign(from)
+	assert.NotNil(t, pool.AddTx(tx, true))
+
+	tx, err = transaction.NewTypedTransaction(transaction.TxTypeTransfer, nil, common.MainnetConfig.ChainID, from.Address(), newKey.PublicKey, to.Address(), big.NewInt(20), big.NewInt(txFee), 1, time.Now().Unix(), nil)
+	assert.Nil(t, err)
+	tx.Sign(newKey)
+	assert.Nil(t, pool.AddTx(tx, true))
+}
+
+func TestValidityWindow(t *testing.T) {
+	pool := NewTxPImpl(common.MainnetConfig, state.NewStateDB())
+	pool.Start()
+	defer pool.Stop()
+
+	from, _ := account.NewKeyPair()
+	to, _ := account.NewKeyPair()
+	now := time.Now().Unix()
+
+	newTx := func(nonce, validAfter, validUntil uint64) abstraction.Transaction {
+		tx, err := transaction.NewTransaction(common.MainnetConfig.ChainID, from.PublicKey, to.Address(), big.NewInt(20), big.NewInt(txFee), nonce, now, nil)
+		assert.Nil(t, err)
+		assert.Nil(t, tx.SetValidityWindow(validAfter, validUntil))
+		tx.Sign(from)
+		return tx
+	}
+
+	// the next block is at height 1.
+	assert.Nil(t, pool.AddTx(newTx(1, 0, 5), true))
+	assert.Nil(t, pool.AddTx(newTx(2, 1, 0), true))
+	assert.NotNil(t, pool.AddTx(newTx(3, 3, 0), true))
+	assert.NotNil(t, pool.AddTx(newTx(4, 0, uint64(now)-1), true))
+	assert.Equal(t, 2, pool.all.Count())
+	assert.Nil(t, pool.VerifyTxHead(newTx(5, 1, 0)))
+	assert.NotNil(t, pool.VerifyTxHead(newTx(6, 3, 0)))
+	assert.Equal(t, 2, pool.all.Count())
+
+	// the window of the first tx is closed at height 6.
+	pool.SetHead(newHead(4))
+	assert.Equal(t, 2, pool.all.Count())
+	pool.SetHead(newHead(5))
+	assert.Equal(t, 1, pool.all.Count())
+	assert.Equal(t, 1, pool.fee.Len())
+}
+
+func TestAddTxHeadMoved(t *testing.T) {
+	pool := NewTxPImpl(common.MainnetConfig, state.NewStateDB())
+
+	from, _ := account.NewKeyPair()
+	to, _ := account.NewKeyPair()
+	tx, err := transaction.NewTransaction(common.MainnetConfig.ChainID, from.PublicKey, to.Address(), big.NewInt(20), big.NewInt(txFee), 1, time.Now().Unix(), nil)
+	assert.Nil(t, err)
+	assert.Nil(t, tx.SetValidityWindow(0, 1))
+	tx.Sign(from)
+
+	// verified by a worker against head 0, the head moves before the tx is added.
+	assert.Nil(t, pool.verifyTx(tx))
+	assert.Nil(t, pool.verifyTxHeadLocked(tx))
+	pool.SetHead(newHead(1))
+
+	pool.mu.Lock()
+	assert.NotNil(t, pool.addTxLocked(tx, true))
+	pool.mu.Unlock()
+	assert.Equal(t, 0, pool.all.Count())
+}
+
+func TestAddTxs(t *testing.T) {
+	pool := NewTxPImpl(common.MainnetConfig, state.NewStateDB())
+	pool.Start()
+	defer pool.Stop()
+
+	txs := createSignedTxs(t, 3*maxVerifyBatchSize)
+	other, _ := account.NewKeyPair()
+	txs[10].Sign(other)
+
+	errs := pool.AddTxs(txs, false)
+	for i, err := range errs {
+		if i == 10 {
+			assert.Equal(t, errTxInvalidSignature, err)
+		} else {
+			assert.Nil(t, err)
+		}
+	}
+	assert.Equal(t, len(txs)-1, pool.all.Count())
+	assert.Equal(t, 0, len(pool.locals))
+}
+
+func TestAddTxChainID(t *testing.T) {
+	pool := NewTxPImpl(common.MainnetConfig, state.NewStateDB())
+	pool.Start()
+	defer pool.Stop()
+
+	txs := createChainSignedTxs(t, common.TestnetConfig.ChainID, 1)
+	assert.Equal(t, ErrTxChainIDMismatch, pool.AddTx(txs[0], true))
+	assert.Equal(t, 0, pool.all.Count())
+}
+
+func TestAddTxMinFee(t *testing.T) {
+	pool := NewTxPImpl(common.MainnetConfig, state.NewStateDB())
+	pool.Start()
+	defer pool.Stop()
+
+	// base fee of the next block is at its lower bound.
+	head := newHead(1)
+	head.Header.BaseFee = block.MinBaseFee
+	pool.SetHead(head)
+
+	from, _ := account.NewKeyPair()
+	to, _ := account.NewKeyPair()
+
+	// the fee covers the base fee, but not the minimum fee of the tx size.
+	tx, err := transaction.NewTransaction(common.MainnetConfig.ChainID, from.PublicKey, to.Address(), big.NewInt(20), big.NewInt(300), 1, time.Now().Unix(), nil)
+	assert.Nil(t, err)
+	tx.Sign(from)
+	assert.True(t, tx.Size() < 300 && tx.Size()*minFeePerByte > 300)
+	assert.Equal(t, errTxFeeBelowMinimum, pool.AddTx(tx, true))
+
+	tx, err = transaction.NewTransaction(common.MainnetConfig.ChainID, from.PublicKey, to.Address(), big.NewInt(20), big.NewInt(1000), 1, time.Now().Unix(), nil)
+	assert.Nil(t, err)
+	tx.Sign(from)
+	assert.Nil(t, pool.AddTx(tx, true))
+}
+
+func TestAddTxStopped(t *testing.T) {
+	pool := NewTxPImpl(common.MainnetConfig, state.NewStateDB())
+	pool.Start()
+	pool.Stop()
+
+	txs := createSignedTxs(t, 1)
+	assert.Equal(t, errTxPoolStopped, pool.AddTx(txs[0], true))
+}
+
+func TestAddTxNotStarted(t *testing.T) {
+	pool := NewTxPImpl(common.MainnetConfig, state.NewStateDB())
+
+	txs := createSignedTxs(t, 2)
+	assert.Equal(t, errTxPoolNotStarted, pool.AddTx(txs[0], true))
+	assert.Equal(t, []error{errTxPoolNotStarted, errTxPoolNotStarted}, pool.AddTxs(txs, false))
+	assert.Equal(t, 0, pool.all.Count())
+}
+
+// BenchmarkAddTxSerial measures the former path: every tx is verified under the tx pool lock.
+func BenchmarkAddTxSerial(b *testing.B) {
+	pool := NewTxPImpl(common.MainnetConfig, state.NewStateDB())
+	txs := createSignedTxs(b, b.N)
+
+	var next int64 = -1
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			tx := txs[atomic.AddInt64(&next, 1)]
+
+			pool.mu.Lock()
+			if err := pool.verifyTx(tx); err == nil {
+				pool.addTxLocked(tx, false)
+			}
+			pool.mu.Unlock()
+		}
+	})
+}
+
+// BenchmarkAddTxBatch measures txs verified in batches by the verifier workers.
+func BenchmarkAddTxBatch(b *testing.B) {
+	pool := NewTxPImpl(common.MainnetConfig, state.NewStateDB())
+	pool.Start()
+	defer pool.Stop()
+	txs := createSignedTxs(b, b.N)
+
+	var next int64 = -1
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			pool.AddTx(txs[atomic.AddInt64(&next, 1)], false)
+		}
+	})
+}
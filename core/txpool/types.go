@@ -1,6 +1,7 @@
 package txpool
 
 import (
+	"math/big"
 	"sync"
 
 	"github.com/ldmtam/tam-chain/common/sorted"
@@ -35,6 +36,17 @@ func (t *txLookup) Count() int {
 	return len(t.all)
 }
 
+func (t *txLookup) Slice() []abstraction.Transaction {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	txs := make([]abstraction.Transaction, 0, len(t.all))
+	for _, tx := range t.all {
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
 func (t *txLookup) Add(tx abstraction.Transaction) {
 	t.lock.Lock()
 	defer t.lock.Unlock()
@@ -51,26 +63,59 @@ func (t *txLookup) Remove(hash common.Hash) {
 
 type sortedTx struct {
 	txsByFee *sorted.Slice
+	baseFee  *big.Int
 	mu       sync.RWMutex
 }
 
-func feeCmp(a, b interface{}) int {
+// NewSortedTx returns new instance of sorted tx, txs are sorted by their tip
+// per weight unit in a block of baseFee.
+func newSortedTx(baseFee *big.Int) *sortedTx {
+	s := &sortedTx{
+		baseFee: baseFee,
+	}
+	s.txsByFee = sorted.NewSlice(s.tipCmp)
+	return s
+}
+
+func (s *sortedTx) tipCmp(a, b interface{}) int {
 	txa := a.(abstraction.Transaction)
 	txb := b.(abstraction.Transaction)
-	if txa.Fee().Cmp(txb.Fee()) == 0 {
+
+	// tip a / weight a vs tip b / weight b
+	x := new(big.Int).Mul(txa.EffectiveTip(s.baseFee), new(big.Int).SetUint64(txb.Weight()))
+	y := new(big.Int).Mul(txb.EffectiveTip(s.baseFee), new(big.Int).SetUint64(txa.Weight()))
+	if x.Cmp(y) == 0 {
 		if txa.Nonce() <= txb.Nonce() {
 			return 1
 		}
 		return -1
 	}
-	return txa.Fee().Cmp(txb.Fee())
+	return x.Cmp(y)
 }
 
-// NewSortedTx returns new instance of sorted tx
-func newSortedTx() *sortedTx {
-	return &sortedTx{
-		txsByFee: sorted.NewSlice(feeCmp),
+// SetBaseFee re-sorts txs for a new base fee.
+func (s *sortedTx) SetBaseFee(baseFee *big.Int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	txs := s.txsByFee
+	s.baseFee = baseFee
+	s.txsByFee = sorted.NewSlice(s.tipCmp)
+	for i := 0; i < txs.Len(); i++ {
+		s.txsByFee.Push(txs.Index(i))
+	}
+}
+
+// Slice returns txs from the highest tip to the lowest.
+func (s *sortedTx) Slice() []abstraction.Transaction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	txs := make([]abstraction.Transaction, s.txsByFee.Len())
+	for i := range txs {
+		txs[i] = s.txsByFee.Index(len(txs) - 1 - i).(abstraction.Transaction)
 	}
+	return txs
 }
 
 func (s *sortedTx) Push(tx abstraction.Transaction) {
@@ -0,0 +1,182 @@
+package txpool
+
+import (
+	"runtime"
+
+	"github.com/ldmtam/tam-chain/abstraction"
+	"github.com/ldmtam/tam-chain/crypto/ed25519batch"
+)
+
+const (
+	verifyQueueSize    = 4096
+	maxVerifyBatchSize = 64
+)
+
+// verifyRequest is a tx waiting for verification.
+type verifyRequest struct {
+	tx     abstraction.Transaction
+	local  bool
+	result chan error
+}
+
+// txVerifier verifies incoming txs in batches before they are added to the tx pool.
+//
+// Queued txs are collected into batches, the workers verify the signatures of
+// a batch at once without holding the tx pool lock and fall back to verifying
+// them one by one if the batch fails. Valid txs of a batch are then checked
+// against the chain head and added under one lock.
+type txVerifier struct {
+	pool    *TxPImpl
+	workers int
+
+	requestCh chan *verifyRequest
+	batchCh   chan []*verifyRequest
+	startCh   chan struct{}
+	quitCh    chan struct{}
+}
+
+func newTxVerifier(pool *TxPImpl) *txVerifier {
+	return &txVerifier{
+		pool:      pool,
+		workers:   runtime.NumCPU(),
+		requestCh: make(chan *verifyRequest, verifyQueueSize),
+		batchCh:   make(chan []*verifyRequest, runtime.NumCPU()),
+		startCh:   make(chan struct{}),
+		quitCh:    make(chan struct{}),
+	}
+}
+
+func (v *txVerifier) start() {
+	close(v.startCh)
+	go v.batchLoop()
+	for i := 0; i < v.workers; i++ {
+		go v.workerLoop()
+	}
+}
+
+func (v *txVerifier) stop() {
+	close(v.quitCh)
+}
+
+// submit puts txs into verification queue, results are sent to returned channels.
+func (v *txVerifier) submit(txs []abstraction.Transaction, local bool) []chan error {
+	results := make([]chan error, len(txs))
+	for i, tx := range txs {
+		results[i] = make(chan error, 1)
+		select {
+		case <-v.startCh:
+		default:
+			// nothing would ever take the request off the queue.
+			results[i] <- errTxPoolNotStarted
+			continue
+		}
+		select {
+		case v.requestCh <- &verifyRequest{tx: tx, local: local, result: results[i]}:
+		case <-v.quitCh:
+			results[i] <- errTxPoolStopped
+		}
+	}
+	return results
+}
+
+// wait waits for the verification result.
+func (v *txVerifier) wait(result chan error) error {
+	select {
+	case err := <-result:
+		return err
+	case <-v.quitCh:
+		return errTxPoolStopped
+	}
+}
+
+// batchLoop groups queued requests into batches. It never waits for a batch
+// to be filled up, so a single tx is verified as soon as a worker is free.
+func (v *txVerifier) batchLoop() {
+	for {
+		select {
+		case <-v.quitCh:
+			return
+		case req := <-v.requestCh:
+			batch := []*verifyRequest{req}
+			for done := false; !done && len(batch) < maxVerifyBatchSize; {
+				select {
+				case req := <-v.requestCh:
+					batch = append(batch, req)
+				default:
+					done = true
+				}
+			}
+			select {
+			case v.batchCh <- batch:
+			case <-v.quitCh:
+				return
+			}
+		}
+	}
+}
+
+func (v *txVerifier) workerLoop() {
+	for {
+		select {
+		case <-v.quitCh:
+			return
+		case batch := <-v.batchCh:
+			v.verifyBatch(batch)
+		}
+	}
+}
+
+func (v *txVerifier) verifyBatch(batch []*verifyRequest) {
+	verified := make([]*verifyRequest, 0, len(batch))
+	for _, req := range batch {
+		if v.pool.all.Get(req.tx.Hash()) != nil {
+			req.result <- ErrTxAlreadyKnown
+			continue
+		}
+		if err := v.pool.verifyTxData(req.tx); err != nil {
+			req.result <- err
+			continue
+		}
+		verified = append(verified, req)
+	}
+	verified = verifySignatures(verified)
+	if len(verified) == 0 {
+		return
+	}
+
+	// the head may have moved since verification, addTxLocked checks the txs against it again.
+	v.pool.mu.Lock()
+	defer v.pool.mu.Unlock()
+
+	for _, req := range verified {
+		req.result <- v.pool.addTxLocked(req.tx, req.local)
+	}
+}
+
+// verifySignatures returns the requests whose tx signature is valid, the
+// others are answered with errTxInvalidSignature.
+func verifySignatures(reqs []*verifyRequest) []*verifyRequest {
+	pubKeys := make([][]byte, len(reqs))
+	msgs := make([][]byte, len(reqs))
+	sigs := make([][]byte, len(reqs))
+	for i, req := range reqs {
+		hash := req.tx.Hash()
+		pubKeys[i] = req.tx.PublicKey()
+		msgs[i] = hash.CloneBytes()
+		sigs[i] = req.tx.Signature()
+	}
+	if len(reqs) > 1 && ed25519batch.Verify(pubKeys, msgs, sigs) {
+		return reqs
+	}
+
+	// a single tx or a batch with an invalid signature, find the invalid ones.
+	valid := reqs[:0]
+	for _, req := range reqs {
+		if !req.tx.Verify(req.tx.PublicKey()) {
+			req.result <- errTxInvalidSignature
+			continue
+		}
+		valid = append(valid, req)
+	}
+	return valid
+}
@@ -0,0 +1,134 @@
+package vm
+
+import (
+	"fmt"
+)
+
+// OpCode is an instruction of the VM.
+type OpCode byte
+
+// Opcodes. Every instruction is one byte, PUSH is followed by a length byte and
+// that many bytes of data, DUP and SWAP are followed by a one byte stack index,
+// EMIT is followed by the number of topics.
+const (
+	STOP OpCode = 0x00
+
+	ADD    OpCode = 0x01
+	SUB    OpCode = 0x02
+	MUL    OpCode = 0x03
+	DIV    OpCode = 0x04
+	MOD    OpCode = 0x05
+	LT     OpCode = 0x06
+	GT     OpCode = 0x07
+	EQ     OpCode = 0x08
+	ISZERO OpCode = 0x09
+	AND    OpCode = 0x0a
+	OR     OpCode = 0x0b
+	NOT    OpCode = 0x0c
+
+	PUSH OpCode = 0x10
+	POP  OpCode = 0x11
+	DUP  OpCode = 0x12
+	SWAP OpCode = 0x13
+
+	JUMP     OpCode = 0x20
+	JUMPI    OpCode = 0x21
+	JUMPDEST OpCode = 0x22
+
+	CALLER    OpCode = 0x30
+	CALLVALUE OpCode = 0x31
+	ADDRESS   OpCode = 0x32
+	INPUT     OpCode = 0x33
+	INPUTSIZE OpCode = 0x34
+	HEIGHT    OpCode = 0x35
+	TIMESTAMP OpCode = 0x36
+
+	SLOAD  OpCode = 0x40
+	SSTORE OpCode = 0x41
+
+	BALANCE  OpCode = 0x50
+	TRANSFER OpCode = 0x51
+	SHA3     OpCode = 0x52
+	EMIT     OpCode = 0x53
+
+	RETURN OpCode = 0x60
+	REVERT OpCode = 0x61
+)
+
+// Gas costs of instructions, host functions also pay per byte of data they handle.
+const (
+	GasQuick    uint64 = 1
+	GasFast     uint64 = 3
+	GasMid      uint64 = 5
+	GasJump     uint64 = 8
+	GasSload    uint64 = 50
+	GasSstore   uint64 = 200
+	GasBalance  uint64 = 50
+	GasTransfer uint64 = 200
+	GasSha3     uint64 = 30
+	GasEmit     uint64 = 100
+
+	// GasPerByte is paid for every byte stored, hashed or emitted.
+	GasPerByte uint64 = 2
+	// GasPerCodeByte is paid for every byte of code deployed.
+	GasPerCodeByte uint64 = 20
+)
+
+type operation struct {
+	name string
+	gas  uint64
+	// immediate is the number of bytes following the opcode, PUSH has a variable length.
+	immediate int
+}
+
+var operations = map[OpCode]operation{
+	STOP: {"STOP", 0, 0},
+
+	ADD:    {"ADD", GasFast, 0},
+	SUB:    {"SUB", GasFast, 0},
+	MUL:    {"MUL", GasMid, 0},
+	DIV:    {"DIV", GasMid, 0},
+	MOD:    {"MOD", GasMid, 0},
+	LT:     {"LT", GasFast, 0},
+	GT:     {"GT", GasFast, 0},
+	EQ:     {"EQ", GasFast, 0},
+	ISZERO: {"ISZERO", GasFast, 0},
+	AND:    {"AND", GasFast, 0},
+	OR:     {"OR", GasFast, 0},
+	NOT:    {"NOT", GasFast, 0},
+
+	PUSH: {"PUSH", GasQuick, 1},
+	POP:  {"POP", GasQuick, 0},
+	DUP:  {"DUP", GasQuick, 1},
+	SWAP: {"SWAP", GasQuick, 1},
+
+	JUMP:     {"JUMP", GasJump, 0},
+	JUMPI:    {"JUMPI", GasJump, 0},
+	JUMPDEST: {"JUMPDEST", GasQuick, 0},
+
+	CALLER:    {"CALLER", GasQuick, 0},
+	CALLVALUE: {"CALLVALUE", GasQuick, 0},
+	ADDRESS:   {"ADDRESS", GasQuick, 0},
+	INPUT:     {"INPUT", GasFast, 0},
+	INPUTSIZE: {"INPUTSIZE", GasQuick, 0},
+	HEIGHT:    {"HEIGHT", GasQuick, 0},
+	TIMESTAMP: {"TIMESTAMP", GasQuick, 0},
+
+	SLOAD:  {"SLOAD", GasSload, 0},
+	SSTORE: {"SSTORE", GasSstore, 0},
+
+	BALANCE:  {"BALANCE", GasBalance, 0},
+	TRANSFER: {"TRANSFER", GasTransfer, 0},
+	SHA3:     {"SHA3", GasSha3, 0},
+	EMIT:     {"EMIT", GasEmit, 1},
+
+	RETURN: {"RETURN", 0, 0},
+	REVERT: {"REVERT", 0, 0},
+}
+
+func (op OpCode) String() string {
+	if o, ok := operations[op]; ok {
+		return o.name
+	}
+	return fmt.Sprintf("opcode 0x%02x", byte(op))
+}
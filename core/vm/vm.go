@@ -0,0 +1,502 @@
+package vm
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/crypto/sha3"
+)
+
+/*
+The VM is a deterministic, gas-metered stack machine.
+
+Stack items are byte strings of at most MaxItemSize bytes. Arithmetic and
+comparison instructions read items as big endian unsigned integers and work
+modulo 2^256, their results are minimal big endian bytes, so zero is the empty
+item. Any non-empty item with a non-zero byte is true.
+
+Every instruction pays gas before it's executed. Execution stops at STOP,
+RETURN, REVERT or the end of code. Running out of gas or any other error
+consumes all gas, REVERT only consumes the gas used so far. Events and storage
+changes of a failed execution are discarded by the caller.
+*/
+
+const (
+	// MaxItemSize is the maximum size of a stack item, it's the largest PUSH data.
+	MaxItemSize = 255
+	// MaxStackDepth is the maximum number of items on the stack.
+	MaxStackDepth = 1024
+	// MaxCodeSize is the maximum size of contract code.
+	MaxCodeSize = 24 * 1024
+	// MaxTopics is the maximum number of topics of an event.
+	MaxTopics = 4
+)
+
+// Errors
+var (
+	ErrOutOfGas = errors.New("out of gas")
+	ErrReverted = errors.New("execution reverted")
+)
+
+var (
+	errInvalidOpcode   = errors.New("invalid opcode")
+	errTruncatedCode   = errors.New("code ends in the middle of an instruction")
+	errCodeTooLarge    = errors.New("code is too large")
+	errStackUnderflow  = errors.New("stack underflow")
+	errStackOverflow   = errors.New("stack overflow")
+	errInvalidJump     = errors.New("jump destination is not JUMPDEST")
+	errInvalidAddress  = errors.New("stack item is not an address")
+	errDivisionByZero  = errors.New("division by zero")
+	errInvalidStackRef = errors.New("invalid DUP or SWAP index")
+	errTooManyTopics   = errors.New("too many event topics")
+)
+
+var (
+	tt256 = new(big.Int).Lsh(big.NewInt(1), 256)
+	mask  = new(big.Int).Sub(tt256, big.NewInt(1))
+)
+
+// Host gives contracts access to the chain state, it's implemented by the executor.
+type Host interface {
+	// GetStorage returns value of key in storage of the running contract, nil if it does not exist.
+	GetStorage(key []byte) []byte
+	// SetStorage sets value of key in storage of the running contract, empty value deletes key.
+	SetStorage(key, value []byte)
+	// Balance returns balance of an account.
+	Balance(address common.Address) (*big.Int, error)
+	// Transfer sends amount from the running contract to an account.
+	Transfer(to common.Address, amount *big.Int) error
+}
+
+// Context is the environment of a contract call.
+type Context struct {
+	Caller    common.Address
+	Address   common.Address
+	Value     *big.Int
+	Input     [][]byte
+	Height    uint64
+	Timestamp int64
+	GasLimit  uint64
+}
+
+// Event is emitted by a contract with EMIT, topics are the first items popped.
+type Event struct {
+	Address common.Address
+	Topics  [][]byte
+	Data    []byte
+}
+
+// Result is the outcome of an execution.
+type Result struct {
+	GasUsed uint64
+	Return  []byte
+	Events  []*Event
+	// Err is nil if the execution succeeded.
+	Err error
+}
+
+// Validate checks that code only has known instructions which are not truncated.
+func Validate(code []byte) error {
+	_, err := analyze(code)
+	return err
+}
+
+// analyze validates code and returns positions of JUMPDEST instructions.
+func analyze(code []byte) (map[uint64]bool, error) {
+	if len(code) > MaxCodeSize {
+		return nil, errCodeTooLarge
+	}
+	dests := make(map[uint64]bool)
+	for pc := 0; pc < len(code); {
+		op := OpCode(code[pc])
+		o, ok := operations[op]
+		if !ok {
+			return nil, errInvalidOpcode
+		}
+		if op == JUMPDEST {
+			dests[uint64(pc)] = true
+		}
+		next := pc + 1 + o.immediate
+		if op == PUSH && pc+1 < len(code) {
+			next += int(code[pc+1])
+		}
+		if next > len(code) {
+			return nil, errTruncatedCode
+		}
+		pc = next
+	}
+	return dests, nil
+}
+
+type interpreter struct {
+	code  []byte
+	dests map[uint64]bool
+	ctx   *Context
+	host  Host
+
+	stack   [][]byte
+	gasUsed uint64
+	events  []*Event
+}
+
+// Execute runs code in ctx.
+func Execute(code []byte, ctx *Context, host Host) *Result {
+	in := &interpreter{
+		code: code,
+		ctx:  ctx,
+		host: host,
+	}
+	ret, err := in.run()
+
+	result := &Result{GasUsed: in.gasUsed, Return: ret, Err: err}
+	switch err {
+	case nil:
+		result.Events = in.events
+	case ErrReverted:
+	default:
+		result.GasUsed = ctx.GasLimit
+		result.Return = nil
+	}
+	return result
+}
+
+func (in *interpreter) useGas(gas uint64) error {
+	if in.ctx.GasLimit-in.gasUsed < gas {
+		in.gasUsed = in.ctx.GasLimit
+		return ErrOutOfGas
+	}
+	in.gasUsed += gas
+	return nil
+}
+
+func (in *interpreter) push(item []byte) error {
+	if len(in.stack) >= MaxStackDepth {
+		return errStackOverflow
+	}
+	in.stack = append(in.stack, item)
+	return nil
+}
+
+func (in *interpreter) pop() ([]byte, error) {
+	if len(in.stack) == 0 {
+		return nil, errStackUnderflow
+	}
+	item := in.stack[len(in.stack)-1]
+	in.stack = in.stack[:len(in.stack)-1]
+	return item, nil
+}
+
+func (in *interpreter) popBig() (*big.Int, error) {
+	item, err := in.pop()
+	if err != nil {
+		return nil, err
+	}
+	v := new(big.Int).SetBytes(item)
+	return v.And(v, mask), nil
+}
+
+func (in *interpreter) popAddress() (common.Address, error) {
+	var address common.Address
+	item, err := in.pop()
+	if err != nil {
+		return address, err
+	}
+	if len(item) != common.AddressLength {
+		return address, errInvalidAddress
+	}
+	address.SetBytes(item)
+	return address, nil
+}
+
+func (in *interpreter) pushBig(v *big.Int) error {
+	v = new(big.Int).And(v, mask)
+	if v.Sign() == 0 {
+		return in.push(nil)
+	}
+	return in.push(v.Bytes())
+}
+
+func (in *interpreter) pushBool(b bool) error {
+	if b {
+		return in.push([]byte{1})
+	}
+	return in.push(nil)
+}
+
+func (in *interpreter) run() ([]byte, error) {
+	dests, err := analyze(in.code)
+	if err != nil {
+		return nil, err
+	}
+	in.dests = dests
+
+	for pc := uint64(0); pc < uint64(len(in.code)); {
+		op := OpCode(in.code[pc])
+		if err := in.useGas(operations[op].gas); err != nil {
+			return nil, err
+		}
+		next := pc + 1 + uint64(operations[op].immediate)
+
+		switch op {
+		case STOP:
+			return nil, nil
+
+		case ADD, SUB, MUL, DIV, MOD, LT, GT, EQ, AND, OR:
+			if err := in.binaryOp(op); err != nil {
+				return nil, err
+			}
+
+		case ISZERO:
+			x, err := in.popBig()
+			if err != nil {
+				return nil, err
+			}
+			if err := in.pushBool(x.Sign() == 0); err != nil {
+				return nil, err
+			}
+
+		case NOT:
+			x, err := in.popBig()
+			if err != nil {
+				return nil, err
+			}
+			if err := in.pushBig(x.Xor(x, mask)); err != nil {
+				return nil, err
+			}
+
+		case PUSH:
+			n := uint64(in.code[pc+1])
+			data := append([]byte{}, in.code[pc+2:pc+2+n]...)
+			if err := in.push(data); err != nil {
+				return nil, err
+			}
+			next += n
+
+		case POP:
+			if _, err := in.pop(); err != nil {
+				return nil, err
+			}
+
+		case DUP:
+			i := int(in.code[pc+1])
+			if i >= len(in.stack) {
+				return nil, errInvalidStackRef
+			}
+			if err := in.push(in.stack[len(in.stack)-1-i]); err != nil {
+				return nil, err
+			}
+
+		case SWAP:
+			i := int(in.code[pc+1])
+			if i == 0 || i >= len(in.stack) {
+				return nil, errInvalidStackRef
+			}
+			top := len(in.stack) - 1
+			in.stack[top], in.stack[top-i] = in.stack[top-i], in.stack[top]
+
+		case JUMP, JUMPI:
+			dest, err := in.popBig()
+			if err != nil {
+				return nil, err
+			}
+			if op == JUMPI {
+				cond, err := in.popBig()
+				if err != nil {
+					return nil, err
+				}
+				if cond.Sign() == 0 {
+					break
+				}
+			}
+			if !dest.IsUint64() || !in.dests[dest.Uint64()] {
+				return nil, errInvalidJump
+			}
+			next = dest.Uint64()
+
+		case JUMPDEST:
+
+		case CALLER:
+			if err := in.push(in.ctx.Caller.CloneBytes()); err != nil {
+				return nil, err
+			}
+
+		case CALLVALUE:
+			if err := in.pushBig(in.ctx.Value); err != nil {
+				return nil, err
+			}
+
+		case ADDRESS:
+			if err := in.push(in.ctx.Address.CloneBytes()); err != nil {
+				return nil, err
+			}
+
+		case INPUT:
+			i, err := in.popBig()
+			if err != nil {
+				return nil, err
+			}
+			var arg []byte
+			if i.IsUint64() && i.Uint64() < uint64(len(in.ctx.Input)) {
+				arg = in.ctx.Input[i.Uint64()]
+			}
+			if err := in.push(arg); err != nil {
+				return nil, err
+			}
+
+		case INPUTSIZE:
+			if err := in.pushBig(new(big.Int).SetInt64(int64(len(in.ctx.Input)))); err != nil {
+				return nil, err
+			}
+
+		case HEIGHT:
+			if err := in.pushBig(new(big.Int).SetUint64(in.ctx.Height)); err != nil {
+				return nil, err
+			}
+
+		case TIMESTAMP:
+			if err := in.pushBig(new(big.Int).SetInt64(in.ctx.Timestamp)); err != nil {
+				return nil, err
+			}
+
+		case SLOAD:
+			key, err := in.pop()
+			if err != nil {
+				return nil, err
+			}
+			if err := in.push(in.host.GetStorage(key)); err != nil {
+				return nil, err
+			}
+
+		case SSTORE:
+			key, err := in.pop()
+			if err != nil {
+				return nil, err
+			}
+			value, err := in.pop()
+			if err != nil {
+				return nil, err
+			}
+			if err := in.useGas(GasPerByte * uint64(len(key)+len(value))); err != nil {
+				return nil, err
+			}
+			in.host.SetStorage(key, value)
+
+		case BALANCE:
+			address, err := in.popAddress()
+			if err != nil {
+				return nil, err
+			}
+			balance, err := in.host.Balance(address)
+			if err != nil {
+				return nil, err
+			}
+			if err := in.pushBig(balance); err != nil {
+				return nil, err
+			}
+
+		case TRANSFER:
+			to, err := in.popAddress()
+			if err != nil {
+				return nil, err
+			}
+			amount, err := in.popBig()
+			if err != nil {
+				return nil, err
+			}
+			if err := in.host.Transfer(to, amount); err != nil {
+				return nil, err
+			}
+
+		case SHA3:
+			data, err := in.pop()
+			if err != nil {
+				return nil, err
+			}
+			if err := in.useGas(GasPerByte * uint64(len(data))); err != nil {
+				return nil, err
+			}
+			hash := sha3.Sum256(data)
+			if err := in.push(hash[:]); err != nil {
+				return nil, err
+			}
+
+		case EMIT:
+			n := int(in.code[pc+1])
+			if n > MaxTopics {
+				return nil, errTooManyTopics
+			}
+			topics := make([][]byte, n)
+			size := 0
+			for i := range topics {
+				if topics[i], err = in.pop(); err != nil {
+					return nil, err
+				}
+				size += len(topics[i])
+			}
+			data, err := in.pop()
+			if err != nil {
+				return nil, err
+			}
+			if err := in.useGas(GasPerByte * uint64(size+len(data))); err != nil {
+				return nil, err
+			}
+			in.events = append(in.events, &Event{Address: in.ctx.Address, Topics: topics, Data: data})
+
+		case RETURN, REVERT:
+			data, err := in.pop()
+			if err != nil {
+				return nil, err
+			}
+			if op == REVERT {
+				return data, ErrReverted
+			}
+			return data, nil
+		}
+
+		pc = next
+	}
+	return nil, nil
+}
+
+// binaryOp pops x then y and pushes x op y.
+func (in *interpreter) binaryOp(op OpCode) error {
+	x, err := in.popBig()
+	if err != nil {
+		return err
+	}
+	y, err := in.popBig()
+	if err != nil {
+		return err
+	}
+
+	switch op {
+	case ADD:
+		return in.pushBig(x.Add(x, y))
+	case SUB:
+		return in.pushBig(x.Mod(x.Sub(x, y), tt256))
+	case MUL:
+		return in.pushBig(x.Mul(x, y))
+	case DIV:
+		if y.Sign() == 0 {
+			return errDivisionByZero
+		}
+		return in.pushBig(x.Div(x, y))
+	case MOD:
+		if y.Sign() == 0 {
+			return errDivisionByZero
+		}
+		return in.pushBig(x.Mod(x, y))
+	case LT:
+		return in.pushBool(x.Cmp(y) < 0)
+	case GT:
+		return in.pushBool(x.Cmp(y) > 0)
+	case EQ:
+		return in.pushBool(x.Cmp(y) == 0)
+	case AND:
+		return in.pushBig(x.And(x, y))
+	case OR:
+		return in.pushBig(x.Or(x, y))
+	}
+	return errInvalidOpcode
+}
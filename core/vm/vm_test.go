@@ -0,0 +1,203 @@
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/crypto/sha3"
+	"github.com/stretchr/testify/assert"
+)
+
+// testHost keeps storage and balances in maps.
+type testHost struct {
+	storage  map[string][]byte
+	balances map[common.Address]int64
+	self     common.Address
+}
+
+func newTestHost(self common.Address) *testHost {
+	return &testHost{
+		storage:  make(map[string][]byte),
+		balances: make(map[common.Address]int64),
+		self:     self,
+	}
+}
+
+func (h *testHost) GetStorage(key []byte) []byte {
+	return h.storage[string(key)]
+}
+
+func (h *testHost) SetStorage(key, value []byte) {
+	h.storage[string(key)] = value
+}
+
+func (h *testHost) Balance(address common.Address) (*big.Int, error) {
+	return big.NewInt(h.balances[address]), nil
+}
+
+func (h *testHost) Transfer(to common.Address, amount *big.Int) error {
+	if h.balances[h.self] < amount.Int64() {
+		return errors.New("insufficient balance")
+	}
+	h.balances[h.self] -= amount.Int64()
+	h.balances[to] += amount.Int64()
+	return nil
+}
+
+// asm assembles instructions, an OpCode is emitted as is, []byte is pushed.
+func asm(parts ...interface{}) []byte {
+	var code []byte
+	for _, part := range parts {
+		switch p := part.(type) {
+		case OpCode:
+			code = append(code, byte(p))
+		case []byte:
+			code = append(code, byte(PUSH), byte(len(p)))
+			code = append(code, p...)
+		case int:
+			code = append(code, byte(p))
+		}
+	}
+	return code
+}
+
+func newTestContext(input ...[]byte) *Context {
+	return &Context{
+		Caller:    common.NewAddress(common.AddressVersionEd25519, []byte("caller")),
+		Address:   common.NewAddress(common.AddressVersionContract, []byte("contract")),
+		Value:     big.NewInt(0),
+		Input:     input,
+		Height:    10,
+		Timestamp: 1557360000,
+		GasLimit:  100000,
+	}
+}
+
+func run(code []byte, ctx *Context) *Result {
+	return Execute(code, ctx, newTestHost(ctx.Address))
+}
+
+func TestArithmetic(t *testing.T) {
+	tests := []struct {
+		code     []byte
+		expected []byte
+	}{
+		{asm([]byte{3}, []byte{5}, ADD, RETURN), []byte{8}},
+		{asm([]byte{3}, []byte{5}, SUB, RETURN), []byte{2}},
+		{asm([]byte{3}, []byte{5}, MUL, RETURN), []byte{15}},
+		{asm([]byte{2}, []byte{7}, DIV, RETURN), []byte{3}},
+		{asm([]byte{2}, []byte{7}, MOD, RETURN), []byte{1}},
+		{asm([]byte{7}, []byte{2}, LT, RETURN), []byte{1}},
+		{asm([]byte{7}, []byte{2}, GT, RETURN), nil},
+		{asm([]byte{0, 7}, []byte{7}, EQ, RETURN), []byte{1}},
+		{asm([]byte{}, ISZERO, RETURN), []byte{1}},
+		{asm([]byte{6}, []byte{3}, AND, RETURN), []byte{2}},
+		{asm([]byte{6}, []byte{3}, OR, RETURN), []byte{7}},
+		// results wrap around 2^256.
+		{asm([]byte{1}, []byte{0}, SUB, NOT, RETURN), nil},
+	}
+	for i, test := range tests {
+		result := run(test.code, newTestContext())
+		assert.Nil(t, result.Err, i)
+		assert.Equal(t, test.expected, result.Return, i)
+	}
+
+	result := run(asm([]byte{}, []byte{1}, DIV, RETURN), newTestContext())
+	assert.Equal(t, errDivisionByZero, result.Err)
+}
+
+func TestJump(t *testing.T) {
+	// returns input[0] + input[0] if input[0] > 10, else input[0].
+	code := asm(
+		[]byte{}, INPUT, DUP, 0, []byte{10}, SWAP, 1, GT, ISZERO, []byte{19}, JUMPI,
+		DUP, 0, ADD,
+		JUMPDEST, RETURN,
+	)
+	assert.Equal(t, JUMPDEST, OpCode(code[19]))
+
+	result := run(code, newTestContext([]byte{20}))
+	assert.Nil(t, result.Err)
+	assert.Equal(t, []byte{40}, result.Return)
+
+	result = run(code, newTestContext([]byte{5}))
+	assert.Nil(t, result.Err)
+	assert.Equal(t, []byte{5}, result.Return)
+
+	result = run(asm([]byte{1}, JUMP), newTestContext())
+	assert.Equal(t, errInvalidJump, result.Err)
+}
+
+func TestGas(t *testing.T) {
+	ctx := newTestContext()
+	result := run(asm([]byte{3}, []byte{5}, ADD, STOP), ctx)
+	assert.Nil(t, result.Err)
+	assert.Equal(t, 2*GasQuick+GasFast, result.GasUsed)
+
+	// an endless loop runs out of gas and consumes all of it.
+	ctx.GasLimit = 1000
+	result = run(asm(JUMPDEST, []byte{}, JUMP), ctx)
+	assert.Equal(t, ErrOutOfGas, result.Err)
+	assert.Equal(t, ctx.GasLimit, result.GasUsed)
+
+	// revert keeps unused gas.
+	result = run(asm([]byte("no"), REVERT), ctx)
+	assert.Equal(t, ErrReverted, result.Err)
+	assert.Equal(t, GasQuick, result.GasUsed)
+	assert.Equal(t, []byte("no"), result.Return)
+}
+
+func TestHostFunctions(t *testing.T) {
+	ctx := newTestContext()
+	host := newTestHost(ctx.Address)
+	host.balances[ctx.Address] = 100
+
+	// store the caller under "owner", pay 30 to the caller and emit an event.
+	code := asm(
+		CALLER, []byte("owner"), SSTORE,
+		[]byte{30}, CALLER, TRANSFER,
+		ADDRESS, BALANCE, []byte("paid"), EMIT, 1,
+		[]byte("owner"), SLOAD, SHA3, RETURN,
+	)
+	assert.Nil(t, Validate(code))
+
+	result := Execute(code, ctx, host)
+	assert.Nil(t, result.Err)
+	assert.Equal(t, ctx.Caller.CloneBytes(), host.storage["owner"])
+	assert.Equal(t, int64(70), host.balances[ctx.Address])
+	assert.Equal(t, int64(30), host.balances[ctx.Caller])
+
+	hash := sha3.Sum256(ctx.Caller.CloneBytes())
+	assert.Equal(t, hash[:], result.Return)
+	assert.Equal(t, 1, len(result.Events))
+	assert.Equal(t, []byte("paid"), result.Events[0].Topics[0])
+	assert.Equal(t, []byte{70}, result.Events[0].Data)
+
+	result = Execute(asm([]byte("d"), []byte("b"), []byte("a"), EMIT, 2, STOP), ctx, host)
+	assert.Nil(t, result.Err)
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("b")}, result.Events[0].Topics)
+	assert.Equal(t, []byte("d"), result.Events[0].Data)
+
+	result = Execute(asm([]byte("d"), EMIT, MaxTopics+1), ctx, host)
+	assert.Equal(t, errTooManyTopics, result.Err)
+
+	// events of failed executions are discarded.
+	result = Execute(asm([]byte{1}, []byte("e"), EMIT, 1, []byte{}, REVERT), ctx, host)
+	assert.Equal(t, ErrReverted, result.Err)
+	assert.Empty(t, result.Events)
+
+	result = Execute(asm([]byte{200}, CALLER, TRANSFER), ctx, host)
+	assert.NotNil(t, result.Err)
+}
+
+func TestValidate(t *testing.T) {
+	assert.Nil(t, Validate(asm([]byte{1, 2, 3}, POP, STOP)))
+	assert.Equal(t, errInvalidOpcode, Validate([]byte{0xff}))
+	assert.Equal(t, errTruncatedCode, Validate([]byte{byte(PUSH), 3, 1}))
+	assert.Equal(t, errTruncatedCode, Validate([]byte{byte(DUP)}))
+	assert.Equal(t, errCodeTooLarge, Validate(make([]byte, MaxCodeSize+1)))
+
+	result := run(asm(POP), newTestContext())
+	assert.Equal(t, errStackUnderflow, result.Err)
+}
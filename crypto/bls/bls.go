@@ -0,0 +1,290 @@
+// Package bls implements BLS signatures on the bn256 curve.
+//
+// Signatures live in G1 and public keys live in G2, so a signature is 64 bytes
+// and a public key is 128 bytes. Signatures of many validators on the same
+// message can be aggregated into one signature which is verified against the
+// aggregated public key. To prevent rogue key attacks, a public key must be
+// registered together with a proof of possession of its private key, and only
+// public keys whose proof has been verified may be aggregated.
+package bls
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/crypto/bn256"
+	"github.com/ldmtam/tam-chain/crypto/sha3"
+)
+
+const (
+	// PrivateKeyLength is the length of marshaled private key.
+	PrivateKeyLength = 32
+	// PublicKeyLength is the length of marshaled public key.
+	PublicKeyLength = 128
+	// SignatureLength is the length of marshaled signature.
+	SignatureLength = 64
+)
+
+// domain separation tags, so a signature on a message can never be used as a
+// proof of possession and vice versa.
+var (
+	signatureDomain  = []byte("TAMCHAIN-BLS-SIG")
+	possessionDomain = []byte("TAMCHAIN-BLS-POP")
+)
+
+// Errors
+var (
+	ErrInvalidPrivateKey = errors.New("invalid bls private key")
+	ErrInvalidPublicKey  = errors.New("invalid bls public key")
+	ErrInvalidSignature  = errors.New("invalid bls signature")
+	ErrEmptyAggregation  = errors.New("nothing to aggregate")
+)
+
+var (
+	g2Generator = new(bn256.G2).ScalarBaseMult(big.NewInt(1))
+
+	// (P + 1) / 4, used to compute square roots since P = 3 mod 4.
+	sqrtExponent = new(big.Int).Rsh(new(big.Int).Add(bn256.P, big.NewInt(1)), 2)
+	curveB       = big.NewInt(3)
+)
+
+// PrivateKey is a BLS private key.
+type PrivateKey struct {
+	k *big.Int
+}
+
+// PublicKey is a BLS public key.
+type PublicKey struct {
+	p *bn256.G2
+}
+
+// Signature is a BLS signature, it can also be an aggregation of signatures.
+type Signature struct {
+	s *bn256.G1
+}
+
+// GenerateKey returns a new BLS private key read from r. If r is nil, crypto/rand is used.
+func GenerateKey(r io.Reader) (*PrivateKey, error) {
+	if r == nil {
+		r = rand.Reader
+	}
+	for {
+		k, err := rand.Int(r, bn256.Order)
+		if err != nil {
+			return nil, err
+		}
+		if k.Sign() > 0 {
+			return &PrivateKey{k: k}, nil
+		}
+	}
+}
+
+// UnmarshalPrivateKey decodes private key from bytes.
+func UnmarshalPrivateKey(b []byte) (*PrivateKey, error) {
+	if len(b) != PrivateKeyLength {
+		return nil, ErrInvalidPrivateKey
+	}
+	k := new(big.Int).SetBytes(b)
+	if k.Sign() == 0 || k.Cmp(bn256.Order) >= 0 {
+		return nil, ErrInvalidPrivateKey
+	}
+	return &PrivateKey{k: k}, nil
+}
+
+// Marshal encodes private key to bytes.
+func (sk *PrivateKey) Marshal() []byte {
+	b := make([]byte, PrivateKeyLength)
+	kBytes := sk.k.Bytes()
+	copy(b[PrivateKeyLength-len(kBytes):], kBytes)
+	return b
+}
+
+// PublicKey returns public key of the private key.
+func (sk *PrivateKey) PublicKey() *PublicKey {
+	return &PublicKey{p: new(bn256.G2).ScalarBaseMult(sk.k)}
+}
+
+// Sign signs on message.
+func (sk *PrivateKey) Sign(msg []byte) *Signature {
+	return &Signature{s: new(bn256.G1).ScalarMult(hashToG1(signatureDomain, msg), sk.k)}
+}
+
+// ProvePossession returns a proof that we own the private key of our public key.
+func (sk *PrivateKey) ProvePossession() *Signature {
+	return &Signature{s: new(bn256.G1).ScalarMult(hashToG1(possessionDomain, sk.PublicKey().Marshal()), sk.k)}
+}
+
+// UnmarshalPublicKey decodes public key from bytes.
+func UnmarshalPublicKey(b []byte) (*PublicKey, error) {
+	if len(b) != PublicKeyLength {
+		return nil, ErrInvalidPublicKey
+	}
+	// the point at infinity would verify any signature of itself.
+	if common.Equal(b, make([]byte, PublicKeyLength)) {
+		return nil, ErrInvalidPublicKey
+	}
+	p := new(bn256.G2)
+	if _, err := p.Unmarshal(b); err != nil {
+		return nil, ErrInvalidPublicKey
+	}
+	return &PublicKey{p: p}, nil
+}
+
+// Marshal encodes public key to bytes.
+func (pk *PublicKey) Marshal() []byte {
+	return pk.p.Marshal()
+}
+
+// Verify verifies signature on message.
+func (pk *PublicKey) Verify(msg []byte, sig *Signature) bool {
+	return pairingEqual(sig.s, hashToG1(signatureDomain, msg), pk.p)
+}
+
+// VerifyPossession verifies proof of possession of the public key.
+func (pk *PublicKey) VerifyPossession(proof *Signature) bool {
+	return pairingEqual(proof.s, hashToG1(possessionDomain, pk.Marshal()), pk.p)
+}
+
+// UnmarshalSignature decodes signature from bytes.
+func UnmarshalSignature(b []byte) (*Signature, error) {
+	if len(b) != SignatureLength {
+		return nil, ErrInvalidSignature
+	}
+	s := new(bn256.G1)
+	if _, err := s.Unmarshal(b); err != nil {
+		return nil, ErrInvalidSignature
+	}
+	return &Signature{s: s}, nil
+}
+
+// Marshal encodes signature to bytes.
+func (sig *Signature) Marshal() []byte {
+	return sig.s.Marshal()
+}
+
+// AggregateSignatures aggregates signatures into one signature.
+func AggregateSignatures(sigs []*Signature) (*Signature, error) {
+	if len(sigs) == 0 {
+		return nil, ErrEmptyAggregation
+	}
+	s := sigs[0].s
+	for _, sig := range sigs[1:] {
+		s = new(bn256.G1).Add(s, sig.s)
+	}
+	return &Signature{s: s}, nil
+}
+
+// AggregatePublicKeys aggregates public keys into one public key.
+//
+// The proof of possession of every public key must have been verified before,
+// otherwise the aggregated public key is vulnerable to rogue key attacks.
+func AggregatePublicKeys(pks []*PublicKey) (*PublicKey, error) {
+	if len(pks) == 0 {
+		return nil, ErrEmptyAggregation
+	}
+	p := pks[0].p
+	for _, pk := range pks[1:] {
+		p = new(bn256.G2).Add(p, pk.p)
+	}
+	return &PublicKey{p: p}, nil
+}
+
+// VerifyAggregate verifies an aggregated signature of public keys on the same message.
+func VerifyAggregate(pks []*PublicKey, msg []byte, sig *Signature) bool {
+	pk, err := AggregatePublicKeys(pks)
+	if err != nil {
+		return false
+	}
+	return pk.Verify(msg, sig)
+}
+
+// BatchVerify verifies many signatures on different messages at once, it's
+// faster than verifying them one by one. Each signature is weighted by a random
+// scalar so an invalid signature cannot be cancelled out by another one.
+func BatchVerify(pks []*PublicKey, msgs [][]byte, sigs []*Signature) bool {
+	if len(pks) == 0 || len(pks) != len(msgs) || len(pks) != len(sigs) {
+		return false
+	}
+
+	a := make([]*bn256.G1, 0, len(pks)+1)
+	b := make([]*bn256.G2, 0, len(pks)+1)
+
+	var sum *bn256.G1
+	for i := range pks {
+		r, err := randomScalar()
+		if err != nil {
+			return false
+		}
+		weighted := new(bn256.G1).ScalarMult(sigs[i].s, r)
+		if sum == nil {
+			sum = weighted
+		} else {
+			sum = new(bn256.G1).Add(sum, weighted)
+		}
+		a = append(a, new(bn256.G1).ScalarMult(hashToG1(signatureDomain, msgs[i]), r))
+		b = append(b, pks[i].p)
+	}
+	a = append(a, new(bn256.G1).Neg(sum))
+	b = append(b, g2Generator)
+
+	return bn256.PairingCheck(a, b)
+}
+
+// pairingEqual checks e(sig, g2) == e(h, pk).
+func pairingEqual(sig, h *bn256.G1, pk *bn256.G2) bool {
+	return bn256.PairingCheck(
+		[]*bn256.G1{new(bn256.G1).Neg(sig), h},
+		[]*bn256.G2{g2Generator, pk},
+	)
+}
+
+// randomScalar returns a random non-zero 128-bit scalar.
+func randomScalar() (*big.Int, error) {
+	b := make([]byte, 16)
+	for {
+		if _, err := io.ReadFull(rand.Reader, b); err != nil {
+			return nil, err
+		}
+		r := new(big.Int).SetBytes(b)
+		if r.Sign() > 0 {
+			return r, nil
+		}
+	}
+}
+
+// hashToG1 maps message to a point of G1 using try-and-increment: x is taken
+// from sha3-256(domain || counter || msg) until x^3 + 3 has a square root y.
+func hashToG1(domain, msg []byte) *bn256.G1 {
+	for counter := uint32(0); ; counter++ {
+		hasher := sha3.New256()
+		hasher.Write(domain)
+		hasher.Write(common.FromUint32(counter))
+		hasher.Write(msg)
+
+		x := new(big.Int).SetBytes(hasher.Sum(nil))
+		x.Mod(x, bn256.P)
+
+		rhs := new(big.Int).Exp(x, big.NewInt(3), bn256.P)
+		rhs.Add(rhs, curveB)
+		rhs.Mod(rhs, bn256.P)
+
+		y := new(big.Int).Exp(rhs, sqrtExponent, bn256.P)
+		if new(big.Int).Exp(y, big.NewInt(2), bn256.P).Cmp(rhs) != 0 || y.Sign() == 0 {
+			continue
+		}
+
+		point := make([]byte, SignatureLength)
+		xBytes, yBytes := x.Bytes(), y.Bytes()
+		copy(point[SignatureLength/2-len(xBytes):SignatureLength/2], xBytes)
+		copy(point[SignatureLength-len(yBytes):], yBytes)
+
+		g := new(bn256.G1)
+		if _, err := g.Unmarshal(point); err != nil {
+			continue
+		}
+		return g
+	}
+}
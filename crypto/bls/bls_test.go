@@ -0,0 +1,126 @@
+package bls
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	message      = []byte("block 1 commit")
+	otherMessage = []byte("block 2 commit")
+)
+
+func generateKeys(t testing.TB, n int) []*PrivateKey {
+	sks := make([]*PrivateKey, n)
+	for i := range sks {
+		sk, err := GenerateKey(nil)
+		assert.Nil(t, err)
+		sks[i] = sk
+	}
+	return sks
+}
+
+func TestSignAndVerify(t *testing.T) {
+	sk := generateKeys(t, 1)[0]
+	pk := sk.PublicKey()
+
+	sig := sk.Sign(message)
+	assert.True(t, pk.Verify(message, sig))
+	assert.False(t, pk.Verify(otherMessage, sig))
+
+	otherPk := generateKeys(t, 1)[0].PublicKey()
+	assert.False(t, otherPk.Verify(message, sig))
+}
+
+func TestMarshal(t *testing.T) {
+	sk := generateKeys(t, 1)[0]
+
+	newSk, err := UnmarshalPrivateKey(sk.Marshal())
+	assert.Nil(t, err)
+	assert.Equal(t, sk.Marshal(), newSk.Marshal())
+
+	pkBytes := sk.PublicKey().Marshal()
+	assert.Len(t, pkBytes, PublicKeyLength)
+	pk, err := UnmarshalPublicKey(pkBytes)
+	assert.Nil(t, err)
+
+	sigBytes := sk.Sign(message).Marshal()
+	assert.Len(t, sigBytes, SignatureLength)
+	sig, err := UnmarshalSignature(sigBytes)
+	assert.Nil(t, err)
+	assert.True(t, pk.Verify(message, sig))
+
+	_, err = UnmarshalPublicKey(make([]byte, PublicKeyLength))
+	assert.Equal(t, ErrInvalidPublicKey, err)
+
+	_, err = UnmarshalSignature(sigBytes[1:])
+	assert.Equal(t, ErrInvalidSignature, err)
+}
+
+func TestProofOfPossession(t *testing.T) {
+	sks := generateKeys(t, 2)
+
+	proof := sks[0].ProvePossession()
+	assert.True(t, sks[0].PublicKey().VerifyPossession(proof))
+	assert.False(t, sks[1].PublicKey().VerifyPossession(proof))
+
+	// a proof of possession is not a signature on the public key.
+	sig := sks[0].Sign(sks[0].PublicKey().Marshal())
+	assert.False(t, sks[0].PublicKey().VerifyPossession(sig))
+}
+
+func TestAggregate(t *testing.T) {
+	sks := generateKeys(t, 4)
+
+	pks := make([]*PublicKey, len(sks))
+	sigs := make([]*Signature, len(sks))
+	for i, sk := range sks {
+		pks[i] = sk.PublicKey()
+		sigs[i] = sk.Sign(message)
+	}
+
+	aggSig, err := AggregateSignatures(sigs)
+	assert.Nil(t, err)
+	assert.True(t, VerifyAggregate(pks, message, aggSig))
+	assert.False(t, VerifyAggregate(pks, otherMessage, aggSig))
+	assert.False(t, VerifyAggregate(pks[1:], message, aggSig))
+
+	_, err = AggregateSignatures(nil)
+	assert.Equal(t, ErrEmptyAggregation, err)
+}
+
+func TestBatchVerify(t *testing.T) {
+	sks := generateKeys(t, 3)
+
+	pks := make([]*PublicKey, len(sks))
+	msgs := make([][]byte, len(sks))
+	sigs := make([]*Signature, len(sks))
+	for i, sk := range sks {
+		pks[i] = sk.PublicKey()
+		msgs[i] = []byte{byte(i)}
+		sigs[i] = sk.Sign(msgs[i])
+	}
+	assert.True(t, BatchVerify(pks, msgs, sigs))
+
+	sigs[0], sigs[1] = sigs[1], sigs[0]
+	assert.False(t, BatchVerify(pks, msgs, sigs))
+	assert.False(t, BatchVerify(pks[1:], msgs, sigs))
+}
+
+func BenchmarkVerifyAggregate(b *testing.B) {
+	sks := generateKeys(b, 32)
+
+	pks := make([]*PublicKey, len(sks))
+	sigs := make([]*Signature, len(sks))
+	for i, sk := range sks {
+		pks[i] = sk.PublicKey()
+		sigs[i] = sk.Sign(message)
+	}
+	aggSig, _ := AggregateSignatures(sigs)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		VerifyAggregate(pks, message, aggSig)
+	}
+}
@@ -7,7 +7,7 @@
 // Package bn256 implements the Optimal Ate pairing over a 256-bit Barreto-Naehrig curve.
 package bn256
 
-import "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+import "github.com/ldmtam/tam-chain/crypto/bn256/cloudflare"
 
 // G1 is an abstract cyclic group. The zero value is suitable for use as the
 // output of an operation, but cannot be used as an input.
@@ -17,6 +17,12 @@ type G1 = bn256.G1
 // output of an operation, but cannot be used as an input.
 type G2 = bn256.G2
 
+// Order is the number of elements in both G1 and G2.
+var Order = bn256.Order
+
+// P is a prime over which we form a basic field.
+var P = bn256.P
+
 // PairingCheck calculates the Optimal Ate pairing for a set of points.
 func PairingCheck(a []*G1, b []*G2) bool {
 	return bn256.PairingCheck(a, b)
@@ -0,0 +1,111 @@
+package ecies
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+// X25519KeySize is the size of X25519 public and private keys.
+const X25519KeySize = 32
+
+// X25519Params are the ECIES parameters used with X25519 keys.
+var X25519Params = ECIES_AES128_SHA256
+
+// ErrEmptyMessage is returned when encrypting an empty message.
+var ErrEmptyMessage = fmt.Errorf("ecies: message is empty")
+
+// EncryptX25519 encrypts a message to a X25519 public key. It is the same
+// construction as Encrypt with the ECDH over curve25519: the ephemeral public
+// key, the IV and ciphertext, and the message tag are concatenated.
+func EncryptX25519(rand io.Reader, pub, m, s1, s2 []byte) (ct []byte, err error) {
+	if len(pub) != X25519KeySize {
+		return nil, ErrInvalidPublicKey
+	}
+	if len(m) == 0 {
+		return nil, ErrEmptyMessage
+	}
+	params := X25519Params
+
+	r := make([]byte, X25519KeySize)
+	if _, err = io.ReadFull(rand, r); err != nil {
+		return
+	}
+	R, err := curve25519.X25519(r, curve25519.Basepoint)
+	if err != nil {
+		return
+	}
+
+	z, err := curve25519.X25519(r, pub)
+	if err != nil {
+		return nil, ErrSharedKeyIsPointAtInfinity
+	}
+
+	Ke, Km, err := deriveX25519Keys(params, z, s1)
+	if err != nil {
+		return
+	}
+
+	em, err := symEncrypt(rand, params, Ke, m)
+	if err != nil {
+		return
+	}
+
+	d := messageTag(params.Hash, Km, em, s2)
+
+	ct = make([]byte, len(R)+len(em)+len(d))
+	copy(ct, R)
+	copy(ct[len(R):], em)
+	copy(ct[len(R)+len(em):], d)
+	return
+}
+
+// DecryptX25519 decrypts a ciphertext produced by EncryptX25519 with a X25519 private key.
+func DecryptX25519(prv, c, s1, s2 []byte) (m []byte, err error) {
+	if len(prv) != X25519KeySize {
+		return nil, ErrImport
+	}
+	params := X25519Params
+	hLen := params.Hash().Size()
+
+	if len(c) <= X25519KeySize+params.BlockSize+hLen {
+		return nil, ErrInvalidMessage
+	}
+	mStart := X25519KeySize
+	mEnd := len(c) - hLen
+
+	z, err := curve25519.X25519(prv, c[:X25519KeySize])
+	if err != nil {
+		return nil, ErrInvalidPublicKey
+	}
+
+	Ke, Km, err := deriveX25519Keys(params, z, s1)
+	if err != nil {
+		return
+	}
+
+	d := messageTag(params.Hash, Km, c[mStart:mEnd], s2)
+	if subtle.ConstantTimeCompare(c[mEnd:], d) != 1 {
+		err = ErrInvalidMessage
+		return
+	}
+
+	m, err = symDecrypt(params, Ke, c[mStart:mEnd])
+	return
+}
+
+func deriveX25519Keys(params *ECIESParams, z, s1 []byte) (Ke, Km []byte, err error) {
+	hash := params.Hash()
+	K, err := concatKDF(hash, z, s1, params.KeyLen+params.KeyLen)
+	if err != nil {
+		return
+	}
+	Ke = K[:params.KeyLen]
+	Km = K[params.KeyLen:]
+	hash.Write(Km)
+	Km = hash.Sum(nil)
+	hash.Reset()
+	return
+}
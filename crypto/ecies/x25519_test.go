@@ -0,0 +1,57 @@
+package ecies
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+func generateX25519Key(t *testing.T) (prv, pub []byte) {
+	prv = make([]byte, X25519KeySize)
+	if _, err := rand.Read(prv); err != nil {
+		t.Fatal(err)
+	}
+	pub, err := curve25519.X25519(prv, curve25519.Basepoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return prv, pub
+}
+
+func TestEncryptDecryptX25519(t *testing.T) {
+	prv1, pub1 := generateX25519Key(t)
+	prv2, _ := generateX25519Key(t)
+
+	message := []byte("Hello, world.")
+	ct, err := EncryptX25519(rand.Reader, pub1, message, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pt, err := DecryptX25519(prv1, ct, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pt, message) {
+		t.Fatal("ecies: plaintext doesn't match message")
+	}
+
+	if _, err = DecryptX25519(prv2, ct, nil, nil); err != ErrInvalidMessage {
+		t.Fatal("ecies: encryption should not have succeeded")
+	}
+
+	ct[len(ct)-1] ^= 0x01
+	if _, err = DecryptX25519(prv1, ct, nil, nil); err != ErrInvalidMessage {
+		t.Fatal("ecies: tampered ciphertext should not be decrypted")
+	}
+}
+
+func TestEncryptX25519EmptyMessage(t *testing.T) {
+	_, pub := generateX25519Key(t)
+
+	if _, err := EncryptX25519(rand.Reader, pub, nil, nil, nil); err != ErrEmptyMessage {
+		t.Fatal("ecies: empty message should not be encrypted")
+	}
+}
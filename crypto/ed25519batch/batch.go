@@ -0,0 +1,91 @@
+// Package ed25519batch verifies ed25519 signatures in batches.
+package ed25519batch
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"io"
+
+	"filippo.io/edwards25519"
+)
+
+const (
+	publicKeySize = 32
+	signatureSize = 64
+)
+
+// Verify returns true if every sigs[i] is a valid signature of msgs[i] by
+// pubKeys[i]. The signatures are checked at once by a random linear
+// combination of their verification equations, which is about twice as fast
+// as checking them one by one. If it returns false, at least one signature is
+// invalid and the signatures must be checked one by one to find it.
+//
+// The combined equation is multiplied by the cofactor, so a signature with a
+// small order component may pass here and fail ed25519.Verify. Batches only
+// filter what is worth checking further, e.g. txs entering the tx pool.
+func Verify(pubKeys, msgs, sigs [][]byte) bool {
+	return verify(rand.Reader, pubKeys, msgs, sigs)
+}
+
+func verify(rand io.Reader, pubKeys, msgs, sigs [][]byte) bool {
+	n := len(sigs)
+	if len(pubKeys) != n || len(msgs) != n {
+		return false
+	}
+	if n == 0 {
+		return true
+	}
+
+	// sum z_i*R_i + sum z_i*k_i*A_i - (sum z_i*s_i)*B == 0
+	scalars := make([]*edwards25519.Scalar, 0, 2*n+1)
+	points := make([]*edwards25519.Point, 0, 2*n+1)
+	sSum := edwards25519.NewScalar()
+	zBytes := make([]byte, 32)
+	for i := 0; i < n; i++ {
+		pubKey, sig := pubKeys[i], sigs[i]
+		if len(pubKey) != publicKeySize || len(sig) != signatureSize {
+			return false
+		}
+		A, err := new(edwards25519.Point).SetBytes(pubKey)
+		if err != nil {
+			return false
+		}
+		R, err := new(edwards25519.Point).SetBytes(sig[:32])
+		if err != nil || !bytes.Equal(R.Bytes(), sig[:32]) {
+			return false
+		}
+		s, err := edwards25519.NewScalar().SetCanonicalBytes(sig[32:])
+		if err != nil {
+			return false
+		}
+
+		h := sha512.New()
+		h.Write(sig[:32])
+		h.Write(pubKey)
+		h.Write(msgs[i])
+		k, err := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+		if err != nil {
+			return false
+		}
+
+		// a random 128 bits z_i is below the group order, so it's canonical.
+		if _, err := io.ReadFull(rand, zBytes[:16]); err != nil {
+			return false
+		}
+		z, err := edwards25519.NewScalar().SetCanonicalBytes(zBytes)
+		if err != nil {
+			return false
+		}
+
+		sSum.MultiplyAdd(z, s, sSum)
+		scalars = append(scalars, z, edwards25519.NewScalar().Multiply(z, k))
+		points = append(points, R, A)
+	}
+	scalars = append(scalars, sSum.Negate(sSum))
+	points = append(points, edwards25519.NewGeneratorPoint())
+
+	check := new(edwards25519.Point).VarTimeMultiScalarMult(scalars, points)
+	check.MultByCofactor(check)
+	return check.Equal(edwards25519.NewIdentityPoint()) == 1
+}
@@ -0,0 +1,79 @@
+package ed25519batch
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ed25519"
+)
+
+func signBatch(t testing.TB, n int) (pubKeys, msgs, sigs [][]byte) {
+	for i := 0; i < n; i++ {
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		assert.Nil(t, err)
+		msg := []byte(fmt.Sprintf("message %d", i))
+		pubKeys = append(pubKeys, pub)
+		msgs = append(msgs, msg)
+		sigs = append(sigs, ed25519.Sign(priv, msg))
+	}
+	return
+}
+
+func TestVerify(t *testing.T) {
+	pubKeys, msgs, sigs := signBatch(t, 64)
+	assert.True(t, Verify(pubKeys, msgs, sigs))
+	assert.True(t, Verify(pubKeys[:1], msgs[:1], sigs[:1]))
+	assert.True(t, Verify(nil, nil, nil))
+
+	// one wrong message fails the batch.
+	msgs[10] = []byte("other message")
+	assert.False(t, Verify(pubKeys, msgs, sigs))
+	msgs[10] = []byte("message 10")
+
+	// a signature by another key.
+	_, _, other := signBatch(t, 1)
+	sigs[20], other[0] = other[0], sigs[20]
+	assert.False(t, Verify(pubKeys, msgs, sigs))
+	sigs[20] = other[0]
+
+	// malformed inputs.
+	assert.False(t, Verify(pubKeys, msgs, sigs[:63]))
+	sigs[30] = sigs[30][:63]
+	assert.False(t, Verify(pubKeys, msgs, sigs))
+}
+
+func TestVerifyNonCanonicalS(t *testing.T) {
+	pubKeys, msgs, sigs := signBatch(t, 2)
+
+	// s+L is rejected by ed25519.Verify, so it must fail the batch too.
+	order := []byte{0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x10}
+	s := sigs[0][32:]
+	var carry uint16
+	for i := range s {
+		sum := uint16(s[i]) + uint16(order[i]) + carry
+		s[i], carry = byte(sum), sum>>8
+	}
+	assert.False(t, ed25519.Verify(pubKeys[0], msgs[0], sigs[0]))
+	assert.False(t, Verify(pubKeys, msgs, sigs))
+}
+
+func BenchmarkVerifySingle(b *testing.B) {
+	pubKeys, msgs, sigs := signBatch(b, 64)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range sigs {
+			ed25519.Verify(pubKeys[j], msgs[j], sigs[j])
+		}
+	}
+}
+
+func BenchmarkVerifyBatch(b *testing.B) {
+	pubKeys, msgs, sigs := signBatch(b, 64)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Verify(pubKeys, msgs, sigs)
+	}
+}
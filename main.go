@@ -8,6 +8,10 @@ import (
 	log "github.com/inconshreveable/log15"
 	"github.com/ldmtam/tam-chain/abstraction"
 	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/block"
+	"github.com/ldmtam/tam-chain/core/chain"
+	"github.com/ldmtam/tam-chain/core/state"
+	"github.com/ldmtam/tam-chain/core/transaction"
 	"github.com/ldmtam/tam-chain/core/txpool"
 	"github.com/ldmtam/tam-chain/p2p"
 	"github.com/ldmtam/tam-chain/rpc"
@@ -30,27 +34,79 @@ func main() {
 			Name:  "bootnode",
 			Usage: "list of boot nodes",
 		},
+		cli.Uint64Flag{
+			Name:  "maxinrate",
+			Usage: "max inbound bytes per second of all peers, 0 means unlimited",
+		},
+		cli.Uint64Flag{
+			Name:  "maxoutrate",
+			Usage: "max outbound bytes per second of all peers, 0 means unlimited",
+		},
+		cli.StringFlag{
+			Name:  "transport",
+			Value: common.TransportCustom,
+			Usage: "p2p transport of txs and blocks: custom or gossipsub",
+		},
+		cli.StringFlag{
+			Name:  "network",
+			Value: common.Mainnet,
+			Usage: "network profile: mainnet or testnet",
+		},
+		cli.StringFlag{
+			Name:  "genesis",
+			Usage: "genesis file, overrides the network profile",
+		},
 	}
 
 	app.Action = func(c *cli.Context) error {
+		chainConfig, err := loadChainConfig(c)
+		if err != nil {
+			return err
+		}
+
 		p2pConfig := &common.P2PConfig{
-			ChainID:   1,
+			ChainID:   chainConfig.ChainID,
 			Version:   1,
 			Port:      c.String("port"),
 			SeedNodes: []string{c.String("bootnode")},
 			DataPath:  c.String("datapath"),
+
+			MaxInboundRate:  c.Uint64("maxinrate"),
+			MaxOutboundRate: c.Uint64("maxoutrate"),
+			Transport:       c.String("transport"),
 		}
 
+		stateDB := state.NewStateDB()
+		blockChain := chain.NewBlockChain(chainConfig, stateDB)
+
 		var net abstraction.P2PService
-		net, _ = p2p.NewNetService(p2pConfig)
-		net.Start()
+		net, err = p2p.NewNetService(p2pConfig, blockChain)
+		if err != nil {
+			return err
+		}
 
-		var txp abstraction.TxPool
-		txp = txpool.NewTxPImpl()
+		txp := txpool.NewTxPImpl(chainConfig, stateDB)
+		txp.SetHead(blockChain.Head())
 		txp.Start()
 
+		setValidators(net, txp, blockChain, chainConfig)
+		// txs are many and small, a late block costs more than the bandwidth.
+		net.SetBroadcastStrategy(abstraction.MessageType(p2p.PublishTx), abstraction.BroadcastStrategy{
+			Fanout:   true,
+			Priority: abstraction.MessagePriority(p2p.NormalMessage),
+		})
+		net.SetBroadcastStrategy(abstraction.MessageType(p2p.PublishBlock), abstraction.BroadcastStrategy{
+			Priority: abstraction.MessagePriority(p2p.UrgentMessage),
+		})
+		go importTxs(net, txp)
+		go importBlocks(net, txp, blockChain)
+		if err := net.Start(); err != nil {
+			txp.Stop()
+			return err
+		}
+
 		rpc := rpc.NewJSONServer("0.0.0.0", "3000")
-		rpc.Start(txp)
+		rpc.Start(txp, blockChain, net, chainConfig)
 
 		waitExit()
 
@@ -67,6 +123,102 @@ func main() {
 	}
 }
 
+// setValidators verifies txs and blocks from peers before they are relayed.
+// Validators may run concurrently, so they do not change the chain or the tx
+// pool. Senders are only penalized for data which is invalid at every node.
+func setValidators(net abstraction.P2PService, txp *txpool.TxPImpl, blockChain *chain.BlockChain, chainConfig *common.ChainConfig) {
+	net.SetValidator(abstraction.MessageType(p2p.PublishTx), func(from string, data []byte) error {
+		tx := &transaction.TxImpl{}
+		if err := tx.Unmarshal(data); err != nil {
+			return err
+		}
+		if tx.ChainID() != chainConfig.ChainID {
+			return txpool.ErrTxChainIDMismatch
+		}
+		if err := tx.VerifySignature(); err != nil {
+			return err
+		}
+		// a closed validity window or a fee below the base fee depend on our head.
+		if err := txp.VerifyTxHead(tx); err != nil {
+			return p2p.ErrIgnoreMessage
+		}
+		return nil
+	})
+
+	net.SetValidator(abstraction.MessageType(p2p.PublishBlock), func(from string, data []byte) error {
+		b := &block.Block{}
+		if err := b.Unmarshal(data); err != nil {
+			return err
+		}
+		// only blocks on top of our head can be verified.
+		err := blockChain.VerifyBlock(b)
+		if err == chain.ErrStaleBlock || err == chain.ErrFutureBlock {
+			return p2p.ErrIgnoreMessage
+		}
+		return err
+	})
+}
+
+// importTxs adds the valid txs from peers to the tx pool, the txs queued
+// meanwhile are added together so their signatures are verified in a batch.
+func importTxs(net abstraction.P2PService, txp *txpool.TxPImpl) {
+	ch := net.Register("txpool", abstraction.MessageType(p2p.PublishTx))
+	for msg := range ch {
+		msgs := []abstraction.IncomingMessage{msg}
+		for done := false; !done; {
+			select {
+			case msg, ok := <-ch:
+				if !ok {
+					done = true
+					break
+				}
+				msgs = append(msgs, msg)
+			default:
+				done = true
+			}
+		}
+
+		var txs []abstraction.Transaction
+		var from []string
+		for _, msg := range msgs {
+			tx := &transaction.TxImpl{}
+			if err := tx.Unmarshal(msg.Data); err != nil {
+				continue
+			}
+			txs = append(txs, tx)
+			from = append(from, msg.From)
+		}
+		for i, err := range txp.AddTxs(txs, false) {
+			if err != nil {
+				log.Debug("Tx from peer is not added.", "from", from[i], "err", err)
+			}
+		}
+	}
+}
+
+// importBlocks applies the valid blocks from peers one at a time, several
+// blocks of a height may pass the validator and only the first is applied.
+func importBlocks(net abstraction.P2PService, txp *txpool.TxPImpl, blockChain *chain.BlockChain) {
+	for msg := range net.Register("blockchain", abstraction.MessageType(p2p.PublishBlock)) {
+		b := &block.Block{}
+		if err := b.Unmarshal(msg.Data); err != nil {
+			continue
+		}
+		if err := blockChain.ApplyBlock(b); err != nil {
+			log.Debug("Block from peer is not applied.", "height", b.Header.Height, "from", msg.From, "err", err)
+			continue
+		}
+		txp.SetHead(b)
+	}
+}
+
+func loadChainConfig(c *cli.Context) (*common.ChainConfig, error) {
+	if c.String("genesis") != "" {
+		return common.LoadChainConfig(c.String("genesis"))
+	}
+	return common.NetworkConfig(c.String("network"))
+}
+
 func waitExit() {
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
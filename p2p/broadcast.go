@@ -0,0 +1,329 @@
+package p2p
+
+import (
+	"errors"
+	"math"
+	mrand "math/rand"
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	log "github.com/inconshreveable/log15"
+	"github.com/ldmtam/tam-chain/p2p/pb"
+	peer "github.com/libp2p/go-libp2p-peer"
+)
+
+/*
+Messages reach the network in one of two ways:
+  * Broadcast floods a message to every neighbor which does not have it.
+  * BroadcastFanout pushes it to a random sqrt(N) of the neighbors and only
+    announces its hash to the others, which fetch it from us unless they got
+    it meanwhile. It saves bandwidth for large payloads like blocks at the
+    cost of a round trip.
+Both should be used with gossip types, so receivers drop duplicates. In
+gossipsub mode both publish the types which have a topic.
+
+Callers set the BroadcastStrategy of each gossip type, which Publish uses to
+send our own messages and relay uses for valid gossip from neighbors, so a
+message travels the whole network the way its originator sent it. Types
+without a strategy are flooded with normal priority.
+*/
+
+const (
+	// maxMessageHashes is the maximum number of hashes in an announcement or a fetch request.
+	maxMessageHashes = 64
+	// maxCachedMessages is the maximum number of announced messages kept for fetching.
+	maxCachedMessages = 4096
+	// messageCacheTTL is how long an announced message can be fetched.
+	messageCacheTTL = time.Minute
+	// fetchTimeout is how long we wait for a fetched message before asking another announcer.
+	fetchTimeout = 5 * time.Second
+)
+
+// errors
+var (
+	ErrPeerNotFound      = errors.New("peer is not a neighbor")
+	errTooManyHashes     = errors.New("too many message hashes")
+	errInvalidHashLength = errors.New("invalid message hash length")
+)
+
+// BroadcastStrategy is how messages of a type are published and relayed.
+type BroadcastStrategy struct {
+	// Fanout sends messages by BroadcastFanout, they are flooded by Broadcast otherwise.
+	Fanout   bool
+	Priority MessagePriority
+}
+
+var defaultBroadcastStrategy = BroadcastStrategy{Priority: NormalMessage}
+
+type cachedMessage struct {
+	typ   MessageType
+	data  []byte
+	added time.Time
+}
+
+// messageCache keeps the messages we announced so neighbors can fetch them,
+// and the hashes we are fetching.
+type messageCache struct {
+	mu       sync.Mutex
+	messages map[[32]byte]*cachedMessage
+	order    [][32]byte
+	fetching map[[32]byte]time.Time
+}
+
+func newMessageCache() *messageCache {
+	return &messageCache{
+		messages: make(map[[32]byte]*cachedMessage),
+		fetching: make(map[[32]byte]time.Time),
+	}
+}
+
+// add caches a message, the oldest ones are evicted once they expire or the cache is full.
+func (c *messageCache) add(hash [32]byte, typ MessageType, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.messages[hash]; ok {
+		return
+	}
+	now := time.Now()
+	c.messages[hash] = &cachedMessage{typ: typ, data: data, added: now}
+	c.order = append(c.order, hash)
+	for len(c.order) > 0 {
+		oldest := c.messages[c.order[0]]
+		if len(c.order) <= maxCachedMessages && now.Sub(oldest.added) <= messageCacheTTL {
+			break
+		}
+		delete(c.messages, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+// get returns the message of hash, nil if it's not cached or expired.
+func (c *messageCache) get(hash [32]byte) *cachedMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m, ok := c.messages[hash]
+	if !ok || time.Since(m.added) > messageCacheTTL {
+		return nil
+	}
+	return m
+}
+
+// startFetch returns false if hash is being fetched already.
+func (c *messageCache) startFetch(hash [32]byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if started, ok := c.fetching[hash]; ok && now.Sub(started) <= fetchTimeout {
+		return false
+	}
+	if len(c.fetching) >= maxCachedMessages {
+		for h, started := range c.fetching {
+			if now.Sub(started) > fetchTimeout {
+				delete(c.fetching, h)
+			}
+		}
+	}
+	c.fetching[hash] = now
+	return true
+}
+
+// messageBuilder builds the message of typ with data once per protocol version.
+type messageBuilder struct {
+	chainID uint32
+	typ     MessageType
+	data    []byte
+	msgs    map[uint16]*p2pMessage
+}
+
+func (pm *PeerManager) newMessageBuilder(typ MessageType, data []byte) *messageBuilder {
+	return &messageBuilder{
+		chainID: pm.config.ChainID,
+		typ:     typ,
+		data:    data,
+		msgs:    make(map[uint16]*p2pMessage),
+	}
+}
+
+func (b *messageBuilder) build(version uint16) *p2pMessage {
+	msg, ok := b.msgs[version]
+	if !ok {
+		msg = newP2PMessage(b.chainID, b.typ, version, b.data)
+		b.msgs[version] = msg
+	}
+	return msg
+}
+
+// Broadcast sends a message of typ with data to the neighbors which do not
+// have it yet, or publishes it to its topic in gossipsub mode.
+func (pm *PeerManager) Broadcast(data []byte, typ MessageType, mp MessagePriority) {
+	if pm.pubsub != nil && pm.pubsub.publish(typ, data) {
+		return
+	}
+	b := pm.newMessageBuilder(typ, data)
+	pm.seen.add(b.build(pm.config.Version).hash())
+
+	for _, p := range pm.Neighbors() {
+		if err := p.SendMessage(b.build(p.version()), mp, true); err != nil {
+			log.Debug("Broadcasting message failed.", "pid", p.id.Pretty(), "type", typ, "err", err)
+		}
+	}
+}
+
+// BroadcastFanout sends a message of typ with data to a random sqrt(N) of the
+// neighbors which do not have it yet and announces its hash to the others, or
+// publishes it to its topic in gossipsub mode.
+func (pm *PeerManager) BroadcastFanout(data []byte, typ MessageType, mp MessagePriority) {
+	if pm.pubsub != nil && pm.pubsub.publish(typ, data) {
+		return
+	}
+	b := pm.newMessageBuilder(typ, data)
+	hash := b.build(pm.config.Version).hash()
+	pm.seen.add(hash)
+	pm.messages.add(hash, typ, data)
+
+	announcement, err := proto.Marshal(&p2ppb.Announcement{Hashes: [][]byte{hash[:]}})
+	if err != nil {
+		log.Error("Encoding announcement failed.", "err", err)
+		return
+	}
+
+	neighbors := pm.Neighbors()
+	mrand.Shuffle(len(neighbors), func(i, j int) {
+		neighbors[i], neighbors[j] = neighbors[j], neighbors[i]
+	})
+	fanout := int(math.Ceil(math.Sqrt(float64(len(neighbors)))))
+	for _, p := range neighbors {
+		if p.hasMessage(hash) {
+			continue
+		}
+		if fanout > 0 {
+			fanout--
+			err = p.SendMessage(b.build(p.version()), mp, true)
+		} else {
+			p.recordMessage(hash)
+			err = pm.sendTo(p, AnnounceMessages, announcement, mp)
+		}
+		if err != nil {
+			log.Debug("Broadcasting message failed.", "pid", p.id.Pretty(), "type", typ, "err", err)
+		}
+	}
+}
+
+// SetBroadcastStrategy sets how messages of typ are published and relayed.
+func (pm *PeerManager) SetBroadcastStrategy(typ MessageType, s BroadcastStrategy) {
+	pm.strategies.Store(typ, s)
+}
+
+func (pm *PeerManager) broadcastStrategy(typ MessageType) BroadcastStrategy {
+	if s, ok := pm.strategies.Load(typ); ok {
+		return s.(BroadcastStrategy)
+	}
+	return defaultBroadcastStrategy
+}
+
+// Publish sends a message of typ with data to the network by the broadcast strategy of typ.
+func (pm *PeerManager) Publish(data []byte, typ MessageType) {
+	s := pm.broadcastStrategy(typ)
+	if s.Fanout {
+		pm.BroadcastFanout(data, typ, s.Priority)
+	} else {
+		pm.Broadcast(data, typ, s.Priority)
+	}
+}
+
+// relay passes valid gossip received from a neighbor on to the others, by the
+// broadcast strategy its originator published it with.
+func (pm *PeerManager) relay(typ MessageType, data []byte) {
+	if !gossipTypes[typ] {
+		return
+	}
+	pm.Publish(data, typ)
+}
+
+// SendToPeer sends a message of typ with data to the neighbor of peerID.
+func (pm *PeerManager) SendToPeer(peerID peer.ID, data []byte, typ MessageType, mp MessagePriority) error {
+	p := pm.GetNeighbor(peerID)
+	if p == nil {
+		return ErrPeerNotFound
+	}
+	return pm.sendTo(p, typ, data, mp)
+}
+
+// parseMessageHashes validates the hashes of an announcement or a fetch request.
+func parseMessageHashes(hashes [][]byte) ([][32]byte, error) {
+	if len(hashes) > maxMessageHashes {
+		return nil, errTooManyHashes
+	}
+	parsed := make([][32]byte, len(hashes))
+	for i, h := range hashes {
+		if len(h) != len(parsed[i]) {
+			return nil, errInvalidHashLength
+		}
+		copy(parsed[i][:], h)
+	}
+	return parsed, nil
+}
+
+// handleAnnouncement fetches the announced messages we have not seen and are not fetching yet.
+func (pm *PeerManager) handleAnnouncement(data []byte, from peer.ID) error {
+	announcement := &p2ppb.Announcement{}
+	if err := proto.Unmarshal(data, announcement); err != nil {
+		return err
+	}
+	hashes, err := parseMessageHashes(announcement.Hashes)
+	if err != nil {
+		return err
+	}
+	p := pm.GetNeighbor(from)
+	if p == nil {
+		return nil
+	}
+
+	req := &p2ppb.FetchRequest{}
+	for _, hash := range hashes {
+		p.recordMessage(hash)
+		if pm.seen.has(hash) || !pm.messages.startFetch(hash) {
+			continue
+		}
+		req.Hashes = append(req.Hashes, hash[:])
+	}
+	if len(req.Hashes) == 0 {
+		return nil
+	}
+	reqData, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return pm.sendTo(p, FetchMessages, reqData, UrgentMessage)
+}
+
+// handleFetchRequest sends the requested messages we announced, unknown hashes are ignored.
+func (pm *PeerManager) handleFetchRequest(data []byte, from peer.ID) error {
+	req := &p2ppb.FetchRequest{}
+	if err := proto.Unmarshal(data, req); err != nil {
+		return err
+	}
+	hashes, err := parseMessageHashes(req.Hashes)
+	if err != nil {
+		return err
+	}
+	p := pm.GetNeighbor(from)
+	if p == nil {
+		return nil
+	}
+
+	for _, hash := range hashes {
+		m := pm.messages.get(hash)
+		if m == nil {
+			continue
+		}
+		if err := pm.sendTo(p, m.typ, m.data, NormalMessage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package p2p
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ldmtam/tam-chain/common"
+	peer "github.com/libp2p/go-libp2p-peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/willf/bloom"
+)
+
+// newTestNode returns a PeerManager without a host, its neighbors are linked
+// to other test nodes in memory.
+func newTestNode() *PeerManager {
+	return &PeerManager{
+		config:     &common.P2PConfig{ChainID: 1, Version: 1},
+		neighbors:  new(sync.Map),
+		subs:       new(sync.Map),
+		reputation: newReputation(),
+		seen:       newSeenCache(),
+		messages:   newMessageCache(),
+		requests:   newRequests(),
+	}
+}
+
+func newLinkedPeer(pm *PeerManager, id peer.ID) *Peer {
+	p := &Peer{
+		id:          id,
+		peerManager: pm,
+		recentMsg:   bloom.NewWithEstimates(bloomMaxItemCount, bloomErrRate),
+		urgentMsgCh: make(chan *p2pMessage, msgChanSize),
+		normalMsgCh: make(chan *p2pMessage, msgChanSize),
+		quitWriteCh: make(chan struct{}),
+		status:      PeerStatus{Version: 1},
+		limiters:    [2]*rateLimiter{newRateLimiter(), newRateLimiter()},
+	}
+	pm.neighbors.Store(id, p)
+	return p
+}
+
+// link makes the nodes a and b of ids aID and bID neighbors, until quit is closed.
+func link(a, b *PeerManager, aID, bID peer.ID, quit chan struct{}) {
+	toB, toA := newLinkedPeer(a, bID), newLinkedPeer(b, aID)
+	go deliver(toB, toA, quit)
+	go deliver(toA, toB, quit)
+}
+
+// deliver passes the messages sent to the neighbor from to the node of to,
+// like the read loop of to would.
+func deliver(from, to *Peer, quit chan struct{}) {
+	for {
+		var msg *p2pMessage
+		select {
+		case msg = <-from.urgentMsgCh:
+		case msg = <-from.normalMsgCh:
+		case <-quit:
+			return
+		}
+		if gossipTypes[msg.messageType()] {
+			to.recordMessage(msg.hash())
+		}
+		to.handleMessage(msg)
+	}
+}
+
+func waitMessage(t *testing.T, ch chan IncomingMessage, typ MessageType, data []byte) {
+	select {
+	case in := <-ch:
+		assert.Equal(t, typ, in.Type())
+		assert.Equal(t, data, in.Data())
+	case <-time.After(time.Second):
+		t.Fatalf("%v is not received", typ)
+	}
+}
+
+func TestRelay(t *testing.T) {
+	quit := make(chan struct{})
+	defer close(quit)
+
+	// a is linked to b only, b to a and the others.
+	a, b := newTestNode(), newTestNode()
+	nodes := []*PeerManager{a, b}
+	link(a, b, "a", "b", quit)
+	var subs []chan IncomingMessage
+	for _, id := range []peer.ID{"c1", "c2", "c3", "c4"} {
+		c := newTestNode()
+		nodes = append(nodes, c)
+		link(b, c, "b", id, quit)
+		subs = append(subs, c.Register("test", PublishTx, PublishBlock))
+	}
+	for _, pm := range nodes {
+		pm.SetBroadcastStrategy(PublishTx, BroadcastStrategy{Fanout: true, Priority: NormalMessage})
+		pm.SetBroadcastStrategy(PublishBlock, BroadcastStrategy{Priority: UrgentMessage})
+	}
+
+	// b pushes the tx to 3 of its 5 neighbors, the other one fetches it.
+	a.Publish([]byte("tx"), PublishTx)
+	for _, ch := range subs {
+		waitMessage(t, ch, PublishTx, []byte("tx"))
+	}
+	assert.NotNil(t, b.messages.get(newP2PMessage(1, PublishTx, 1, []byte("tx")).hash()))
+
+	// the block is flooded, nothing is announced.
+	a.Publish([]byte("block"), PublishBlock)
+	for _, ch := range subs {
+		waitMessage(t, ch, PublishBlock, []byte("block"))
+	}
+	assert.Nil(t, b.messages.get(newP2PMessage(1, PublishBlock, 1, []byte("block")).hash()))
+
+	// invalid gossip is not relayed.
+	b.SetValidator(PublishBlock, func(from peer.ID, data []byte) error {
+		return ErrIgnoreMessage
+	})
+	a.Publish([]byte("future block"), PublishBlock)
+	for _, ch := range subs {
+		select {
+		case in := <-ch:
+			t.Fatalf("%s is relayed", in.Data())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func TestMessageCache(t *testing.T) {
+	c := newMessageCache()
+	h1, h2 := [32]byte{1}, [32]byte{2}
+
+	c.add(h1, PublishTx, []byte("tx"))
+	m := c.get(h1)
+	assert.Equal(t, PublishTx, m.typ)
+	assert.Equal(t, []byte("tx"), m.data)
+	assert.Nil(t, c.get(h2))
+
+	// expired messages are evicted by the next add.
+	m.added = time.Now().Add(-messageCacheTTL - time.Second)
+	assert.Nil(t, c.get(h1))
+	c.add(h2, PublishTx, nil)
+	assert.Len(t, c.order, 1)
+	assert.NotNil(t, c.get(h2))
+
+	// a hash is fetched from one announcer at a time.
+	assert.True(t, c.startFetch(h1))
+	assert.False(t, c.startFetch(h1))
+	c.fetching[h1] = time.Now().Add(-fetchTimeout - time.Second)
+	assert.True(t, c.startFetch(h1))
+}
+
+func TestParseMessageHashes(t *testing.T) {
+	hashes, err := parseMessageHashes([][]byte{make([]byte, 32), append(make([]byte, 31), 1)})
+	assert.Nil(t, err)
+	assert.Equal(t, [][32]byte{{}, {31: 1}}, hashes)
+
+	_, err = parseMessageHashes([][]byte{make([]byte, 31)})
+	assert.Equal(t, errInvalidHashLength, err)
+
+	_, err = parseMessageHashes(make([][]byte, maxMessageHashes+1))
+	assert.Equal(t, errTooManyHashes, err)
+}
@@ -0,0 +1,110 @@
+package p2p
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/ldmtam/tam-chain/crypto/sha3"
+)
+
+/*
+Gossip is deduplicated twice:
+  * every Peer records the hashes of gossip it sent us or we sent it in its
+    recentMsg bloom filter, and broadcasts skip peers which already have a
+    message.
+  * the seen cache of the PeerManager drops inbound gossip we got from any
+    peer before, so subscribers handle and relay a message once.
+*/
+
+const (
+	// seenCacheSize is the number of hashes in a generation of the seen cache.
+	seenCacheSize = 100000
+	// seenCacheTTL is how long a generation of the seen cache is used.
+	seenCacheTTL = 2 * time.Minute
+)
+
+// gossipTypes are the message types which are relayed through the network.
+var gossipTypes = map[MessageType]bool{
+	PublishTx:    true,
+	PublishBlock: true,
+}
+
+// hash identifies a message by its type and data, the header is not hashed
+// as the version differs between peers.
+func (m *p2pMessage) hash() [32]byte {
+	b := make([]byte, 2, 2+len(m.rawData()))
+	binary.BigEndian.PutUint16(b, uint16(m.messageType()))
+	return sha3.Sum256(append(b, m.rawData()...))
+}
+
+// hasMessage returns true if the peer may have the message of hash.
+func (p *Peer) hasMessage(hash [32]byte) bool {
+	p.bloomMutex.Lock()
+	defer p.bloomMutex.Unlock()
+
+	return p.recentMsg.Test(hash[:])
+}
+
+// recordMessage records that the peer has the message of hash, the filter is
+// cleared once it holds bloomMaxItemCount items to keep its error rate.
+func (p *Peer) recordMessage(hash [32]byte) {
+	p.bloomMutex.Lock()
+	defer p.bloomMutex.Unlock()
+
+	if p.bloomItemCount >= bloomMaxItemCount {
+		p.recentMsg.ClearAll()
+		p.bloomItemCount = 0
+	}
+	if !p.recentMsg.TestAndAdd(hash[:]) {
+		p.bloomItemCount++
+	}
+}
+
+// seenCache remembers message hashes for one to two generations, a generation
+// ends after seenCacheTTL or seenCacheSize hashes.
+type seenCache struct {
+	mu      sync.Mutex
+	current map[[32]byte]struct{}
+	prev    map[[32]byte]struct{}
+	rotated time.Time
+}
+
+func newSeenCache() *seenCache {
+	return &seenCache{
+		current: make(map[[32]byte]struct{}),
+		prev:    make(map[[32]byte]struct{}),
+		rotated: time.Now(),
+	}
+}
+
+// has returns true if hash was seen.
+func (c *seenCache) has(hash [32]byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.current[hash]
+	if !ok {
+		_, ok = c.prev[hash]
+	}
+	return ok
+}
+
+// add adds hash and returns true if it was seen before.
+func (c *seenCache) add(hash [32]byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.current[hash]; ok {
+		return true
+	}
+	_, seen := c.prev[hash]
+
+	if len(c.current) >= seenCacheSize || time.Since(c.rotated) > seenCacheTTL {
+		c.prev = c.current
+		c.current = make(map[[32]byte]struct{})
+		c.rotated = time.Now()
+	}
+	c.current[hash] = struct{}{}
+	return seen
+}
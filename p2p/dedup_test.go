@@ -0,0 +1,36 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageHash(t *testing.T) {
+	data := []byte("tx")
+	// the version is not hashed.
+	assert.Equal(t, newP2PMessage(1, PublishTx, 1, data).hash(), newP2PMessage(1, PublishTx, 2, data).hash())
+	assert.NotEqual(t, newP2PMessage(1, PublishTx, 1, data).hash(), newP2PMessage(1, Ping, 1, data).hash())
+	assert.NotEqual(t, newP2PMessage(1, PublishTx, 1, data).hash(), newP2PMessage(1, PublishTx, 1, []byte("tx2")).hash())
+}
+
+func TestSeenCache(t *testing.T) {
+	c := newSeenCache()
+	h1, h2 := [32]byte{1}, [32]byte{2}
+
+	assert.False(t, c.add(h1))
+	assert.True(t, c.add(h1))
+
+	// hashes of the previous generation are still seen.
+	c.rotated = time.Now().Add(-seenCacheTTL - time.Second)
+	assert.False(t, c.add(h2))
+	assert.True(t, c.add(h1))
+
+	// and forgotten after another rotation.
+	c.rotated = time.Now().Add(-seenCacheTTL - time.Second)
+	c.add([32]byte{3})
+	c.rotated = time.Now().Add(-seenCacheTTL - time.Second)
+	c.add([32]byte{4})
+	assert.False(t, c.add(h2))
+}
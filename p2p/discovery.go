@@ -0,0 +1,187 @@
+package p2p
+
+import (
+	"crypto/rand"
+	"errors"
+	mrand "math/rand"
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	log "github.com/inconshreveable/log15"
+	"github.com/ldmtam/tam-chain/p2p/pb"
+	kbucket "github.com/libp2p/go-libp2p-kbucket"
+	peer "github.com/libp2p/go-libp2p-peer"
+	peerstore "github.com/libp2p/go-libp2p-peerstore"
+	"github.com/multiformats/go-multiaddr"
+)
+
+/*
+Discovery follows Kademlia: every syncRoutingTableInterval a few random
+neighbors are asked for the peers closest to our own ID, which fills the
+buckets near us, and to a random ID, which fills the far buckets. Peers in
+responses are added to the routing table, the routing table file keeps them
+across restarts and the dial loop connects to them.
+*/
+
+const (
+	// discoveryFanout is the number of neighbors queried in a round.
+	discoveryFanout = 3
+	// maxQueryIDs is the maximum number of ids in a query.
+	maxQueryIDs = 4
+	// maxAddrsPerPeer is the maximum number of addresses of a peer in a response.
+	maxAddrsPerPeer = 8
+	// queryTimeout is how long a response to a query is accepted.
+	queryTimeout = 30 * time.Second
+)
+
+var (
+	errTooManyQueryIDs     = errors.New("too many ids in routing query")
+	errUnsolicitedResponse = errors.New("routing response without a query")
+)
+
+// discovery tracks the routing queries we sent, responses to other queries are dropped.
+type discovery struct {
+	queries sync.Map // map[peer.ID]time.Time
+}
+
+func (pm *PeerManager) discoveryLoop() {
+	defer pm.wg.Done()
+
+	pm.discoverPeers()
+	for {
+		select {
+		case <-pm.quitCh:
+			return
+		case <-time.After(syncRoutingTableInterval):
+			pm.discoverPeers()
+		}
+	}
+}
+
+// discoverPeers queries random neighbors for the peers closest to our ID and a random ID.
+func (pm *PeerManager) discoverPeers() {
+	randomID := make([]byte, 32)
+	rand.Read(randomID)
+	query := &p2ppb.RoutingQuery{Ids: [][]byte{[]byte(pm.host.ID()), randomID}}
+	data, err := proto.Marshal(query)
+	if err != nil {
+		log.Error("Encoding routing query failed.", "err", err)
+		return
+	}
+
+	neighbors := pm.Neighbors()
+	mrand.Shuffle(len(neighbors), func(i, j int) {
+		neighbors[i], neighbors[j] = neighbors[j], neighbors[i]
+	})
+	if len(neighbors) > discoveryFanout {
+		neighbors = neighbors[:discoveryFanout]
+	}
+	for _, p := range neighbors {
+		pm.discovery.queries.Store(p.id, time.Now())
+		if err := pm.sendTo(p, RoutingTableQuery, data, NormalMessage); err != nil {
+			log.Debug("Sending routing query failed.", "pid", p.id.Pretty(), "err", err)
+		}
+	}
+}
+
+// handleRoutingQuery answers a query with the peers closest to each id,
+// excluding the peer who asks.
+func (pm *PeerManager) handleRoutingQuery(data []byte, from peer.ID) error {
+	query := &p2ppb.RoutingQuery{}
+	if err := proto.Unmarshal(data, query); err != nil {
+		return err
+	}
+	if len(query.Ids) > maxQueryIDs {
+		return errTooManyQueryIDs
+	}
+
+	seen := make(map[peer.ID]bool)
+	resp := &p2ppb.RoutingResponse{}
+	for _, id := range query.Ids {
+		for _, pid := range pm.routingTable.NearestPeers(kbucket.ConvertKey(string(id)), bucketSize) {
+			if pid == from || seen[pid] {
+				continue
+			}
+			seen[pid] = true
+
+			addrs := pm.peerStore.Addrs(pid)
+			if len(addrs) == 0 {
+				continue
+			}
+			if len(addrs) > maxAddrsPerPeer {
+				addrs = addrs[:maxAddrsPerPeer]
+			}
+			info := &p2ppb.PeerInfo{Id: []byte(pid)}
+			for _, addr := range addrs {
+				info.Addrs = append(info.Addrs, addr.Bytes())
+			}
+			resp.Peers = append(resp.Peers, info)
+		}
+	}
+
+	respData, err := proto.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	p := pm.GetNeighbor(from)
+	if p == nil {
+		return nil
+	}
+	return pm.sendTo(p, RoutingTableResponse, respData, NormalMessage)
+}
+
+// handleRoutingResponse adds the valid peers of a response to a query we sent to the routing table.
+func (pm *PeerManager) handleRoutingResponse(data []byte, from peer.ID) error {
+	sent, ok := pm.discovery.queries.Load(from)
+	if !ok || time.Since(sent.(time.Time)) > queryTimeout {
+		return errUnsolicitedResponse
+	}
+	pm.discovery.queries.Delete(from)
+
+	resp := &p2ppb.RoutingResponse{}
+	if err := proto.Unmarshal(data, resp); err != nil {
+		return err
+	}
+	if len(resp.Peers) > maxQueryIDs*bucketSize {
+		resp.Peers = resp.Peers[:maxQueryIDs*bucketSize]
+	}
+	for _, info := range resp.Peers {
+		pid, addrs, err := parsePeerInfo(info)
+		if err != nil {
+			log.Debug("Invalid peer in routing response.", "from", from.Pretty(), "err", err)
+			continue
+		}
+		if pid == pm.host.ID() || pm.IsBanned(pid) {
+			continue
+		}
+		pm.peerStore.AddAddrs(pid, addrs, peerstore.AddressTTL)
+		pm.routingTable.Update(pid)
+		pm.lastUpdateTime.Store(time.Now().Unix())
+	}
+	return nil
+}
+
+// parsePeerInfo validates the id and addresses of a peer, a peer without any
+// valid address is invalid.
+func parsePeerInfo(info *p2ppb.PeerInfo) (peer.ID, []multiaddr.Multiaddr, error) {
+	pid, err := peer.IDFromBytes(info.Id)
+	if err != nil {
+		return "", nil, err
+	}
+	var addrs []multiaddr.Multiaddr
+	for _, b := range info.Addrs {
+		if len(addrs) == maxAddrsPerPeer {
+			break
+		}
+		addr, err := multiaddr.NewMultiaddrBytes(b)
+		if err != nil {
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+	if len(addrs) == 0 {
+		return "", nil, errInvalidMultiaddr
+	}
+	return pid, addrs, nil
+}
@@ -0,0 +1,155 @@
+package p2p
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/block"
+	"github.com/ldmtam/tam-chain/p2p/pb"
+	libnet "github.com/libp2p/go-libp2p-net"
+	peer "github.com/libp2p/go-libp2p-peer"
+)
+
+// Capability is a service a node offers to its peers.
+type Capability uint64
+
+// Capabilities
+const (
+	// CapabilityBlocks means the node serves blocks for sync.
+	CapabilityBlocks Capability = 1 << iota
+	// CapabilityTxRelay means the node relays txs.
+	CapabilityTxRelay
+
+	localCapabilities = CapabilityBlocks | CapabilityTxRelay
+)
+
+const (
+	handshakeTimeout = 10 * time.Second
+	// maxHandshakeLength bounds the data of a handshake message.
+	maxHandshakeLength = 1024
+)
+
+var (
+	errNotHandshake     = errors.New("first message is not a handshake")
+	errChainIDMismatch  = errors.New("peer is on another chain")
+	errGenesisMismatch  = errors.New("peer has another genesis block")
+	errNoCommonVersion  = errors.New("no common protocol version")
+	errInvalidHandshake = errors.New("invalid handshake")
+)
+
+// Chain is the local chain whose genesis and head are exchanged in handshakes.
+type Chain interface {
+	Genesis() *block.Block
+	Head() *block.Block
+}
+
+// PeerStatus is what a peer told about itself in the handshake, with the
+// protocol version negotiated with it.
+type PeerStatus struct {
+	Version      uint16
+	HeadHeight   uint64
+	HeadHash     common.Hash
+	Capabilities Capability
+}
+
+// Has returns true if the peer offers capability c.
+func (s *PeerStatus) Has(c Capability) bool {
+	return s.Capabilities&c == c
+}
+
+// minVersion returns the lowest protocol version we speak.
+func (pm *PeerManager) minVersion() uint16 {
+	if pm.config.MinVersion == 0 {
+		return pm.config.Version
+	}
+	return pm.config.MinVersion
+}
+
+// handshake exchanges handshakes over the first stream of a connection and
+// returns the status of the remote peer, the peer is incompatible if it fails.
+func (pm *PeerManager) handshake(s libnet.Stream) (*PeerStatus, error) {
+	if err := s.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return nil, err
+	}
+	defer s.SetDeadline(time.Time{})
+
+	genesisHash := pm.chain.Genesis().Hash()
+	head := pm.chain.Head()
+	headHash := head.Hash()
+	data, err := proto.Marshal(&p2ppb.Handshake{
+		MinVersion:   uint32(pm.minVersion()),
+		MaxVersion:   uint32(pm.config.Version),
+		ChainId:      pm.config.ChainID,
+		GenesisHash:  genesisHash.CloneBytes(),
+		HeadHeight:   head.Header.Height,
+		HeadHash:     headHash.CloneBytes(),
+		Capabilities: uint64(localCapabilities),
+	})
+	if err != nil {
+		return nil, err
+	}
+	msg := newP2PMessage(pm.config.ChainID, Handshake, pm.config.Version, data)
+	if _, err := s.Write(msg.content()); err != nil {
+		return nil, err
+	}
+
+	msg, err = readMessage(s, maxHandshakeLength)
+	if err != nil {
+		return nil, err
+	}
+	if msg.messageType() != Handshake {
+		return nil, errNotHandshake
+	}
+	data, err = msg.data()
+	if err != nil {
+		return nil, err
+	}
+	remote := &p2ppb.Handshake{}
+	if err := proto.Unmarshal(data, remote); err != nil {
+		return nil, errInvalidHandshake
+	}
+	return pm.checkHandshake(remote, genesisHash)
+}
+
+// checkHandshake checks that the remote peer is on our chain and negotiates
+// the highest protocol version both sides speak.
+func (pm *PeerManager) checkHandshake(remote *p2ppb.Handshake, genesisHash common.Hash) (*PeerStatus, error) {
+	if remote.ChainId != pm.config.ChainID {
+		return nil, errChainIDMismatch
+	}
+	if common.Equal(remote.GenesisHash, genesisHash.CloneBytes()) == false {
+		return nil, errGenesisMismatch
+	}
+	if remote.MinVersion > remote.MaxVersion || remote.MaxVersion > 0xffff || len(remote.HeadHash) != common.HashLength {
+		return nil, errInvalidHandshake
+	}
+
+	version := uint32(pm.config.Version)
+	if remote.MaxVersion < version {
+		version = remote.MaxVersion
+	}
+	if version < uint32(pm.minVersion()) || version < remote.MinVersion {
+		return nil, errNoCommonVersion
+	}
+
+	status := &PeerStatus{
+		Version:      uint16(version),
+		HeadHeight:   remote.HeadHeight,
+		Capabilities: Capability(remote.Capabilities),
+	}
+	status.HeadHash.SetBytes(remote.HeadHash)
+	return status, nil
+}
+
+// penalizeHandshake lowers the score of a peer whose handshake failed. Peers on
+// another chain are banned, failures like timeouts are not penalized.
+func (pm *PeerManager) penalizeHandshake(pid peer.ID, err error) {
+	switch err {
+	case errChainIDMismatch, errGenesisMismatch:
+		pm.AdjustScore(pid, PenaltyWrongChain)
+	case errNotHandshake, errInvalidHandshake, errMessageTooLarge, errInvalidChecksum, errUnmatchDataLength:
+		pm.AdjustScore(pid, PenaltyProtocolViolation)
+	}
+}
@@ -0,0 +1,56 @@
+package p2p
+
+import (
+	"testing"
+
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/p2p/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckHandshake(t *testing.T) {
+	pm := &PeerManager{config: &common.P2PConfig{ChainID: 1, MinVersion: 2, Version: 4}}
+	var genesisHash, headHash common.Hash
+	genesisHash[0] = 1
+	headHash[0] = 2
+
+	newHandshake := func(minVersion, maxVersion uint32) *p2ppb.Handshake {
+		return &p2ppb.Handshake{
+			MinVersion:   minVersion,
+			MaxVersion:   maxVersion,
+			ChainId:      1,
+			GenesisHash:  genesisHash.CloneBytes(),
+			HeadHeight:   10,
+			HeadHash:     headHash.CloneBytes(),
+			Capabilities: uint64(CapabilityBlocks),
+		}
+	}
+
+	// the highest common version is picked.
+	status, err := pm.checkHandshake(newHandshake(1, 3), genesisHash)
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(3), status.Version)
+	assert.Equal(t, uint64(10), status.HeadHeight)
+	assert.Equal(t, headHash, status.HeadHash)
+	assert.True(t, status.Has(CapabilityBlocks))
+	assert.False(t, status.Has(CapabilityTxRelay))
+
+	status, err = pm.checkHandshake(newHandshake(3, 9), genesisHash)
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(4), status.Version)
+
+	_, err = pm.checkHandshake(newHandshake(1, 1), genesisHash)
+	assert.Equal(t, errNoCommonVersion, err)
+	_, err = pm.checkHandshake(newHandshake(5, 6), genesisHash)
+	assert.Equal(t, errNoCommonVersion, err)
+	_, err = pm.checkHandshake(newHandshake(3, 2), genesisHash)
+	assert.Equal(t, errInvalidHandshake, err)
+
+	h := newHandshake(1, 4)
+	h.ChainId = 2
+	_, err = pm.checkHandshake(h, genesisHash)
+	assert.Equal(t, errChainIDMismatch, err)
+
+	_, err = pm.checkHandshake(newHandshake(1, 4), headHash)
+	assert.Equal(t, errGenesisMismatch, err)
+}
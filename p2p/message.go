@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"hash/crc32"
+	"io"
 
 	"github.com/golang/snappy"
 )
@@ -42,6 +43,12 @@ const (
 	RoutingTableQuery
 	RoutingTableResponse
 	PublishTx
+	Handshake
+	AnnounceMessages
+	FetchMessages
+	RequestMessage
+	ResponseMessage
+	PublishBlock
 
 	UrgentMessage = 1
 	NormalMessage = 2
@@ -59,6 +66,18 @@ func (m MessageType) String() string {
 		return "RoutingTableResponse"
 	case PublishTx:
 		return "PublishTx"
+	case Handshake:
+		return "Handshake"
+	case AnnounceMessages:
+		return "AnnounceMessages"
+	case FetchMessages:
+		return "FetchMessages"
+	case RequestMessage:
+		return "RequestMessage"
+	case ResponseMessage:
+		return "ResponseMessage"
+	case PublishBlock:
+		return "PublishBlock"
 	default:
 		return fmt.Sprintf("unknown message type: %d \n", m)
 	}
@@ -73,12 +92,17 @@ const (
 	dataLengthBegin, dataLengthEnd     = 8, 12
 	dataChecksumBegin, dataChecksumEnd = 12, 16
 	dataBegin                          = 16
+
+	// maxDataLength bounds both the compressed and the uncompressed data of a
+	// message, it's checked before any buffer of the claimed size is allocated.
+	maxDataLength = 4 * 1024 * 1024
 )
 
 var (
 	errInvalidChecksum   = errors.New("invalid data checksum")
 	errUnmatchDataLength = errors.New("unmatch data length")
 	errMessageTooShort   = errors.New("message too short")
+	errMessageTooLarge   = errors.New("message too large")
 )
 
 func (m *p2pMessage) content() []byte {
@@ -110,6 +134,15 @@ func (m *p2pMessage) rawData() []byte {
 }
 
 func (m *p2pMessage) data() ([]byte, error) {
+	// check uncompressed length before allocating
+	length, err := snappy.DecodedLen(m.rawData())
+	if err != nil {
+		return nil, err
+	}
+	if length > maxDataLength {
+		return nil, errMessageTooLarge
+	}
+
 	// uncompress data
 	data, err := snappy.Decode(nil, m.rawData())
 	if err != nil {
@@ -143,6 +176,9 @@ func parseP2PMessage(data []byte) (*p2pMessage, error) {
 	if len(data) < dataBegin {
 		return nil, errMessageTooShort
 	}
+	if len(data) > dataBegin+maxDataLength {
+		return nil, errMessageTooLarge
+	}
 
 	m := p2pMessage(data)
 
@@ -159,6 +195,24 @@ func parseP2PMessage(data []byte) (*p2pMessage, error) {
 	return &m, nil
 }
 
+// readMessage reads a message from r, maxLength bounds its data length.
+func readMessage(r io.Reader, maxLength uint32) (*p2pMessage, error) {
+	header := make([]byte, dataBegin)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header[dataLengthBegin:dataLengthEnd])
+	if length > maxLength {
+		return nil, errMessageTooLarge
+	}
+	data := make([]byte, dataBegin+length)
+	copy(data, header)
+	if _, err := io.ReadFull(r, data[dataBegin:]); err != nil {
+		return nil, err
+	}
+	return parseP2PMessage(data)
+}
+
 // IncomingMessage is the struct of message sent via the stream
 type IncomingMessage struct {
 	from PeerID
@@ -1,6 +1,7 @@
 package p2p
 
 import (
+	"bytes"
 	"encoding/binary"
 	"hash/crc32"
 	"testing"
@@ -74,3 +75,34 @@ func TestP2PMessageParse(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, m, newM)
 }
+
+func TestP2PMessageTooLarge(t *testing.T) {
+	m := newP2PMessage(testChainID, testMessageType, testVerion, testData)
+	content := append(m.content(), make([]byte, maxDataLength)...)
+	_, err := parseP2PMessage(content)
+	assert.Equal(t, errMessageTooLarge, err)
+
+	// data which decompresses to more than the limit is rejected before decoding.
+	m = newP2PMessage(testChainID, testMessageType, testVerion, make([]byte, maxDataLength+1))
+	assert.True(t, int(m.dataLength()) < maxDataLength)
+	_, err = m.data()
+	assert.Equal(t, errMessageTooLarge, err)
+}
+
+func TestReadMessage(t *testing.T) {
+	m := newP2PMessage(testChainID, testMessageType, testVerion, testData)
+	other := newP2PMessage(testChainID, Pong, testVerion, nil)
+	r := bytes.NewReader(append(m.content(), other.content()...))
+
+	newM, err := readMessage(r, maxDataLength)
+	assert.Nil(t, err)
+	assert.Equal(t, m, newM)
+	newM, err = readMessage(r, maxDataLength)
+	assert.Nil(t, err)
+	assert.Equal(t, other, newM)
+
+	_, err = readMessage(bytes.NewReader(m.content()), m.dataLength()-1)
+	assert.Equal(t, errMessageTooLarge, err)
+	_, err = readMessage(bytes.NewReader(m.content()[:dataBegin+1]), maxDataLength)
+	assert.NotNil(t, err)
+}
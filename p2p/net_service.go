@@ -7,9 +7,11 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/libp2p/go-libp2p"
 
+	"github.com/ldmtam/tam-chain/abstraction"
 	"github.com/ldmtam/tam-chain/common"
 
 	crypto "github.com/libp2p/go-libp2p-crypto"
@@ -39,8 +41,9 @@ type NetService struct {
 	config      *common.P2PConfig
 }
 
-// NewNetService returns a NetService instance
-func NewNetService(config *common.P2PConfig) (*NetService, error) {
+// NewNetService returns a NetService instance, the genesis and head of chain
+// are exchanged in handshakes with peers.
+func NewNetService(config *common.P2PConfig, chain Chain) (*NetService, error) {
 	ns := &NetService{
 		config: config,
 	}
@@ -70,7 +73,19 @@ func NewNetService(config *common.P2PConfig) (*NetService, error) {
 	}
 	ns.host = host
 
-	ns.peerManager = NewPeerManager(host, config)
+	ns.peerManager = NewPeerManager(host, config, chain)
+	switch config.Transport {
+	case "", common.TransportCustom:
+	case common.TransportGossipSub:
+		ns.peerManager.pubsub, err = newPubsubTransport(ns.peerManager)
+		if err != nil {
+			log.Error("failed to create gossipsub.", "err", err)
+			return nil, err
+		}
+	default:
+		return nil, errUnknownTransport
+	}
+	host.SetStreamHandler(protocolID, ns.peerManager.HandleStream)
 
 	return ns, nil
 }
@@ -91,12 +106,7 @@ func (ns *NetService) createHost(pk crypto.PrivKey, listenAddr string) (host.Hos
 		libp2p.ListenAddrStrings(fmt.Sprintf("/ip4/%s/tcp/%d", tcpAddr.IP, tcpAddr.Port)),
 	}
 
-	h, err := libp2p.New(context.Background(), opts...)
-	if err != nil {
-		return nil, err
-	}
-	h.SetStreamHandler(protocolID, nil)
-	return h, nil
+	return libp2p.New(context.Background(), opts...)
 }
 
 // Start starts the job.
@@ -111,5 +121,155 @@ func (ns *NetService) Start() error {
 
 // Stop stops the job.
 func (ns *NetService) Stop() {
+	ns.host.RemoveStreamHandler(protocolID)
+	ns.peerManager.Stop()
+	ns.host.Close()
 	log.Info("Net service stopped")
 }
+
+// Broadcast floods data to all neighbors which do not have it yet.
+func (ns *NetService) Broadcast(data []byte, typ abstraction.MessageType, mp abstraction.MessagePriority) {
+	ns.peerManager.Broadcast(data, MessageType(typ), MessagePriority(mp))
+}
+
+// BroadcastFanout pushes data to a random sqrt(N) of the neighbors and
+// announces its hash to the others.
+func (ns *NetService) BroadcastFanout(data []byte, typ abstraction.MessageType, mp abstraction.MessagePriority) {
+	ns.peerManager.BroadcastFanout(data, MessageType(typ), MessagePriority(mp))
+}
+
+// Publish sends data to the network by the broadcast strategy of typ.
+func (ns *NetService) Publish(data []byte, typ abstraction.MessageType) {
+	ns.peerManager.Publish(data, MessageType(typ))
+}
+
+// SetBroadcastStrategy sets how messages of typ are published and relayed.
+func (ns *NetService) SetBroadcastStrategy(typ abstraction.MessageType, s abstraction.BroadcastStrategy) {
+	ns.peerManager.SetBroadcastStrategy(MessageType(typ), BroadcastStrategy{
+		Fanout:   s.Fanout,
+		Priority: MessagePriority(s.Priority),
+	})
+}
+
+// SendToPeer sends data to the neighbor of id.
+func (ns *NetService) SendToPeer(id string, data []byte, typ abstraction.MessageType, mp abstraction.MessagePriority) error {
+	pid, err := peer.IDB58Decode(id)
+	if err != nil {
+		return err
+	}
+	return ns.peerManager.SendToPeer(pid, data, MessageType(typ), MessagePriority(mp))
+}
+
+// Register returns a channel of incoming messages of types for the subscriber id.
+func (ns *NetService) Register(id string, types ...abstraction.MessageType) chan abstraction.IncomingMessage {
+	msgTypes := make([]MessageType, len(types))
+	for i, typ := range types {
+		msgTypes[i] = MessageType(typ)
+	}
+	in := ns.peerManager.Register(id, msgTypes...)
+
+	out := make(chan abstraction.IncomingMessage, msgChanSize)
+	go func() {
+		for {
+			select {
+			case msg := <-in:
+				select {
+				case out <- abstraction.IncomingMessage{From: msg.from.Pretty(), Type: abstraction.MessageType(msg.typ), Data: msg.data}:
+				case <-ns.peerManager.quitCh:
+					return
+				}
+			case <-ns.peerManager.quitCh:
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// SetValidator sets the validator of messages of typ.
+func (ns *NetService) SetValidator(typ abstraction.MessageType, v abstraction.MessageValidator) {
+	ns.peerManager.SetValidator(MessageType(typ), func(from peer.ID, data []byte) error {
+		return v(from.Pretty(), data)
+	})
+}
+
+// Request sends a request of typ to the neighbor of id and waits for the response data.
+func (ns *NetService) Request(ctx context.Context, id string, typ abstraction.RequestType, data []byte) ([]byte, error) {
+	pid, err := peer.IDB58Decode(id)
+	if err != nil {
+		return nil, err
+	}
+	return ns.peerManager.Request(ctx, pid, RequestType(typ), data)
+}
+
+// HandleRequest sets the handler of requests of typ.
+func (ns *NetService) HandleRequest(typ abstraction.RequestType, h abstraction.RequestHandler) {
+	ns.peerManager.HandleRequest(RequestType(typ), func(from peer.ID, data []byte) ([]byte, error) {
+		return h(from.Pretty(), data)
+	})
+}
+
+// Peers returns the neighbors of the node.
+func (ns *NetService) Peers() []*abstraction.PeerInfo {
+	neighbors := ns.peerManager.Neighbors()
+	peers := make([]*abstraction.PeerInfo, len(neighbors))
+	for i, p := range neighbors {
+		status := p.Status()
+		peers[i] = &abstraction.PeerInfo{
+			ID:         p.ID().Pretty(),
+			Addr:       p.Addr().String(),
+			Version:    status.Version,
+			HeadHeight: status.HeadHeight,
+			Latency:    p.Latency(),
+			LastSeen:   p.LastSeen(),
+			Score:      ns.peerManager.Score(p.ID()),
+			BytesIn:    p.BytesIn(),
+			BytesOut:   p.BytesOut(),
+		}
+	}
+	return peers
+}
+
+// Bandwidth returns the traffic by message type.
+func (ns *NetService) Bandwidth() []*abstraction.BandwidthStats {
+	var stats []*abstraction.BandwidthStats
+	for typ, bytes := range ns.peerManager.Bandwidth() {
+		stats = append(stats, &abstraction.BandwidthStats{
+			Type: typ.String(),
+			In:   bytes[inbound],
+			Out:  bytes[outbound],
+		})
+	}
+	return stats
+}
+
+// BannedPeers returns the peers which are banned now.
+func (ns *NetService) BannedPeers() []*abstraction.BannedPeer {
+	var bans []*abstraction.BannedPeer
+	for pid, until := range ns.peerManager.BannedPeers() {
+		bans = append(bans, &abstraction.BannedPeer{ID: pid.Pretty(), Until: until})
+	}
+	return bans
+}
+
+// Ban disconnects the peer of id and bans it for duration d.
+func (ns *NetService) Ban(id string, d time.Duration) error {
+	pid, err := peer.IDB58Decode(id)
+	if err != nil {
+		return err
+	}
+	ns.peerManager.Ban(pid, d)
+	return nil
+}
+
+// Unban lifts the ban of the peer of id.
+func (ns *NetService) Unban(id string) error {
+	pid, err := peer.IDB58Decode(id)
+	if err != nil {
+		return err
+	}
+	if !ns.peerManager.Unban(pid) {
+		return ErrPeerNotBanned
+	}
+	return nil
+}
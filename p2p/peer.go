@@ -4,22 +4,25 @@ import (
 	"context"
 	"encoding/binary"
 	"errors"
-	"io"
+	"math/rand"
 	"sync"
 	"time"
 
 	"github.com/willf/bloom"
 
 	log "github.com/inconshreveable/log15"
+	"github.com/ldmtam/tam-chain/common"
 	libnet "github.com/libp2p/go-libp2p-net"
 	"github.com/libp2p/go-libp2p-peer"
 	"github.com/multiformats/go-multiaddr"
+	"github.com/uber-go/atomic"
 )
 
 // errors
 var (
 	ErrStreamCountExceed  = errors.New("stream count exceed")
 	ErrMessageChannelFull = errors.New("message channel is full")
+	errPeerStopped        = errors.New("peer is stopped")
 )
 
 const (
@@ -28,6 +31,15 @@ const (
 
 	msgChanSize    = 1024
 	maxStreamCount = 4
+
+	// writeTimeout is the base deadline of a write, with a second per minWriteRate bytes.
+	writeTimeout = 5 * time.Second
+	minWriteRate = 5 * 1024
+
+	pingInterval = 15 * time.Second
+	// idleTimeout disconnects peers we have not received any message from,
+	// pings make sure live peers send something.
+	idleTimeout = 4 * pingInterval
 )
 
 // Peer represents a neighbor which we connect directly
@@ -42,9 +54,10 @@ type Peer struct {
 	conn        libnet.Conn
 	peerManager *PeerManager
 
-	// stream is a channel where we get and send data through.
+	// streams is the pool of idle streams we send data through, liveStreams
+	// are all open streams including the ones being written.
 	streams     chan libnet.Stream
-	streamCount int
+	liveStreams map[libnet.Stream]struct{}
 	streamMutex sync.Mutex
 
 	recentMsg      *bloom.BloomFilter
@@ -55,21 +68,49 @@ type Peer struct {
 	normalMsgCh chan *p2pMessage
 
 	quitWriteCh chan struct{}
+	stopOnce    sync.Once
+
+	status      PeerStatus
+	statusMutex sync.RWMutex
+
+	// lastSeen is when the last message was received, in unix nanoseconds.
+	lastSeen atomic.Int64
+	// acked is set once the peer sent us a message, so it has accepted the
+	// handshake and takes new streams without handshaking them.
+	acked atomic.Bool
+	// latency is the last round trip time of a ping, in nanoseconds.
+	latency   atomic.Int64
+	pingNonce uint64
+	pingSent  time.Time
+	pingMutex sync.Mutex
+
+	limiters  [2]*rateLimiter
+	bandwidth *bandwidthCounter
+
+	// inflightRequests are our requests to the peer, servingRequests its requests to us.
+	inflightRequests atomic.Int32
+	servingRequests  atomic.Int32
 }
 
-// NewPeer returns a new instance of Peer struct
-func NewPeer(stream libnet.Stream, pm *PeerManager) *Peer {
+// NewPeer returns a new instance of Peer struct, status is the result of the
+// handshake with the peer.
+func NewPeer(stream libnet.Stream, pm *PeerManager, status *PeerStatus) *Peer {
 	peer := &Peer{
 		id:          stream.Conn().RemotePeer(),
 		addr:        stream.Conn().RemoteMultiaddr(),
 		conn:        stream.Conn(),
 		peerManager: pm,
 		streams:     make(chan libnet.Stream, maxStreamCount),
+		liveStreams: make(map[libnet.Stream]struct{}),
 		recentMsg:   bloom.NewWithEstimates(bloomMaxItemCount, bloomErrRate),
 		urgentMsgCh: make(chan *p2pMessage, msgChanSize),
 		normalMsgCh: make(chan *p2pMessage, msgChanSize),
 		quitWriteCh: make(chan struct{}),
+		status:      *status,
+		limiters:    [2]*rateLimiter{newRateLimiter(), newRateLimiter()},
+		bandwidth:   newBandwidthCounter(),
 	}
+	peer.lastSeen.Store(time.Now().UnixNano())
 	peer.AddStream(stream)
 	return peer
 }
@@ -79,6 +120,86 @@ func (p *Peer) Start() {
 	log.Info("Peer is started.", "id", p.id.Pretty())
 
 	go p.writeLoop()
+	go p.pingLoop()
+}
+
+// Stop stops the write loop and closes the connection, which ends the read loops.
+func (p *Peer) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.quitWriteCh)
+		p.conn.Close()
+	})
+}
+
+// ID returns the peer id.
+func (p *Peer) ID() peer.ID {
+	return p.id
+}
+
+// Status returns the status of the peer.
+func (p *Peer) Status() PeerStatus {
+	p.statusMutex.RLock()
+	defer p.statusMutex.RUnlock()
+
+	return p.status
+}
+
+// SetHead updates the head of the peer, e.g. when it announces a new block.
+func (p *Peer) SetHead(height uint64, hash common.Hash) {
+	p.statusMutex.Lock()
+	defer p.statusMutex.Unlock()
+
+	p.status.HeadHeight = height
+	p.status.HeadHash = hash
+}
+
+// Addr returns the remote address of the peer.
+func (p *Peer) Addr() multiaddr.Multiaddr {
+	return p.addr
+}
+
+// Latency returns the last measured round trip time, 0 if it's not measured yet.
+func (p *Peer) Latency() time.Duration {
+	return time.Duration(p.latency.Load())
+}
+
+// LastSeen returns when the last message of the peer was received.
+func (p *Peer) LastSeen() time.Time {
+	return time.Unix(0, p.lastSeen.Load())
+}
+
+// BytesIn returns the bytes received from the peer.
+func (p *Peer) BytesIn() uint64 {
+	return p.bandwidth.total(inbound)
+}
+
+// BytesOut returns the bytes sent to the peer.
+func (p *Peer) BytesOut() uint64 {
+	return p.bandwidth.total(outbound)
+}
+
+// waitBandwidth waits until n bytes in direction are under the limits of the
+// peer and the global caps, it returns false if the peer is stopped first.
+func (p *Peer) waitBandwidth(direction int, n int) bool {
+	global := p.peerManager.inboundBandwidth
+	if direction == outbound {
+		global = p.peerManager.outboundBandwidth
+	}
+	return p.limiters[direction].bytes.wait(n, p.quitWriteCh) && global.wait(n, p.quitWriteCh)
+}
+
+// countBytes records n bytes of a message of typ in direction.
+func (p *Peer) countBytes(direction int, typ MessageType, n int) {
+	p.bandwidth.add(direction, typ, n)
+	p.peerManager.bandwidth.add(direction, typ, n)
+}
+
+// version returns the protocol version negotiated with the peer.
+func (p *Peer) version() uint16 {
+	p.statusMutex.RLock()
+	defer p.statusMutex.RUnlock()
+
+	return p.status.Version
 }
 
 // AddStream tries to add a Stream in stream pool.
@@ -86,29 +207,54 @@ func (p *Peer) AddStream(stream libnet.Stream) error {
 	p.streamMutex.Lock()
 	defer p.streamMutex.Unlock()
 
-	if p.streamCount > maxStreamCount {
+	if len(p.liveStreams) >= maxStreamCount {
 		return ErrStreamCountExceed
 	}
+	p.liveStreams[stream] = struct{}{}
 	p.streams <- stream
-	p.streamCount++
 	go p.readLoop(stream)
 	return nil
 }
 
-// CloseStream closes a stream and decrease the stream count.CloseStream
-// only closes for writing, reading will works
+// CloseStream closes a stream and removes it from the stream pool. CloseStream
+// only closes for writing, reading will works. Closing a closed stream is a no-op.
 func (p *Peer) CloseStream(stream libnet.Stream) {
 	p.streamMutex.Lock()
 	defer p.streamMutex.Unlock()
 
+	if _, ok := p.liveStreams[stream]; !ok {
+		return
+	}
 	stream.Close()
-	p.streamCount--
+	delete(p.liveStreams, stream)
+
+	// drop the stream if it's idle in the pool, so it's never written again.
+	for n := len(p.streams); n > 0; n-- {
+		select {
+		case s := <-p.streams:
+			if s != stream {
+				p.streams <- s
+			}
+		default:
+			return
+		}
+	}
+}
+
+// putStream returns a stream to the pool unless it was closed meanwhile.
+func (p *Peer) putStream(stream libnet.Stream) {
+	p.streamMutex.Lock()
+	defer p.streamMutex.Unlock()
+
+	if _, ok := p.liveStreams[stream]; ok {
+		p.streams <- stream
+	}
 }
 
 func (p *Peer) newStream() (libnet.Stream, error) {
 	p.streamMutex.Lock()
 	defer p.streamMutex.Unlock()
-	if p.streamCount > maxStreamCount {
+	if len(p.liveStreams) >= maxStreamCount {
 		return nil, ErrStreamCountExceed
 	}
 	stream, err := p.peerManager.host.NewStream(context.Background(), p.id, protocolID)
@@ -116,7 +262,7 @@ func (p *Peer) newStream() (libnet.Stream, error) {
 		log.Error("Creating stream failed.", "pid", p.id.Pretty(), "err", err)
 		return nil, err
 	}
-	p.streamCount++
+	p.liveStreams[stream] = struct{}{}
 	go p.readLoop(stream)
 	return stream, nil
 }
@@ -126,25 +272,39 @@ func (p *Peer) getStream() (libnet.Stream, error) {
 	case stream := <-p.streams:
 		return stream, nil
 	default:
+		// until the peer has accepted the handshake, a new stream would be
+		// taken for another handshake, so wait for the handshake stream.
+		if !p.acked.Load() {
+			break
+		}
 		stream, err := p.newStream()
 		if err == ErrStreamCountExceed {
 			break
 		}
 		return stream, err
 	}
-	return <-p.streams, nil
+	select {
+	case stream := <-p.streams:
+		return stream, nil
+	case <-p.quitWriteCh:
+		return nil, errPeerStopped
+	}
 }
 
 func (p *Peer) write(m *p2pMessage) error {
+	if !p.waitBandwidth(outbound, len(m.content())) {
+		return errPeerStopped
+	}
+
 	stream, err := p.getStream()
 	// if getStream fails, the TCP connection may be broken and we should stop the peer.
 	if err != nil {
 		log.Error("Get stream fails.", "err", err)
+		p.peerManager.RemoveNeighbor(p.id)
 		return err
 	}
 
-	// 5kB/s
-	deadline := time.Now().Add(time.Duration(len(m.content())/1024/5+1) * time.Second)
+	deadline := time.Now().Add(writeTimeout + time.Duration(len(m.content())/minWriteRate)*time.Second)
 	if err = stream.SetWriteDeadline(deadline); err != nil {
 		log.Warn("Write message failed.", "err", err)
 		p.CloseStream(stream)
@@ -157,8 +317,9 @@ func (p *Peer) write(m *p2pMessage) error {
 		p.CloseStream(stream)
 		return err
 	}
+	p.countBytes(outbound, m.messageType(), len(m.content()))
 
-	p.streams <- stream
+	p.putStream(stream)
 	return nil
 }
 
@@ -187,44 +348,67 @@ func (p *Peer) writeLoop() {
 	}
 }
 
+// readLoop reads messages from a stream until it fails, the stream is closed then.
 func (p *Peer) readLoop(stream libnet.Stream) {
-	header := make([]byte, dataBegin)
+	defer p.CloseStream(stream)
+
 	for {
-		_, err := io.ReadFull(stream, header)
-		if err != nil {
-			log.Warn("Read header failed", "err", err)
+		msg, err := readMessage(stream, maxDataLength)
+		if err == errMessageTooLarge || err == errInvalidChecksum || err == errUnmatchDataLength {
+			log.Warn("Read invalid message.", "err", err)
+			p.peerManager.AdjustScore(p.id, PenaltyProtocolViolation)
+			p.peerManager.RemoveNeighbor(p.id)
 			return
 		}
-		chainID := binary.BigEndian.Uint32(header[chainIDBegin:chainIDEnd])
-		if chainID != p.peerManager.config.ChainID {
-			log.Warn("Mismatched chainID.", "chainID", chainID)
+		if err != nil {
+			log.Warn("Read message failed", "err", err)
 			return
 		}
-		version := binary.BigEndian.Uint16(header[versionBegin:versionEnd])
-		if version != p.peerManager.config.Version {
-			log.Warn("Mismatched version.", "version", version)
+		if msg.chainID() != p.peerManager.config.ChainID {
+			log.Warn("Mismatched chainID.", "chainID", msg.chainID())
+			p.peerManager.AdjustScore(p.id, PenaltyWrongChain)
+			p.peerManager.RemoveNeighbor(p.id)
 			return
 		}
-		length := binary.BigEndian.Uint32(header[dataLengthBegin:dataLengthEnd])
-		data := make([]byte, dataBegin+length)
-		_, err = io.ReadFull(stream, data[dataBegin:])
-		if err != nil {
-			log.Warn("Read message failed", "err", err)
+		if msg.version() != p.version() {
+			log.Warn("Mismatched version.", "version", msg.version())
+			p.peerManager.AdjustScore(p.id, PenaltyProtocolViolation)
+			p.peerManager.RemoveNeighbor(p.id)
 			return
 		}
-		copy(data[0:dataBegin], header)
-		msg, err := parseP2PMessage(data)
-		if err != nil {
-			log.Error("Parse p2pmessage failed", "err", err)
+		p.lastSeen.Store(time.Now().UnixNano())
+		p.acked.Store(true)
+
+		if !p.waitBandwidth(inbound, len(msg.content())) {
 			return
 		}
+		p.countBytes(inbound, msg.messageType(), len(msg.content()))
+		if !p.limiters[inbound].allowMessage(msg.messageType()) {
+			log.Debug("Inbound message rate limit exceeded.", "pid", p.id.Pretty(), "type", msg.messageType())
+			p.peerManager.AdjustScore(p.id, PenaltyRateLimit)
+			continue
+		}
+		if gossipTypes[msg.messageType()] {
+			p.recordMessage(msg.hash())
+		}
 
 		p.handleMessage(msg)
 	}
 }
 
-// SendMessage puts message into corresponding channel.
+// SendMessage puts message into corresponding channel. If deduplicate is
+// true, the message is skipped if the peer has it already.
 func (p *Peer) SendMessage(msg *p2pMessage, mp MessagePriority, deduplicate bool) error {
+	if deduplicate {
+		hash := msg.hash()
+		if p.hasMessage(hash) {
+			return nil
+		}
+		p.recordMessage(hash)
+	}
+	if !p.limiters[outbound].allowMessage(msg.messageType()) {
+		return ErrRateLimited
+	}
 
 	ch := p.urgentMsgCh
 	if mp == NormalMessage {
@@ -240,6 +424,77 @@ func (p *Peer) SendMessage(msg *p2pMessage, mp MessagePriority, deduplicate bool
 }
 
 func (p *Peer) handleMessage(msg *p2pMessage) error {
+	switch msg.messageType() {
+	case Ping:
+		data, err := msg.data()
+		if err != nil || len(data) != 8 {
+			p.peerManager.AdjustScore(p.id, PenaltyProtocolViolation)
+			return err
+		}
+		return p.peerManager.sendTo(p, Pong, data, UrgentMessage)
+	case Pong:
+		data, err := msg.data()
+		if err != nil {
+			return err
+		}
+		p.handlePong(data)
+		return nil
+	}
 	p.peerManager.HandleMessage(msg, p.id)
 	return nil
 }
+
+// pingLoop pings the peer every pingInterval and disconnects it once it's idle for idleTimeout.
+func (p *Peer) pingLoop() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	// the first pong acknowledges the handshake.
+	p.ping()
+	for {
+		select {
+		case <-p.quitWriteCh:
+			return
+		case <-ticker.C:
+			if time.Since(p.LastSeen()) > idleTimeout {
+				log.Info("Peer is idle.", "pid", p.id.Pretty(), "addr", p.addr)
+				p.peerManager.RemoveNeighbor(p.id)
+				return
+			}
+			p.ping()
+		}
+	}
+}
+
+// ping sends a random nonce, the peer echoes it in a pong. Only the latest ping is tracked.
+func (p *Peer) ping() {
+	data := make([]byte, 8)
+	nonce := rand.Uint64()
+	binary.BigEndian.PutUint64(data, nonce)
+
+	p.pingMutex.Lock()
+	p.pingNonce = nonce
+	p.pingSent = time.Now()
+	p.pingMutex.Unlock()
+
+	if err := p.peerManager.sendTo(p, Ping, data, UrgentMessage); err != nil {
+		log.Debug("Sending ping failed.", "pid", p.id.Pretty(), "err", err)
+	}
+}
+
+// handlePong records the round trip time of the latest ping.
+func (p *Peer) handlePong(data []byte) {
+	if len(data) != 8 {
+		return
+	}
+	p.pingMutex.Lock()
+	defer p.pingMutex.Unlock()
+
+	if p.pingSent.IsZero() || binary.BigEndian.Uint64(data) != p.pingNonce {
+		return
+	}
+	rtt := time.Since(p.pingSent)
+	p.pingSent = time.Time{}
+	p.latency.Store(int64(rtt))
+	p.peerManager.peerStore.RecordLatency(p.id, rtt)
+}
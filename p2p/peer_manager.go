@@ -2,6 +2,8 @@ package p2p
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -16,6 +18,7 @@ import (
 
 	"github.com/libp2p/go-libp2p-host"
 	kbucket "github.com/libp2p/go-libp2p-kbucket"
+	libnet "github.com/libp2p/go-libp2p-net"
 	peer "github.com/libp2p/go-libp2p-peer"
 	peerstore "github.com/libp2p/go-libp2p-peerstore"
 	"github.com/uber-go/atomic"
@@ -24,6 +27,13 @@ import (
 var (
 	dumpRoutingTableInterval = 2 * time.Minute
 	syncRoutingTableInterval = 30 * time.Second
+	dialInterval             = 10 * time.Second
+	dialTimeout              = 10 * time.Second
+)
+
+// errors
+var (
+	ErrNeighborLimit = errors.New("neighbor count reaches the limit")
 )
 
 const (
@@ -40,46 +50,373 @@ const (
 type PeerManager struct {
 	neighbors     *sync.Map // map[peer.ID]*Peer
 	neighborCount int
+	// neighborMutex keeps neighborCount in line with neighbors.
+	neighborMutex sync.Mutex
+	notifiee      *libnet.NotifyBundle
 
 	subs   *sync.Map // map[MessageType]map[string]chan IncomingMessage
 	quitCh chan struct{}
 
 	host           host.Host
 	config         *common.P2PConfig
+	chain          Chain
 	routingTable   *kbucket.RoutingTable
 	peerStore      peerstore.Peerstore
 	lastUpdateTime atomic.Int64
+	discovery      discovery
+	reputation     *reputation
+	banFileMutex   sync.Mutex
+
+	// inboundBandwidth and outboundBandwidth are the global caps, nil if unlimited.
+	inboundBandwidth  *tokenBucket
+	outboundBandwidth *tokenBucket
+	bandwidth         *bandwidthCounter
+
+	seen     *seenCache
+	messages *messageCache
+	requests *requests
+
+	validators sync.Map // map[MessageType]Validator
+	strategies sync.Map // map[MessageType]BroadcastStrategy
+	pubsub     *pubsubTransport
 
 	wg *sync.WaitGroup
 }
 
 // NewPeerManager returns a new instance of PeerManager struct.
-func NewPeerManager(host host.Host, config *common.P2PConfig) *PeerManager {
+func NewPeerManager(host host.Host, config *common.P2PConfig, chain Chain) *PeerManager {
 	routingTable := kbucket.NewRoutingTable(bucketSize, kbucket.ConvertPeerID(host.ID()), time.Second, host.Peerstore())
-	return &PeerManager{
+	pm := &PeerManager{
 		neighbors:    new(sync.Map),
 		subs:         new(sync.Map),
 		quitCh:       make(chan struct{}),
 		routingTable: routingTable,
 		host:         host,
 		config:       config,
+		chain:        chain,
 		peerStore:    host.Peerstore(),
+		reputation:   newReputation(),
 		wg:           new(sync.WaitGroup),
+
+		inboundBandwidth:  newTokenBucket(float64(config.MaxInboundRate), float64(config.MaxInboundRate)),
+		outboundBandwidth: newTokenBucket(float64(config.MaxOutboundRate), float64(config.MaxOutboundRate)),
+		bandwidth:         newBandwidthCounter(),
+		seen:              newSeenCache(),
+		messages:          newMessageCache(),
+		requests:          newRequests(),
 	}
+	pm.notifiee = &libnet.NotifyBundle{DisconnectedF: pm.onDisconnected}
+	return pm
 }
 
 // Start starts peer manager's jobs.
 func (pm *PeerManager) Start() {
+	pm.host.Network().Notify(pm.notifiee)
+	pm.loadBans()
 	pm.parseSeeds()
 	pm.loadRoutingTable()
 
+	pm.wg.Add(3)
 	go pm.dumpRoutingTableLoop()
+	go pm.dialLoop()
+	go pm.discoveryLoop()
+
+	if pm.pubsub != nil {
+		if err := pm.pubsub.start(); err != nil {
+			log.Error("Starting pubsub failed.", "err", err)
+		}
+	}
 }
 
-// Stop stops peer manager's jobs.
+// Stop stops peer manager's jobs and all neighbors.
 func (pm *PeerManager) Stop() {
+	pm.host.Network().StopNotify(pm.notifiee)
 	close(pm.quitCh)
 	pm.wg.Wait()
+	if pm.pubsub != nil {
+		pm.pubsub.stop()
+	}
+
+	pm.neighbors.Range(func(k, v interface{}) bool {
+		pm.RemoveNeighbor(k.(peer.ID))
+		return true
+	})
+}
+
+// HandleStream is the handler of inbound streams. The stream is added to the
+// Peer of the remote peer, which becomes a neighbor after a handshake if it's
+// not one yet.
+func (pm *PeerManager) HandleStream(s libnet.Stream) {
+	remotePID := s.Conn().RemotePeer()
+	if pm.IsBanned(remotePID) {
+		s.Reset()
+		return
+	}
+	if p := pm.GetNeighbor(remotePID); p != nil {
+		if err := p.AddStream(s); err != nil {
+			log.Warn("Adding inbound stream failed.", "pid", remotePID.Pretty(), "err", err)
+			s.Reset()
+		}
+		return
+	}
+
+	status, err := pm.handshake(s)
+	if err != nil {
+		log.Warn("Handshake with inbound peer failed.", "pid", remotePID.Pretty(), "err", err)
+		pm.penalizeHandshake(remotePID, err)
+		s.Reset()
+		return
+	}
+	pm.storePeer(remotePID, []multiaddr.Multiaddr{s.Conn().RemoteMultiaddr()})
+	if err := pm.AddNeighbor(s, status); err != nil {
+		log.Warn("Inbound peer is rejected.", "pid", remotePID.Pretty(), "err", err)
+		s.Reset()
+	}
+}
+
+// GetNeighbor returns the neighbor of peerID, nil if it's not a neighbor.
+func (pm *PeerManager) GetNeighbor(peerID peer.ID) *Peer {
+	v, ok := pm.neighbors.Load(peerID)
+	if !ok {
+		return nil
+	}
+	return v.(*Peer)
+}
+
+// Neighbors returns all neighbors.
+func (pm *PeerManager) Neighbors() []*Peer {
+	var neighbors []*Peer
+	pm.neighbors.Range(func(k, v interface{}) bool {
+		neighbors = append(neighbors, v.(*Peer))
+		return true
+	})
+	return neighbors
+}
+
+// NeighborCount returns the number of neighbors.
+func (pm *PeerManager) NeighborCount() int {
+	pm.neighborMutex.Lock()
+	defer pm.neighborMutex.Unlock()
+
+	return pm.neighborCount
+}
+
+// AddNeighbor adds stream s of a handshaked peer to its Peer. The Peer is
+// created with status and started if the peer is not a neighbor yet, which
+// fails if there are maxNeighborCount neighbors or the peer is banned.
+func (pm *PeerManager) AddNeighbor(s libnet.Stream, status *PeerStatus) error {
+	remotePID := s.Conn().RemotePeer()
+
+	if pm.IsBanned(remotePID) {
+		return ErrPeerBanned
+	}
+
+	pm.neighborMutex.Lock()
+	if p := pm.GetNeighbor(remotePID); p != nil {
+		pm.neighborMutex.Unlock()
+		return p.AddStream(s)
+	}
+	if pm.neighborCount >= maxNeighborCount {
+		pm.neighborMutex.Unlock()
+		return ErrNeighborLimit
+	}
+	p := NewPeer(s, pm, status)
+	pm.neighbors.Store(remotePID, p)
+	pm.neighborCount++
+	pm.neighborMutex.Unlock()
+
+	p.Start()
+	return nil
+}
+
+// RemoveNeighbor stops the neighbor of peerID and removes it from the neighbors.
+func (pm *PeerManager) RemoveNeighbor(peerID peer.ID) {
+	pm.neighborMutex.Lock()
+	v, ok := pm.neighbors.Load(peerID)
+	if ok {
+		pm.neighbors.Delete(peerID)
+		pm.neighborCount--
+	}
+	pm.neighborMutex.Unlock()
+	pm.discovery.queries.Delete(peerID)
+
+	if ok {
+		v.(*Peer).Stop()
+		pm.reputation.forget(peerID)
+	}
+}
+
+// onDisconnected removes the neighbor once its last connection is closed.
+func (pm *PeerManager) onDisconnected(n libnet.Network, conn libnet.Conn) {
+	remotePID := conn.RemotePeer()
+	if n.Connectedness(remotePID) == libnet.Connected {
+		return
+	}
+	pm.RemoveNeighbor(remotePID)
+}
+
+func (pm *PeerManager) dialLoop() {
+	defer pm.wg.Done()
+
+	pm.dialPeers()
+	for {
+		select {
+		case <-pm.quitCh:
+			return
+		case <-time.After(dialInterval):
+			pm.dialPeers()
+		}
+	}
+}
+
+// dialPeers connects to peers in the routing table until the neighbor limit is reached.
+func (pm *PeerManager) dialPeers() {
+	for _, pid := range pm.routingTable.ListPeers() {
+		if pm.NeighborCount() >= maxNeighborCount {
+			return
+		}
+		if pid == pm.host.ID() || pm.GetNeighbor(pid) != nil || pm.IsBanned(pid) {
+			continue
+		}
+		if err := pm.dial(pid); err != nil {
+			log.Debug("Dialing peer failed.", "pid", pid.Pretty(), "err", err)
+		}
+	}
+}
+
+func (pm *PeerManager) dial(pid peer.ID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+
+	stream, err := pm.host.NewStream(ctx, pid, protocolID)
+	if err != nil {
+		return err
+	}
+	status, err := pm.handshake(stream)
+	if err != nil {
+		// incompatible peers are not dialed again.
+		pm.routingTable.Remove(pid)
+		pm.penalizeHandshake(pid, err)
+		stream.Reset()
+		return err
+	}
+	if err := pm.AddNeighbor(stream, status); err != nil {
+		stream.Reset()
+		return err
+	}
+	return nil
+}
+
+// Register returns a channel of incoming messages of types for the subscriber id.
+func (pm *PeerManager) Register(id string, types ...MessageType) chan IncomingMessage {
+	ch := make(chan IncomingMessage, msgChanSize)
+	for _, typ := range types {
+		m, _ := pm.subs.LoadOrStore(typ, new(sync.Map))
+		m.(*sync.Map).Store(id, ch)
+	}
+	return ch
+}
+
+// Deregister removes the subscriber id of types.
+func (pm *PeerManager) Deregister(id string, types ...MessageType) {
+	for _, typ := range types {
+		if m, ok := pm.subs.Load(typ); ok {
+			m.(*sync.Map).Delete(id)
+		}
+	}
+}
+
+// Bandwidth returns the inbound and outbound bytes of all peers by message type.
+func (pm *PeerManager) Bandwidth() map[MessageType][2]uint64 {
+	return pm.bandwidth.byType()
+}
+
+// sendTo sends a message of typ with data to the neighbor p.
+func (pm *PeerManager) sendTo(p *Peer, typ MessageType, data []byte, mp MessagePriority) error {
+	msg := newP2PMessage(pm.config.ChainID, typ, p.version(), data)
+	return p.SendMessage(msg, mp, false)
+}
+
+// HandleMessage handles a message received from a neighbor. Routing,
+// announcement and request messages are handled by the peer manager, others
+// are validated, relayed if they are gossip and passed to the subscribers of
+// their type. Gossip seen before is dropped, and subscribers which do not keep
+// up miss messages.
+func (pm *PeerManager) HandleMessage(msg *p2pMessage, from peer.ID) {
+	if gossipTypes[msg.messageType()] && pm.seen.add(msg.hash()) {
+		return
+	}
+
+	data, err := msg.data()
+	if err != nil {
+		log.Warn("Decoding message failed.", "pid", from.Pretty(), "err", err)
+		pm.AdjustScore(from, PenaltyProtocolViolation)
+		return
+	}
+
+	switch msg.messageType() {
+	case RoutingTableQuery:
+		if err := pm.handleRoutingQuery(data, from); err != nil {
+			log.Warn("Handling routing query failed.", "pid", from.Pretty(), "err", err)
+			pm.AdjustScore(from, PenaltyInvalidData)
+		}
+		return
+	case RoutingTableResponse:
+		if err := pm.handleRoutingResponse(data, from); err != nil {
+			log.Warn("Handling routing response failed.", "pid", from.Pretty(), "err", err)
+			pm.AdjustScore(from, PenaltyInvalidData)
+			return
+		}
+		pm.AdjustScore(from, RewardUsefulData)
+		return
+	case AnnounceMessages:
+		if err := pm.handleAnnouncement(data, from); err != nil {
+			log.Warn("Handling announcement failed.", "pid", from.Pretty(), "err", err)
+			pm.AdjustScore(from, PenaltyInvalidData)
+		}
+		return
+	case FetchMessages:
+		if err := pm.handleFetchRequest(data, from); err != nil {
+			log.Warn("Handling fetch request failed.", "pid", from.Pretty(), "err", err)
+			pm.AdjustScore(from, PenaltyInvalidData)
+		}
+		return
+	case RequestMessage:
+		if err := pm.handleRequest(data, from); err != nil {
+			log.Warn("Handling request failed.", "pid", from.Pretty(), "err", err)
+			pm.AdjustScore(from, PenaltyInvalidData)
+		}
+		return
+	case ResponseMessage:
+		if err := pm.handleResponse(data, from); err != nil {
+			log.Warn("Handling response failed.", "pid", from.Pretty(), "err", err)
+			pm.AdjustScore(from, PenaltyInvalidData)
+		}
+		return
+	}
+
+	if err := pm.validate(msg.messageType(), data, from); err != nil {
+		return
+	}
+	pm.relay(msg.messageType(), data)
+	pm.dispatch(msg.messageType(), data, from)
+}
+
+// dispatch passes a message to the subscribers of its type.
+func (pm *PeerManager) dispatch(typ MessageType, data []byte, from peer.ID) {
+	m, ok := pm.subs.Load(typ)
+	if !ok {
+		return
+	}
+	in := IncomingMessage{from: from, data: data, typ: typ}
+	m.(*sync.Map).Range(func(k, v interface{}) bool {
+		select {
+		case v.(chan IncomingMessage) <- in:
+		default:
+			log.Warn("Subscriber channel is full.", "id", k, "type", typ)
+		}
+		return true
+	})
 }
 
 func (pm *PeerManager) parseSeeds() {
@@ -117,13 +454,12 @@ func (pm *PeerManager) loadRoutingTable() {
 }
 
 func (pm *PeerManager) dumpRoutingTableLoop() {
-	pm.wg.Add(1)
+	defer pm.wg.Done()
 	var lastSaveTime int64
 
 	for {
 		select {
 		case <-pm.quitCh:
-			pm.wg.Done()
 			return
 		case <-time.After(dumpRoutingTableInterval):
 			if lastSaveTime < pm.lastUpdateTime.Load() {
@@ -0,0 +1,109 @@
+package p2p
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	libnet "github.com/libp2p/go-libp2p-net"
+	"github.com/stretchr/testify/assert"
+)
+
+// testStream is a stream whose reads fail once readErr is sent.
+type testStream struct {
+	libnet.Stream
+	readErr chan error
+	closed  chan struct{}
+}
+
+func newTestStream() *testStream {
+	return &testStream{
+		readErr: make(chan error, 1),
+		closed:  make(chan struct{}),
+	}
+}
+
+func (s *testStream) Read(b []byte) (int, error) {
+	return 0, <-s.readErr
+}
+
+func (s *testStream) Close() error {
+	close(s.closed)
+	return nil
+}
+
+// readerStream is a stream which reads from a reader.
+type readerStream struct {
+	libnet.Stream
+	io.Reader
+}
+
+func (s *readerStream) Read(b []byte) (int, error) {
+	return s.Reader.Read(b)
+}
+
+func TestReadLoopClosesStream(t *testing.T) {
+	_, p := newTestPeer()
+	p.streams = make(chan libnet.Stream, maxStreamCount)
+	p.liveStreams = make(map[libnet.Stream]struct{})
+
+	dead, live := newTestStream(), newTestStream()
+	assert.Nil(t, p.AddStream(dead))
+	assert.Nil(t, p.AddStream(live))
+
+	dead.readErr <- io.EOF
+	select {
+	case <-dead.closed:
+	case <-time.After(time.Second):
+		t.Fatal("stream is not closed")
+	}
+
+	// only the live stream is left in the pool.
+	p.streamMutex.Lock()
+	assert.Equal(t, 1, len(p.liveStreams))
+	p.streamMutex.Unlock()
+	stream, err := p.getStream()
+	assert.Nil(t, err)
+	assert.Equal(t, live, stream)
+
+	// a closed stream is not returned to the pool.
+	p.CloseStream(live)
+	p.putStream(live)
+	assert.Equal(t, 0, len(p.streams))
+	live.readErr <- io.EOF
+}
+
+func TestGetStreamBeforeAck(t *testing.T) {
+	_, p := newTestPeer()
+	p.streams = make(chan libnet.Stream, maxStreamCount)
+	p.liveStreams = make(map[libnet.Stream]struct{})
+
+	handshake := newTestStream()
+	assert.Nil(t, p.AddStream(handshake))
+	stream, err := p.getStream()
+	assert.Nil(t, err)
+
+	// no stream is opened before the peer acknowledges the handshake, the
+	// write waits for the handshake stream instead.
+	got := make(chan libnet.Stream, 1)
+	go func() {
+		s, _ := p.getStream()
+		got <- s
+	}()
+	select {
+	case <-got:
+		t.Fatal("stream is returned while the handshake stream is in use")
+	case <-time.After(50 * time.Millisecond):
+	}
+	p.putStream(stream)
+	assert.Equal(t, handshake, <-got)
+	assert.False(t, p.acked.Load())
+
+	// any message from the peer acknowledges the handshake.
+	p.bandwidth, p.peerManager.bandwidth = newBandwidthCounter(), newBandwidthCounter()
+	pong := newP2PMessage(1, Pong, 1, make([]byte, 8))
+	p.readLoop(&readerStream{Reader: bytes.NewReader(pong.content())})
+	assert.True(t, p.acked.Load())
+	handshake.readErr <- io.EOF
+}
@@ -0,0 +1,160 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	log "github.com/inconshreveable/log15"
+	peer "github.com/libp2p/go-libp2p-peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+)
+
+/*
+In gossipsub mode txs and blocks are relayed over libp2p pubsub topics instead
+of PublishTx and PublishBlock messages, the other messages still use our own
+protocol. Validators run before a message is relayed in both modes, so invalid
+gossip is neither relayed nor passed to subscribers. Bandwidth of the topics
+is counted by payload, as pubsub frames messages itself.
+*/
+
+// gossipTopics are the names of the topics of message types in gossipsub mode.
+var gossipTopics = map[MessageType]string{
+	PublishTx:    "tx",
+	PublishBlock: "block",
+}
+
+// errors
+var (
+	// ErrIgnoreMessage is returned by validators for messages which cannot be
+	// verified, e.g. blocks far ahead of our head. They are dropped without
+	// penalizing the sender.
+	ErrIgnoreMessage    = errors.New("message is ignored")
+	errUnknownTransport = errors.New("unknown p2p transport")
+)
+
+// Validator verifies the data of a message received from a neighbor.
+type Validator func(from peer.ID, data []byte) error
+
+// SetValidator sets the validator of messages of typ.
+func (pm *PeerManager) SetValidator(typ MessageType, v Validator) {
+	pm.validators.Store(typ, v)
+}
+
+// validate runs the validator of typ, the sender of invalid data is penalized.
+func (pm *PeerManager) validate(typ MessageType, data []byte, from peer.ID) error {
+	v, ok := pm.validators.Load(typ)
+	if !ok {
+		return nil
+	}
+	err := v.(Validator)(from, data)
+	if err != nil && err != ErrIgnoreMessage {
+		log.Debug("Invalid message.", "pid", from.Pretty(), "type", typ, "err", err)
+		pm.AdjustScore(from, PenaltyInvalidData)
+	}
+	return err
+}
+
+// pubsubTransport relays the message types of gossipTopics over gossipsub.
+type pubsubTransport struct {
+	pm     *PeerManager
+	ps     *pubsub.PubSub
+	topics map[MessageType]string
+	subs   []*pubsub.Subscription
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newPubsubTransport(pm *PeerManager) (*pubsubTransport, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ps, err := pubsub.NewGossipSub(ctx, pm.host)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	topics := make(map[MessageType]string)
+	for typ, name := range gossipTopics {
+		topics[typ] = fmt.Sprintf("/tamchain/%d/%s", pm.config.ChainID, name)
+	}
+	return &pubsubTransport{
+		pm:     pm,
+		ps:     ps,
+		topics: topics,
+		ctx:    ctx,
+		cancel: cancel,
+	}, nil
+}
+
+// start subscribes to the topics.
+func (t *pubsubTransport) start() error {
+	for typ, topic := range t.topics {
+		if err := t.ps.RegisterTopicValidator(topic, t.validator(typ)); err != nil {
+			return err
+		}
+		sub, err := t.ps.Subscribe(topic)
+		if err != nil {
+			return err
+		}
+		t.subs = append(t.subs, sub)
+
+		t.wg.Add(1)
+		go t.readLoop(typ, sub)
+	}
+	return nil
+}
+
+func (t *pubsubTransport) stop() {
+	for _, sub := range t.subs {
+		sub.Cancel()
+	}
+	t.cancel()
+	t.wg.Wait()
+}
+
+// validator returns the pubsub validator of typ, pid is the neighbor who relayed the message.
+func (t *pubsubTransport) validator(typ MessageType) pubsub.Validator {
+	return func(ctx context.Context, pid peer.ID, msg *pubsub.Message) bool {
+		if pid == t.pm.host.ID() {
+			return true
+		}
+		if t.pm.IsBanned(pid) {
+			return false
+		}
+		return t.pm.validate(typ, msg.Data, pid) == nil
+	}
+}
+
+// readLoop passes the messages of a topic to the subscribers of typ.
+func (t *pubsubTransport) readLoop(typ MessageType, sub *pubsub.Subscription) {
+	defer t.wg.Done()
+
+	for {
+		msg, err := sub.Next(t.ctx)
+		if err != nil {
+			return
+		}
+		if msg.ReceivedFrom == t.pm.host.ID() {
+			continue
+		}
+		t.pm.bandwidth.add(inbound, typ, len(msg.Data))
+		t.pm.dispatch(typ, msg.Data, msg.ReceivedFrom)
+	}
+}
+
+// publish publishes data to the topic of typ, it returns false if typ has no topic.
+func (t *pubsubTransport) publish(typ MessageType, data []byte) bool {
+	topic, ok := t.topics[typ]
+	if !ok {
+		return false
+	}
+	if err := t.ps.Publish(topic, data); err != nil {
+		log.Warn("Publishing message failed.", "topic", topic, "err", err)
+		return true
+	}
+	t.pm.bandwidth.add(outbound, typ, len(data))
+	return true
+}
@@ -0,0 +1,35 @@
+package p2p
+
+import (
+	"errors"
+	"testing"
+
+	peer "github.com/libp2p/go-libp2p-peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	pm := &PeerManager{reputation: newReputation()}
+	pid := peer.ID("peer1")
+	errInvalid := errors.New("invalid tx")
+	pm.SetValidator(PublishTx, func(from peer.ID, data []byte) error {
+		switch string(data) {
+		case "valid":
+			return nil
+		case "future":
+			return ErrIgnoreMessage
+		}
+		return errInvalid
+	})
+
+	assert.Nil(t, pm.validate(PublishTx, []byte("valid"), pid))
+	// messages without a validator are valid.
+	assert.Nil(t, pm.validate(PublishBlock, []byte("invalid"), pid))
+	assert.Equal(t, 0, pm.Score(pid))
+
+	assert.Equal(t, ErrIgnoreMessage, pm.validate(PublishTx, []byte("future"), pid))
+	assert.Equal(t, 0, pm.Score(pid))
+
+	assert.Equal(t, errInvalid, pm.validate(PublishTx, []byte("invalid"), pid))
+	assert.Equal(t, PenaltyInvalidData, pm.Score(pid))
+}
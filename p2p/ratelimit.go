@@ -0,0 +1,216 @@
+package p2p
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+/*
+Traffic is limited by token buckets in both directions:
+  * bytes of each peer, and bytes of all peers by the global caps in config.
+    Readers and writers wait for tokens, so a fast peer is slowed down by TCP
+    back pressure instead of being dropped.
+  * messages of each type of each peer. Inbound messages over the limit are
+    dropped and the peer is penalized, outbound ones are not sent.
+*/
+
+const (
+	inbound = iota
+	outbound
+)
+
+var (
+	// peerByteRate and peerByteBurst limit the bytes per second of a peer in each direction.
+	peerByteRate  = float64(512 * 1024)
+	peerByteBurst = float64(1024 * 1024)
+
+	// messageRates limit the messages per second of a peer by type.
+	messageRates = map[MessageType]rateLimit{
+		Ping:                 {rate: 1, burst: 5},
+		Pong:                 {rate: 1, burst: 5},
+		RoutingTableQuery:    {rate: 1, burst: 5},
+		RoutingTableResponse: {rate: 1, burst: 5},
+		PublishTx:            {rate: 200, burst: 1000},
+		PublishBlock:         {rate: 2, burst: 10},
+		Handshake:            {rate: 1, burst: 1},
+	}
+	defaultMessageRate = rateLimit{rate: 100, burst: 500}
+)
+
+// errors
+var (
+	ErrRateLimited = errors.New("message rate limit exceeded")
+)
+
+type rateLimit struct {
+	rate  float64
+	burst float64
+}
+
+func messageRate(typ MessageType) rateLimit {
+	if r, ok := messageRates[typ]; ok {
+		return r
+	}
+	return defaultMessageRate
+}
+
+// tokenBucket is refilled at rate tokens per second up to burst tokens. A nil
+// bucket is unlimited.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns a full bucket, or nil if rate is 0.
+func newTokenBucket(rate, burst float64) *tokenBucket {
+	if rate == 0 {
+		return nil
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+}
+
+// allow takes n tokens if there are enough.
+func (b *tokenBucket) allow(n float64) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	if b.tokens < n {
+		return false
+	}
+	b.tokens -= n
+	return true
+}
+
+// reserve takes n tokens, which may exceed burst, and returns how long to
+// wait until they are refilled.
+func (b *tokenBucket) reserve(n float64) time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// wait takes n tokens and waits until they are refilled, it returns false if
+// quit is closed first.
+func (b *tokenBucket) wait(n int, quit <-chan struct{}) bool {
+	d := b.reserve(float64(n))
+	if d == 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-quit:
+		return false
+	}
+}
+
+// rateLimiter limits the bytes and the messages of each type of a peer in one direction.
+type rateLimiter struct {
+	bytes    *tokenBucket
+	messages map[MessageType]*tokenBucket
+	mu       sync.Mutex
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		bytes:    newTokenBucket(peerByteRate, peerByteBurst),
+		messages: make(map[MessageType]*tokenBucket),
+	}
+}
+
+// allowMessage returns true if a message of typ is under the limit of its type.
+func (l *rateLimiter) allowMessage(typ MessageType) bool {
+	l.mu.Lock()
+	b, ok := l.messages[typ]
+	if !ok {
+		r := messageRate(typ)
+		b = newTokenBucket(r.rate, r.burst)
+		l.messages[typ] = b
+	}
+	l.mu.Unlock()
+
+	return b.allow(1)
+}
+
+// bandwidthCounter counts bytes by direction and message type.
+type bandwidthCounter struct {
+	mu     sync.Mutex
+	counts [2]map[MessageType]uint64
+}
+
+func newBandwidthCounter() *bandwidthCounter {
+	return &bandwidthCounter{
+		counts: [2]map[MessageType]uint64{
+			make(map[MessageType]uint64),
+			make(map[MessageType]uint64),
+		},
+	}
+}
+
+func (c *bandwidthCounter) add(direction int, typ MessageType, n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counts[direction][typ] += uint64(n)
+}
+
+// total returns the bytes of all types in direction.
+func (c *bandwidthCounter) total(direction int) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var total uint64
+	for _, n := range c.counts[direction] {
+		total += n
+	}
+	return total
+}
+
+// byType returns the inbound and outbound bytes of each message type.
+func (c *bandwidthCounter) byType() map[MessageType][2]uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	types := make(map[MessageType][2]uint64)
+	for direction, counts := range c.counts {
+		for typ, n := range counts {
+			v := types[typ]
+			v[direction] = n
+			types[typ] = v
+		}
+	}
+	return types
+}
@@ -0,0 +1,61 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	b := newTokenBucket(10, 5)
+	for i := 0; i < 5; i++ {
+		assert.True(t, b.allow(1))
+	}
+	assert.False(t, b.allow(1))
+
+	// tokens are refilled at rate.
+	b.last = b.last.Add(-200 * time.Millisecond)
+	assert.True(t, b.allow(2))
+	assert.False(t, b.allow(1))
+
+	// more than burst can be reserved, the debt is paid over time.
+	d := b.reserve(10)
+	assert.True(t, d > 900*time.Millisecond && d <= time.Second)
+
+	// a nil bucket is unlimited.
+	var unlimited *tokenBucket
+	assert.Nil(t, newTokenBucket(0, 0))
+	assert.True(t, unlimited.allow(1000))
+	assert.True(t, unlimited.wait(1000, nil))
+
+	quit := make(chan struct{})
+	close(quit)
+	assert.False(t, b.wait(10, quit))
+}
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter()
+	r := messageRate(Ping)
+	for i := 0; i < int(r.burst); i++ {
+		assert.True(t, l.allowMessage(Ping))
+	}
+	assert.False(t, l.allowMessage(Ping))
+	// types have separate limits.
+	assert.True(t, l.allowMessage(PublishTx))
+}
+
+func TestBandwidthCounter(t *testing.T) {
+	c := newBandwidthCounter()
+	c.add(inbound, Ping, 10)
+	c.add(inbound, PublishTx, 100)
+	c.add(outbound, Ping, 20)
+	c.add(outbound, Ping, 5)
+
+	assert.Equal(t, uint64(110), c.total(inbound))
+	assert.Equal(t, uint64(25), c.total(outbound))
+	assert.Equal(t, map[MessageType][2]uint64{
+		Ping:      {10, 25},
+		PublishTx: {100, 0},
+	}, c.byType())
+}
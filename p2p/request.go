@@ -0,0 +1,195 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	log "github.com/inconshreveable/log15"
+	"github.com/ldmtam/tam-chain/p2p/pb"
+	peer "github.com/libp2p/go-libp2p-peer"
+)
+
+/*
+Requests are correlated with their responses by an id unique to the sender.
+Each request type has one handler, which runs in its own goroutine so a slow
+handler does not block the read loop of the peer. A peer has at most
+maxInflightRequests requests waiting for a response in each direction.
+*/
+
+// RequestType identifies the handler of a request.
+type RequestType uint32
+
+// RequestHandler handles a request of a neighbor and returns the response data.
+type RequestHandler func(from peer.ID, data []byte) ([]byte, error)
+
+const (
+	maxInflightRequests = 16
+	// requestTimeout is the timeout of requests whose context has no deadline.
+	requestTimeout = 10 * time.Second
+)
+
+// errors
+var (
+	ErrTooManyRequests = errors.New("too many inflight requests")
+	ErrRequestTimeout  = errors.New("request timeout")
+	errUnknownRequest  = errors.New("unknown request type")
+)
+
+type requestKey struct {
+	pid peer.ID
+	id  uint64
+}
+
+// requests tracks the requests waiting for a response and the request handlers.
+type requests struct {
+	mu       sync.Mutex
+	nextID   uint64
+	pending  map[requestKey]chan *p2ppb.Response
+	handlers sync.Map // map[RequestType]RequestHandler
+}
+
+func newRequests() *requests {
+	return &requests{
+		pending: make(map[requestKey]chan *p2ppb.Response),
+	}
+}
+
+// add returns a new request id for pid and the channel of its response.
+func (r *requests) add(pid peer.ID) (uint64, chan *p2ppb.Response) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	ch := make(chan *p2ppb.Response, 1)
+	r.pending[requestKey{pid, r.nextID}] = ch
+	return r.nextID, ch
+}
+
+func (r *requests) remove(pid peer.ID, id uint64) chan *p2ppb.Response {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := requestKey{pid, id}
+	ch := r.pending[key]
+	delete(r.pending, key)
+	return ch
+}
+
+// HandleRequest sets the handler of requests of typ.
+func (pm *PeerManager) HandleRequest(typ RequestType, h RequestHandler) {
+	pm.requests.handlers.Store(typ, h)
+}
+
+// Request sends a request of typ with data to the neighbor of peerID and
+// waits for the response data. It fails if ctx is done, or if it has no
+// deadline, after requestTimeout.
+func (pm *PeerManager) Request(ctx context.Context, peerID peer.ID, typ RequestType, data []byte) ([]byte, error) {
+	p := pm.GetNeighbor(peerID)
+	if p == nil {
+		return nil, ErrPeerNotFound
+	}
+	if p.inflightRequests.Inc() > maxInflightRequests {
+		p.inflightRequests.Dec()
+		return nil, ErrTooManyRequests
+	}
+	defer p.inflightRequests.Dec()
+
+	id, ch := pm.requests.add(peerID)
+	defer pm.requests.remove(peerID, id)
+
+	reqData, err := proto.Marshal(&p2ppb.Request{Id: id, Type: uint32(typ), Data: data})
+	if err != nil {
+		return nil, err
+	}
+	if err := pm.sendTo(p, RequestMessage, reqData, NormalMessage); err != nil {
+		return nil, err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, requestTimeout)
+		defer cancel()
+	}
+	select {
+	case resp := <-ch:
+		if resp.Error != "" {
+			return nil, errors.New(resp.Error)
+		}
+		return resp.Data, nil
+	case <-p.quitWriteCh:
+		return nil, errPeerStopped
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, ErrRequestTimeout
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// handleRequest runs the handler of a request and sends the response.
+func (pm *PeerManager) handleRequest(data []byte, from peer.ID) error {
+	req := &p2ppb.Request{}
+	if err := proto.Unmarshal(data, req); err != nil {
+		return err
+	}
+	p := pm.GetNeighbor(from)
+	if p == nil {
+		return nil
+	}
+	if p.servingRequests.Inc() > maxInflightRequests {
+		p.servingRequests.Dec()
+		return pm.respond(p, &p2ppb.Response{Id: req.Id, Error: ErrTooManyRequests.Error()})
+	}
+
+	go func() {
+		defer p.servingRequests.Dec()
+
+		resp := &p2ppb.Response{Id: req.Id}
+		respData, err := pm.callHandler(RequestType(req.Type), from, req.Data)
+		if err != nil {
+			log.Debug("Handling request failed.", "pid", from.Pretty(), "type", req.Type, "err", err)
+			resp.Error = err.Error()
+		} else {
+			resp.Data = respData
+		}
+		if err := pm.respond(p, resp); err != nil {
+			log.Debug("Sending response failed.", "pid", from.Pretty(), "err", err)
+		}
+	}()
+	return nil
+}
+
+func (pm *PeerManager) callHandler(typ RequestType, from peer.ID, data []byte) ([]byte, error) {
+	h, ok := pm.requests.handlers.Load(typ)
+	if !ok {
+		return nil, errUnknownRequest
+	}
+	return h.(RequestHandler)(from, data)
+}
+
+func (pm *PeerManager) respond(p *Peer, resp *p2ppb.Response) error {
+	data, err := proto.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return pm.sendTo(p, ResponseMessage, data, NormalMessage)
+}
+
+// handleResponse passes a response to the request waiting for it.
+func (pm *PeerManager) handleResponse(data []byte, from peer.ID) error {
+	resp := &p2ppb.Response{}
+	if err := proto.Unmarshal(data, resp); err != nil {
+		return err
+	}
+	ch := pm.requests.remove(from, resp.Id)
+	if ch == nil {
+		// the request may have timed out already.
+		log.Debug("Response without a request.", "pid", from.Pretty(), "id", resp.Id)
+		return nil
+	}
+	ch <- resp
+	return nil
+}
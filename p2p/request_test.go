@@ -0,0 +1,112 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/p2p/pb"
+	peer "github.com/libp2p/go-libp2p-peer"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestPeer returns a PeerManager with a neighbor whose outgoing messages
+// are left in its normal message channel.
+func newTestPeer() (*PeerManager, *Peer) {
+	pm := &PeerManager{
+		config:    &common.P2PConfig{ChainID: 1, Version: 1},
+		neighbors: new(sync.Map),
+		requests:  newRequests(),
+	}
+	p := &Peer{
+		id:          peer.ID("peer1"),
+		peerManager: pm,
+		urgentMsgCh: make(chan *p2pMessage, msgChanSize),
+		normalMsgCh: make(chan *p2pMessage, msgChanSize),
+		quitWriteCh: make(chan struct{}),
+		status:      PeerStatus{Version: 1},
+		limiters:    [2]*rateLimiter{newRateLimiter(), newRateLimiter()},
+	}
+	pm.neighbors.Store(p.id, p)
+	return pm, p
+}
+
+func readSent(t *testing.T, p *Peer, typ MessageType, pb proto.Message) {
+	msg := <-p.normalMsgCh
+	assert.Equal(t, typ, msg.messageType())
+	data, err := msg.data()
+	assert.Nil(t, err)
+	assert.Nil(t, proto.Unmarshal(data, pb))
+}
+
+func TestRequest(t *testing.T) {
+	pm, p := newTestPeer()
+
+	// the response is matched to the request by id.
+	go func() {
+		req := &p2ppb.Request{}
+		readSent(t, p, RequestMessage, req)
+		assert.Equal(t, uint32(7), req.Type)
+		assert.Equal(t, []byte("ping"), req.Data)
+
+		other, _ := proto.Marshal(&p2ppb.Response{Id: req.Id + 1, Data: []byte("other")})
+		pm.handleResponse(other, p.id)
+		resp, _ := proto.Marshal(&p2ppb.Response{Id: req.Id, Data: []byte("pong")})
+		pm.handleResponse(resp, p.id)
+	}()
+	data, err := pm.Request(context.Background(), p.id, 7, []byte("ping"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("pong"), data)
+
+	go func() {
+		req := &p2ppb.Request{}
+		readSent(t, p, RequestMessage, req)
+		resp, _ := proto.Marshal(&p2ppb.Response{Id: req.Id, Error: "not found"})
+		pm.handleResponse(resp, p.id)
+	}()
+	_, err = pm.Request(context.Background(), p.id, 7, nil)
+	assert.Equal(t, errors.New("not found"), err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = pm.Request(ctx, p.id, 7, nil)
+	assert.Equal(t, ErrRequestTimeout, err)
+	assert.Empty(t, pm.requests.pending)
+
+	_, err = pm.Request(context.Background(), peer.ID("peer2"), 7, nil)
+	assert.Equal(t, ErrPeerNotFound, err)
+
+	p.inflightRequests.Store(maxInflightRequests)
+	_, err = pm.Request(context.Background(), p.id, 7, nil)
+	assert.Equal(t, ErrTooManyRequests, err)
+}
+
+func TestHandleRequest(t *testing.T) {
+	pm, p := newTestPeer()
+	pm.HandleRequest(7, func(from peer.ID, data []byte) ([]byte, error) {
+		if len(data) == 0 {
+			return nil, errors.New("empty request")
+		}
+		return append(data, '!'), nil
+	})
+
+	handle := func(typ uint32, data []byte) *p2ppb.Response {
+		req, _ := proto.Marshal(&p2ppb.Request{Id: 3, Type: typ, Data: data})
+		assert.Nil(t, pm.handleRequest(req, p.id))
+		resp := &p2ppb.Response{}
+		readSent(t, p, ResponseMessage, resp)
+		assert.Equal(t, uint64(3), resp.Id)
+		return resp
+	}
+
+	assert.Equal(t, []byte("hi!"), handle(7, []byte("hi")).Data)
+	assert.Equal(t, "empty request", handle(7, nil).Error)
+	assert.Equal(t, errUnknownRequest.Error(), handle(8, nil).Error)
+
+	p.servingRequests.Store(maxInflightRequests)
+	assert.Equal(t, ErrTooManyRequests.Error(), handle(7, []byte("hi")).Error)
+}
@@ -0,0 +1,297 @@
+package p2p
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/inconshreveable/log15"
+	peer "github.com/libp2p/go-libp2p-peer"
+)
+
+/*
+Every peer has a reputation score which starts at 0. Misbehavior lowers it,
+useful data raises it up to maxScore. Once the score drops to banThreshold the
+peer is disconnected and banned for banDuration. Bans are kept in the ban list
+file under the data path so they survive restarts. Scores are kept in memory
+only and a peer starts over at 0 when its ban ends. Non-negative scores are
+forgotten when a peer disconnects, negative ones are kept so reconnecting does
+not clear them, at most maxScoredPeers of them.
+*/
+
+// Score adjustments
+const (
+	// PenaltyProtocolViolation is for messages breaking the wire protocol,
+	// e.g. bad checksums or lengths.
+	PenaltyProtocolViolation = -50
+	// PenaltyWrongChain is for peers on another chain, which are banned at once.
+	PenaltyWrongChain = banThreshold - maxScore
+	// PenaltyInvalidData is for well formed messages with invalid content,
+	// e.g. invalid txs or unsolicited responses.
+	PenaltyInvalidData = -20
+	// PenaltyRateLimit is for messages over the rate limit of their type.
+	PenaltyRateLimit = -5
+	// RewardUsefulData is for valid data we did not have yet.
+	RewardUsefulData = 1
+)
+
+const (
+	maxScore     = 100
+	banThreshold = -100
+	// maxScoredPeers is the maximum number of scores kept, the least recently
+	// adjusted one is evicted to make room for a new peer.
+	maxScoredPeers = 4096
+
+	banListFile = "ban.list"
+)
+
+var banDuration = 24 * time.Hour
+
+// errors
+var (
+	ErrPeerBanned      = errors.New("peer is banned")
+	ErrPeerNotBanned   = errors.New("peer is not banned")
+	errInvalidBanEntry = errors.New("invalid ban list entry")
+)
+
+type peerScore struct {
+	score    int
+	adjusted time.Time
+}
+
+// reputation keeps the scores and bans of peers.
+type reputation struct {
+	mu     sync.Mutex
+	scores map[peer.ID]*peerScore
+	bans   map[peer.ID]time.Time // ban end time
+}
+
+func newReputation() *reputation {
+	return &reputation{
+		scores: make(map[peer.ID]*peerScore),
+		bans:   make(map[peer.ID]time.Time),
+	}
+}
+
+// adjust adds delta to the score of pid, returns the new score and true if
+// the score reaches banThreshold.
+func (r *reputation) adjust(pid peer.ID, delta int) (int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.scores[pid]
+	if !ok {
+		if len(r.scores) >= maxScoredPeers {
+			r.evict()
+		}
+		s = &peerScore{}
+		r.scores[pid] = s
+	}
+	s.score += delta
+	if s.score > maxScore {
+		s.score = maxScore
+	}
+	s.adjusted = time.Now()
+	return s.score, s.score <= banThreshold
+}
+
+// evict removes the least recently adjusted score, r.mu must be held.
+func (r *reputation) evict() {
+	var oldest peer.ID
+	var oldestTime time.Time
+	for pid, s := range r.scores {
+		if oldestTime.IsZero() || s.adjusted.Before(oldestTime) {
+			oldest, oldestTime = pid, s.adjusted
+		}
+	}
+	delete(r.scores, oldest)
+}
+
+// forget removes the score of pid unless it's negative.
+func (r *reputation) forget(pid peer.ID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s, ok := r.scores[pid]; ok && s.score >= 0 {
+		delete(r.scores, pid)
+	}
+}
+
+func (r *reputation) score(pid peer.ID) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s, ok := r.scores[pid]; ok {
+		return s.score
+	}
+	return 0
+}
+
+// ban bans pid until the given time and resets its score.
+func (r *reputation) ban(pid peer.ID, until time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.bans[pid] = until
+	delete(r.scores, pid)
+}
+
+// unban returns false if pid is not banned.
+func (r *reputation) unban(pid peer.ID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.bans[pid]
+	delete(r.bans, pid)
+	return ok
+}
+
+// isBanned returns true if pid is banned, expired bans are removed.
+func (r *reputation) isBanned(pid peer.ID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	until, ok := r.bans[pid]
+	if !ok {
+		return false
+	}
+	if time.Now().Before(until) {
+		return true
+	}
+	delete(r.bans, pid)
+	return false
+}
+
+// banned returns the peers which are banned now with their ban end time.
+func (r *reputation) banned() map[peer.ID]time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	bans := make(map[peer.ID]time.Time, len(r.bans))
+	for pid, until := range r.bans {
+		if now.Before(until) {
+			bans[pid] = until
+		}
+	}
+	return bans
+}
+
+// save writes the current bans to path, one `<peer id> <unix end time>` per line.
+func (r *reputation) save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	file.WriteString(fmt.Sprintf("# %s\n", time.Now().String()))
+	for pid, until := range r.banned() {
+		file.WriteString(fmt.Sprintf("%s %d\n", pid.Pretty(), until.Unix()))
+	}
+	return nil
+}
+
+// load reads the bans saved in path, expired bans are skipped.
+func (r *reputation) load(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	now := time.Now()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			log.Error("Parsing ban list failed.", "err", errInvalidBanEntry)
+			continue
+		}
+		pid, err := peer.IDB58Decode(fields[0])
+		if err != nil {
+			log.Error("Parsing ban list failed.", "err", err)
+			continue
+		}
+		sec, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			log.Error("Parsing ban list failed.", "err", err)
+			continue
+		}
+		if until := time.Unix(sec, 0); now.Before(until) {
+			r.ban(pid, until)
+		}
+	}
+	return scanner.Err()
+}
+
+// AdjustScore adds delta to the score of pid, which is disconnected and banned
+// for banDuration if the score drops to the ban threshold.
+func (pm *PeerManager) AdjustScore(pid peer.ID, delta int) {
+	score, ban := pm.reputation.adjust(pid, delta)
+	if delta < 0 {
+		log.Debug("Peer is penalized.", "pid", pid.Pretty(), "delta", delta, "score", score)
+	}
+	if ban {
+		pm.Ban(pid, banDuration)
+	}
+}
+
+// Score returns the reputation score of pid.
+func (pm *PeerManager) Score(pid peer.ID) int {
+	return pm.reputation.score(pid)
+}
+
+// Ban disconnects pid and refuses any connection with it for duration d.
+func (pm *PeerManager) Ban(pid peer.ID, d time.Duration) {
+	log.Info("Peer is banned.", "pid", pid.Pretty(), "duration", d)
+	pm.reputation.ban(pid, time.Now().Add(d))
+	pm.RemoveNeighbor(pid)
+	pm.routingTable.Remove(pid)
+	pm.saveBans()
+}
+
+// Unban lifts the ban of pid, it returns false if pid is not banned.
+func (pm *PeerManager) Unban(pid peer.ID) bool {
+	if !pm.reputation.unban(pid) {
+		return false
+	}
+	log.Info("Peer is unbanned.", "pid", pid.Pretty())
+	pm.saveBans()
+	return true
+}
+
+// IsBanned returns true if pid is banned.
+func (pm *PeerManager) IsBanned(pid peer.ID) bool {
+	return pm.reputation.isBanned(pid)
+}
+
+// BannedPeers returns the banned peers with their ban end time.
+func (pm *PeerManager) BannedPeers() map[peer.ID]time.Time {
+	return pm.reputation.banned()
+}
+
+func (pm *PeerManager) loadBans() {
+	if err := pm.reputation.load(filepath.Join(pm.config.DataPath, banListFile)); err != nil && !os.IsNotExist(err) {
+		log.Error("Reading ban list file failed.", "err", err)
+	}
+}
+
+func (pm *PeerManager) saveBans() {
+	pm.banFileMutex.Lock()
+	defer pm.banFileMutex.Unlock()
+
+	if err := pm.reputation.save(filepath.Join(pm.config.DataPath, banListFile)); err != nil {
+		log.Error("Writing ban list file failed.", "err", err)
+	}
+}
@@ -0,0 +1,88 @@
+package p2p
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	peer "github.com/libp2p/go-libp2p-peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReputation(t *testing.T) {
+	r := newReputation()
+	pid := peer.ID("peer1")
+
+	// rewards are capped at maxScore.
+	for i := 0; i < maxScore+10; i++ {
+		r.adjust(pid, RewardUsefulData)
+	}
+	assert.Equal(t, maxScore, r.score(pid))
+
+	score, ban := r.adjust(pid, PenaltyInvalidData)
+	assert.Equal(t, maxScore+PenaltyInvalidData, score)
+	assert.False(t, ban)
+
+	// peers on another chain are banned whatever their score.
+	_, ban = r.adjust(pid, PenaltyWrongChain)
+	assert.True(t, ban)
+
+	r.ban(pid, time.Now().Add(time.Hour))
+	assert.True(t, r.isBanned(pid))
+	assert.Equal(t, 0, r.score(pid))
+	assert.True(t, r.unban(pid))
+	assert.False(t, r.isBanned(pid))
+	assert.False(t, r.unban(pid))
+
+	// expired bans are dropped.
+	r.ban(pid, time.Now().Add(-time.Second))
+	assert.False(t, r.isBanned(pid))
+	assert.Empty(t, r.banned())
+}
+
+func TestReputationForget(t *testing.T) {
+	r := newReputation()
+	good, bad := peer.ID("peer1"), peer.ID("peer2")
+	r.adjust(good, RewardUsefulData)
+	r.adjust(bad, PenaltyInvalidData)
+
+	// negative scores survive disconnects.
+	r.forget(good)
+	r.forget(bad)
+	assert.Equal(t, 0, r.score(good))
+	assert.Equal(t, PenaltyInvalidData, r.score(bad))
+	assert.Len(t, r.scores, 1)
+
+	// the least recently adjusted score is evicted once the map is full.
+	for i := 1; i < maxScoredPeers; i++ {
+		r.adjust(peer.ID(fmt.Sprintf("other%d", i)), PenaltyRateLimit)
+	}
+	r.adjust(bad, PenaltyRateLimit)
+	r.scores[peer.ID("other1")].adjusted = time.Now().Add(-time.Minute)
+	r.adjust(peer.ID("new"), PenaltyRateLimit)
+	assert.Len(t, r.scores, maxScoredPeers)
+	assert.Equal(t, 0, r.score(peer.ID("other1")))
+	assert.Equal(t, PenaltyInvalidData+PenaltyRateLimit, r.score(bad))
+}
+
+func TestReputationSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reputation")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, banListFile)
+
+	r := newReputation()
+	until := time.Now().Add(time.Hour).Truncate(time.Second)
+	r.ban(peer.ID("peer1"), until)
+	r.ban(peer.ID("peer2"), time.Now().Add(-time.Hour))
+	assert.Nil(t, r.save(path))
+
+	loaded := newReputation()
+	assert.Nil(t, loaded.load(path))
+	bans := loaded.banned()
+	assert.Len(t, bans, 1)
+	assert.True(t, until.Equal(bans[peer.ID("peer1")]))
+}
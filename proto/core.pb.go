@@ -20,16 +20,137 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+// TxType decides how the payload of a transaction is validated and executed.
+type TxType int32
+
+const (
+	TxType_TRANSFER           TxType = 0
+	TxType_CREATE_ACCOUNT     TxType = 1
+	TxType_ROTATE_KEY         TxType = 2
+	TxType_REGISTER_VALIDATOR TxType = 3
+	TxType_ANCHOR_DATA        TxType = 4
+	TxType_TIME_LOCK_TRANSFER TxType = 5
+	TxType_CANCEL_TIME_LOCK   TxType = 6
+	TxType_HTLC_LOCK          TxType = 7
+	TxType_HTLC_CLAIM         TxType = 8
+	TxType_HTLC_REFUND        TxType = 9
+	TxType_CONTRACT_DEPLOY    TxType = 10
+	TxType_CONTRACT_CALL      TxType = 11
+)
+
+var TxType_name = map[int32]string{
+	0:  "TRANSFER",
+	1:  "CREATE_ACCOUNT",
+	2:  "ROTATE_KEY",
+	3:  "REGISTER_VALIDATOR",
+	4:  "ANCHOR_DATA",
+	5:  "TIME_LOCK_TRANSFER",
+	6:  "CANCEL_TIME_LOCK",
+	7:  "HTLC_LOCK",
+	8:  "HTLC_CLAIM",
+	9:  "HTLC_REFUND",
+	10: "CONTRACT_DEPLOY",
+	11: "CONTRACT_CALL",
+}
+
+var TxType_value = map[string]int32{
+	"TRANSFER":           0,
+	"CREATE_ACCOUNT":     1,
+	"ROTATE_KEY":         2,
+	"REGISTER_VALIDATOR": 3,
+	"ANCHOR_DATA":        4,
+	"TIME_LOCK_TRANSFER": 5,
+	"CANCEL_TIME_LOCK":   6,
+	"HTLC_LOCK":          7,
+	"HTLC_CLAIM":         8,
+	"HTLC_REFUND":        9,
+	"CONTRACT_DEPLOY":    10,
+	"CONTRACT_CALL":      11,
+}
+
+func (x TxType) String() string {
+	return proto.EnumName(TxType_name, int32(x))
+}
+
+func (TxType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{0}
+}
+
+// HashAlgorithm is the hash function of a hashlock.
+type HashAlgorithm int32
+
+const (
+	HashAlgorithm_SHA3_256 HashAlgorithm = 0
+	HashAlgorithm_SHA256   HashAlgorithm = 1
+)
+
+var HashAlgorithm_name = map[int32]string{
+	0: "SHA3_256",
+	1: "SHA256",
+}
+
+var HashAlgorithm_value = map[string]int32{
+	"SHA3_256": 0,
+	"SHA256":   1,
+}
+
+func (x HashAlgorithm) String() string {
+	return proto.EnumName(HashAlgorithm_name, int32(x))
+}
+
+func (HashAlgorithm) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{1}
+}
+
+// HTLCStatus is the state of a hash-time-locked contract.
+type HTLCStatus int32
+
+const (
+	HTLCStatus_LOCKED   HTLCStatus = 0
+	HTLCStatus_CLAIMED  HTLCStatus = 1
+	HTLCStatus_REFUNDED HTLCStatus = 2
+)
+
+var HTLCStatus_name = map[int32]string{
+	0: "LOCKED",
+	1: "CLAIMED",
+	2: "REFUNDED",
+}
+
+var HTLCStatus_value = map[string]int32{
+	"LOCKED":   0,
+	"CLAIMED":  1,
+	"REFUNDED": 2,
+}
+
+func (x HTLCStatus) String() string {
+	return proto.EnumName(HTLCStatus_name, int32(x))
+}
+
+func (HTLCStatus) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{2}
+}
+
 type Transaction struct {
-	Hash                 []byte   `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
-	Chainid              uint32   `protobuf:"varint,2,opt,name=chainid,proto3" json:"chainid,omitempty"`
-	From                 []byte   `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
-	To                   []byte   `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
-	Value                []byte   `protobuf:"bytes,5,opt,name=value,proto3" json:"value,omitempty"`
-	Fee                  []byte   `protobuf:"bytes,6,opt,name=fee,proto3" json:"fee,omitempty"`
-	Nonce                uint64   `protobuf:"varint,7,opt,name=nonce,proto3" json:"nonce,omitempty"`
-	Timestamp            int64    `protobuf:"varint,8,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Signature            []byte   `protobuf:"bytes,9,opt,name=signature,proto3" json:"signature,omitempty"`
+	Hash      []byte `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Chainid   uint32 `protobuf:"varint,2,opt,name=chainid,proto3" json:"chainid,omitempty"`
+	From      []byte `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To        []byte `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	Value     []byte `protobuf:"bytes,5,opt,name=value,proto3" json:"value,omitempty"`
+	Fee       []byte `protobuf:"bytes,6,opt,name=fee,proto3" json:"fee,omitempty"`
+	Nonce     uint64 `protobuf:"varint,7,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	Timestamp int64  `protobuf:"varint,8,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Signature []byte `protobuf:"bytes,9,opt,name=signature,proto3" json:"signature,omitempty"`
+	PublicKey []byte `protobuf:"bytes,10,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	Memo      []byte `protobuf:"bytes,11,opt,name=memo,proto3" json:"memo,omitempty"`
+	Version   uint32 `protobuf:"varint,12,opt,name=version,proto3" json:"version,omitempty"`
+	Type      TxType `protobuf:"varint,13,opt,name=type,proto3,enum=corepb.TxType" json:"type,omitempty"`
+	Payload   []byte `protobuf:"bytes,14,opt,name=payload,proto3" json:"payload,omitempty"`
+	// bounds of the validity window, block heights below 500000000 and unix timestamps otherwise.
+	ValidAfter uint64 `protobuf:"varint,15,opt,name=valid_after,json=validAfter,proto3" json:"valid_after,omitempty"`
+	ValidUntil uint64 `protobuf:"varint,16,opt,name=valid_until,json=validUntil,proto3" json:"valid_until,omitempty"`
+	// the maximum priority tip paid to the block producer, `fee` is the maximum total fee.
+	Tip                  []byte   `protobuf:"bytes,17,opt,name=tip,proto3" json:"tip,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -123,83 +244,1187 @@ func (m *Transaction) GetSignature() []byte {
 	return nil
 }
 
-type Account struct {
-	Address              []byte   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
-	Balance              []byte   `protobuf:"bytes,2,opt,name=balance,proto3" json:"balance,omitempty"`
-	Nonce                uint64   `protobuf:"varint,3,opt,name=nonce,proto3" json:"nonce,omitempty"`
+func (m *Transaction) GetPublicKey() []byte {
+	if m != nil {
+		return m.PublicKey
+	}
+	return nil
+}
+
+func (m *Transaction) GetMemo() []byte {
+	if m != nil {
+		return m.Memo
+	}
+	return nil
+}
+
+func (m *Transaction) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *Transaction) GetType() TxType {
+	if m != nil {
+		return m.Type
+	}
+	return TxType_TRANSFER
+}
+
+func (m *Transaction) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (m *Transaction) GetValidAfter() uint64 {
+	if m != nil {
+		return m.ValidAfter
+	}
+	return 0
+}
+
+func (m *Transaction) GetValidUntil() uint64 {
+	if m != nil {
+		return m.ValidUntil
+	}
+	return 0
+}
+
+func (m *Transaction) GetTip() []byte {
+	if m != nil {
+		return m.Tip
+	}
+	return nil
+}
+
+// KeysPayload is the payload of CREATE_ACCOUNT and ROTATE_KEY txs.
+type KeysPayload struct {
+	Keys                 [][]byte `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Account) Reset()         { *m = Account{} }
-func (m *Account) String() string { return proto.CompactTextString(m) }
-func (*Account) ProtoMessage()    {}
-func (*Account) Descriptor() ([]byte, []int) {
+func (m *KeysPayload) Reset()         { *m = KeysPayload{} }
+func (m *KeysPayload) String() string { return proto.CompactTextString(m) }
+func (*KeysPayload) ProtoMessage()    {}
+func (*KeysPayload) Descriptor() ([]byte, []int) {
 	return fileDescriptor_f7e43720d1edc0fe, []int{1}
 }
 
-func (m *Account) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Account.Unmarshal(m, b)
+func (m *KeysPayload) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeysPayload.Unmarshal(m, b)
 }
-func (m *Account) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Account.Marshal(b, m, deterministic)
+func (m *KeysPayload) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeysPayload.Marshal(b, m, deterministic)
 }
-func (m *Account) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Account.Merge(m, src)
+func (m *KeysPayload) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeysPayload.Merge(m, src)
 }
-func (m *Account) XXX_Size() int {
-	return xxx_messageInfo_Account.Size(m)
+func (m *KeysPayload) XXX_Size() int {
+	return xxx_messageInfo_KeysPayload.Size(m)
 }
-func (m *Account) XXX_DiscardUnknown() {
-	xxx_messageInfo_Account.DiscardUnknown(m)
+func (m *KeysPayload) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeysPayload.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Account proto.InternalMessageInfo
+var xxx_messageInfo_KeysPayload proto.InternalMessageInfo
 
-func (m *Account) GetAddress() []byte {
+func (m *KeysPayload) GetKeys() [][]byte {
 	if m != nil {
-		return m.Address
+		return m.Keys
 	}
 	return nil
 }
 
-func (m *Account) GetBalance() []byte {
+// ValidatorPayload is the payload of REGISTER_VALIDATOR txs.
+type ValidatorPayload struct {
+	BlsPublicKey         []byte   `protobuf:"bytes,1,opt,name=bls_public_key,json=blsPublicKey,proto3" json:"bls_public_key,omitempty"`
+	ProofOfPossession    []byte   `protobuf:"bytes,2,opt,name=proof_of_possession,json=proofOfPossession,proto3" json:"proof_of_possession,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ValidatorPayload) Reset()         { *m = ValidatorPayload{} }
+func (m *ValidatorPayload) String() string { return proto.CompactTextString(m) }
+func (*ValidatorPayload) ProtoMessage()    {}
+func (*ValidatorPayload) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{2}
+}
+
+func (m *ValidatorPayload) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ValidatorPayload.Unmarshal(m, b)
+}
+func (m *ValidatorPayload) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ValidatorPayload.Marshal(b, m, deterministic)
+}
+func (m *ValidatorPayload) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ValidatorPayload.Merge(m, src)
+}
+func (m *ValidatorPayload) XXX_Size() int {
+	return xxx_messageInfo_ValidatorPayload.Size(m)
+}
+func (m *ValidatorPayload) XXX_DiscardUnknown() {
+	xxx_messageInfo_ValidatorPayload.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ValidatorPayload proto.InternalMessageInfo
+
+func (m *ValidatorPayload) GetBlsPublicKey() []byte {
 	if m != nil {
-		return m.Balance
+		return m.BlsPublicKey
 	}
 	return nil
 }
 
-func (m *Account) GetNonce() uint64 {
+func (m *ValidatorPayload) GetProofOfPossession() []byte {
 	if m != nil {
-		return m.Nonce
+		return m.ProofOfPossession
+	}
+	return nil
+}
+
+// AnchorPayload is the payload of ANCHOR_DATA txs.
+type AnchorPayload struct {
+	DataHash             []byte   `protobuf:"bytes,1,opt,name=data_hash,json=dataHash,proto3" json:"data_hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AnchorPayload) Reset()         { *m = AnchorPayload{} }
+func (m *AnchorPayload) String() string { return proto.CompactTextString(m) }
+func (*AnchorPayload) ProtoMessage()    {}
+func (*AnchorPayload) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{3}
+}
+
+func (m *AnchorPayload) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AnchorPayload.Unmarshal(m, b)
+}
+func (m *AnchorPayload) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AnchorPayload.Marshal(b, m, deterministic)
+}
+func (m *AnchorPayload) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AnchorPayload.Merge(m, src)
+}
+func (m *AnchorPayload) XXX_Size() int {
+	return xxx_messageInfo_AnchorPayload.Size(m)
+}
+func (m *AnchorPayload) XXX_DiscardUnknown() {
+	xxx_messageInfo_AnchorPayload.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AnchorPayload proto.InternalMessageInfo
+
+func (m *AnchorPayload) GetDataHash() []byte {
+	if m != nil {
+		return m.DataHash
+	}
+	return nil
+}
+
+// TimeLockPayload is the payload of TIME_LOCK_TRANSFER txs.
+type TimeLockPayload struct {
+	// block height below 500000000 and unix timestamp otherwise, like validity window bounds.
+	UnlockAt             uint64   `protobuf:"varint,1,opt,name=unlock_at,json=unlockAt,proto3" json:"unlock_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TimeLockPayload) Reset()         { *m = TimeLockPayload{} }
+func (m *TimeLockPayload) String() string { return proto.CompactTextString(m) }
+func (*TimeLockPayload) ProtoMessage()    {}
+func (*TimeLockPayload) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{4}
+}
+
+func (m *TimeLockPayload) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TimeLockPayload.Unmarshal(m, b)
+}
+func (m *TimeLockPayload) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TimeLockPayload.Marshal(b, m, deterministic)
+}
+func (m *TimeLockPayload) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TimeLockPayload.Merge(m, src)
+}
+func (m *TimeLockPayload) XXX_Size() int {
+	return xxx_messageInfo_TimeLockPayload.Size(m)
+}
+func (m *TimeLockPayload) XXX_DiscardUnknown() {
+	xxx_messageInfo_TimeLockPayload.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TimeLockPayload proto.InternalMessageInfo
+
+func (m *TimeLockPayload) GetUnlockAt() uint64 {
+	if m != nil {
+		return m.UnlockAt
+	}
+	return 0
+}
+
+// CancelTimeLockPayload is the payload of CANCEL_TIME_LOCK txs.
+type CancelTimeLockPayload struct {
+	LockId               []byte   `protobuf:"bytes,1,opt,name=lock_id,json=lockId,proto3" json:"lock_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CancelTimeLockPayload) Reset()         { *m = CancelTimeLockPayload{} }
+func (m *CancelTimeLockPayload) String() string { return proto.CompactTextString(m) }
+func (*CancelTimeLockPayload) ProtoMessage()    {}
+func (*CancelTimeLockPayload) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{5}
+}
+
+func (m *CancelTimeLockPayload) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CancelTimeLockPayload.Unmarshal(m, b)
+}
+func (m *CancelTimeLockPayload) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CancelTimeLockPayload.Marshal(b, m, deterministic)
+}
+func (m *CancelTimeLockPayload) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CancelTimeLockPayload.Merge(m, src)
+}
+func (m *CancelTimeLockPayload) XXX_Size() int {
+	return xxx_messageInfo_CancelTimeLockPayload.Size(m)
+}
+func (m *CancelTimeLockPayload) XXX_DiscardUnknown() {
+	xxx_messageInfo_CancelTimeLockPayload.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CancelTimeLockPayload proto.InternalMessageInfo
+
+func (m *CancelTimeLockPayload) GetLockId() []byte {
+	if m != nil {
+		return m.LockId
+	}
+	return nil
+}
+
+// HTLCLockPayload is the payload of HTLC_LOCK txs.
+type HTLCLockPayload struct {
+	HashAlgorithm HashAlgorithm `protobuf:"varint,1,opt,name=hash_algorithm,json=hashAlgorithm,proto3,enum=corepb.HashAlgorithm" json:"hash_algorithm,omitempty"`
+	HashLock      []byte        `protobuf:"bytes,2,opt,name=hash_lock,json=hashLock,proto3" json:"hash_lock,omitempty"`
+	// block height from which the sender can refund the contract.
+	TimeoutHeight        uint64   `protobuf:"varint,3,opt,name=timeout_height,json=timeoutHeight,proto3" json:"timeout_height,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HTLCLockPayload) Reset()         { *m = HTLCLockPayload{} }
+func (m *HTLCLockPayload) String() string { return proto.CompactTextString(m) }
+func (*HTLCLockPayload) ProtoMessage()    {}
+func (*HTLCLockPayload) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{6}
+}
+
+func (m *HTLCLockPayload) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HTLCLockPayload.Unmarshal(m, b)
+}
+func (m *HTLCLockPayload) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HTLCLockPayload.Marshal(b, m, deterministic)
+}
+func (m *HTLCLockPayload) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HTLCLockPayload.Merge(m, src)
+}
+func (m *HTLCLockPayload) XXX_Size() int {
+	return xxx_messageInfo_HTLCLockPayload.Size(m)
+}
+func (m *HTLCLockPayload) XXX_DiscardUnknown() {
+	xxx_messageInfo_HTLCLockPayload.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HTLCLockPayload proto.InternalMessageInfo
+
+func (m *HTLCLockPayload) GetHashAlgorithm() HashAlgorithm {
+	if m != nil {
+		return m.HashAlgorithm
+	}
+	return HashAlgorithm_SHA3_256
+}
+
+func (m *HTLCLockPayload) GetHashLock() []byte {
+	if m != nil {
+		return m.HashLock
+	}
+	return nil
+}
+
+func (m *HTLCLockPayload) GetTimeoutHeight() uint64 {
+	if m != nil {
+		return m.TimeoutHeight
 	}
 	return 0
 }
 
+// HTLCClaimPayload is the payload of HTLC_CLAIM txs.
+type HTLCClaimPayload struct {
+	ContractId           []byte   `protobuf:"bytes,1,opt,name=contract_id,json=contractId,proto3" json:"contract_id,omitempty"`
+	Preimage             []byte   `protobuf:"bytes,2,opt,name=preimage,proto3" json:"preimage,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HTLCClaimPayload) Reset()         { *m = HTLCClaimPayload{} }
+func (m *HTLCClaimPayload) String() string { return proto.CompactTextString(m) }
+func (*HTLCClaimPayload) ProtoMessage()    {}
+func (*HTLCClaimPayload) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{7}
+}
+
+func (m *HTLCClaimPayload) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HTLCClaimPayload.Unmarshal(m, b)
+}
+func (m *HTLCClaimPayload) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HTLCClaimPayload.Marshal(b, m, deterministic)
+}
+func (m *HTLCClaimPayload) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HTLCClaimPayload.Merge(m, src)
+}
+func (m *HTLCClaimPayload) XXX_Size() int {
+	return xxx_messageInfo_HTLCClaimPayload.Size(m)
+}
+func (m *HTLCClaimPayload) XXX_DiscardUnknown() {
+	xxx_messageInfo_HTLCClaimPayload.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HTLCClaimPayload proto.InternalMessageInfo
+
+func (m *HTLCClaimPayload) GetContractId() []byte {
+	if m != nil {
+		return m.ContractId
+	}
+	return nil
+}
+
+func (m *HTLCClaimPayload) GetPreimage() []byte {
+	if m != nil {
+		return m.Preimage
+	}
+	return nil
+}
+
+// HTLCRefundPayload is the payload of HTLC_REFUND txs.
+type HTLCRefundPayload struct {
+	ContractId           []byte   `protobuf:"bytes,1,opt,name=contract_id,json=contractId,proto3" json:"contract_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HTLCRefundPayload) Reset()         { *m = HTLCRefundPayload{} }
+func (m *HTLCRefundPayload) String() string { return proto.CompactTextString(m) }
+func (*HTLCRefundPayload) ProtoMessage()    {}
+func (*HTLCRefundPayload) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{8}
+}
+
+func (m *HTLCRefundPayload) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HTLCRefundPayload.Unmarshal(m, b)
+}
+func (m *HTLCRefundPayload) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HTLCRefundPayload.Marshal(b, m, deterministic)
+}
+func (m *HTLCRefundPayload) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HTLCRefundPayload.Merge(m, src)
+}
+func (m *HTLCRefundPayload) XXX_Size() int {
+	return xxx_messageInfo_HTLCRefundPayload.Size(m)
+}
+func (m *HTLCRefundPayload) XXX_DiscardUnknown() {
+	xxx_messageInfo_HTLCRefundPayload.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HTLCRefundPayload proto.InternalMessageInfo
+
+func (m *HTLCRefundPayload) GetContractId() []byte {
+	if m != nil {
+		return m.ContractId
+	}
+	return nil
+}
+
+// ContractDeployPayload is the payload of CONTRACT_DEPLOY txs.
+type ContractDeployPayload struct {
+	Code                 []byte   `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	GasLimit             uint64   `protobuf:"varint,2,opt,name=gas_limit,json=gasLimit,proto3" json:"gas_limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ContractDeployPayload) Reset()         { *m = ContractDeployPayload{} }
+func (m *ContractDeployPayload) String() string { return proto.CompactTextString(m) }
+func (*ContractDeployPayload) ProtoMessage()    {}
+func (*ContractDeployPayload) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{9}
+}
+
+func (m *ContractDeployPayload) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ContractDeployPayload.Unmarshal(m, b)
+}
+func (m *ContractDeployPayload) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ContractDeployPayload.Marshal(b, m, deterministic)
+}
+func (m *ContractDeployPayload) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ContractDeployPayload.Merge(m, src)
+}
+func (m *ContractDeployPayload) XXX_Size() int {
+	return xxx_messageInfo_ContractDeployPayload.Size(m)
+}
+func (m *ContractDeployPayload) XXX_DiscardUnknown() {
+	xxx_messageInfo_ContractDeployPayload.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ContractDeployPayload proto.InternalMessageInfo
+
+func (m *ContractDeployPayload) GetCode() []byte {
+	if m != nil {
+		return m.Code
+	}
+	return nil
+}
+
+func (m *ContractDeployPayload) GetGasLimit() uint64 {
+	if m != nil {
+		return m.GasLimit
+	}
+	return 0
+}
+
+// ContractCallPayload is the payload of CONTRACT_CALL txs.
+type ContractCallPayload struct {
+	Args                 [][]byte `protobuf:"bytes,1,rep,name=args,proto3" json:"args,omitempty"`
+	GasLimit             uint64   `protobuf:"varint,2,opt,name=gas_limit,json=gasLimit,proto3" json:"gas_limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ContractCallPayload) Reset()         { *m = ContractCallPayload{} }
+func (m *ContractCallPayload) String() string { return proto.CompactTextString(m) }
+func (*ContractCallPayload) ProtoMessage()    {}
+func (*ContractCallPayload) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{10}
+}
+
+func (m *ContractCallPayload) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ContractCallPayload.Unmarshal(m, b)
+}
+func (m *ContractCallPayload) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ContractCallPayload.Marshal(b, m, deterministic)
+}
+func (m *ContractCallPayload) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ContractCallPayload.Merge(m, src)
+}
+func (m *ContractCallPayload) XXX_Size() int {
+	return xxx_messageInfo_ContractCallPayload.Size(m)
+}
+func (m *ContractCallPayload) XXX_DiscardUnknown() {
+	xxx_messageInfo_ContractCallPayload.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ContractCallPayload proto.InternalMessageInfo
+
+func (m *ContractCallPayload) GetArgs() [][]byte {
+	if m != nil {
+		return m.Args
+	}
+	return nil
+}
+
+func (m *ContractCallPayload) GetGasLimit() uint64 {
+	if m != nil {
+		return m.GasLimit
+	}
+	return 0
+}
+
+type Account struct {
+	Address              []byte   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Balance              []byte   `protobuf:"bytes,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	Nonce                uint64   `protobuf:"varint,3,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	Keys                 [][]byte `protobuf:"bytes,4,rep,name=keys,proto3" json:"keys,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Account) Reset()         { *m = Account{} }
+func (m *Account) String() string { return proto.CompactTextString(m) }
+func (*Account) ProtoMessage()    {}
+func (*Account) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{11}
+}
+
+func (m *Account) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Account.Unmarshal(m, b)
+}
+func (m *Account) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Account.Marshal(b, m, deterministic)
+}
+func (m *Account) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Account.Merge(m, src)
+}
+func (m *Account) XXX_Size() int {
+	return xxx_messageInfo_Account.Size(m)
+}
+func (m *Account) XXX_DiscardUnknown() {
+	xxx_messageInfo_Account.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Account proto.InternalMessageInfo
+
+func (m *Account) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *Account) GetBalance() []byte {
+	if m != nil {
+		return m.Balance
+	}
+	return nil
+}
+
+func (m *Account) GetNonce() uint64 {
+	if m != nil {
+		return m.Nonce
+	}
+	return 0
+}
+
+func (m *Account) GetKeys() [][]byte {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+type Validator struct {
+	Address              []byte   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	BlsPublicKey         []byte   `protobuf:"bytes,2,opt,name=bls_public_key,json=blsPublicKey,proto3" json:"bls_public_key,omitempty"`
+	Stake                []byte   `protobuf:"bytes,3,opt,name=stake,proto3" json:"stake,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Validator) Reset()         { *m = Validator{} }
+func (m *Validator) String() string { return proto.CompactTextString(m) }
+func (*Validator) ProtoMessage()    {}
+func (*Validator) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{12}
+}
+
+func (m *Validator) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Validator.Unmarshal(m, b)
+}
+func (m *Validator) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Validator.Marshal(b, m, deterministic)
+}
+func (m *Validator) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Validator.Merge(m, src)
+}
+func (m *Validator) XXX_Size() int {
+	return xxx_messageInfo_Validator.Size(m)
+}
+func (m *Validator) XXX_DiscardUnknown() {
+	xxx_messageInfo_Validator.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Validator proto.InternalMessageInfo
+
+func (m *Validator) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *Validator) GetBlsPublicKey() []byte {
+	if m != nil {
+		return m.BlsPublicKey
+	}
+	return nil
+}
+
+func (m *Validator) GetStake() []byte {
+	if m != nil {
+		return m.Stake
+	}
+	return nil
+}
+
+type Anchor struct {
+	Owner                []byte   `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	DataHash             []byte   `protobuf:"bytes,2,opt,name=data_hash,json=dataHash,proto3" json:"data_hash,omitempty"`
+	Height               uint64   `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+	Timestamp            int64    `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Anchor) Reset()         { *m = Anchor{} }
+func (m *Anchor) String() string { return proto.CompactTextString(m) }
+func (*Anchor) ProtoMessage()    {}
+func (*Anchor) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{13}
+}
+
+func (m *Anchor) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Anchor.Unmarshal(m, b)
+}
+func (m *Anchor) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Anchor.Marshal(b, m, deterministic)
+}
+func (m *Anchor) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Anchor.Merge(m, src)
+}
+func (m *Anchor) XXX_Size() int {
+	return xxx_messageInfo_Anchor.Size(m)
+}
+func (m *Anchor) XXX_DiscardUnknown() {
+	xxx_messageInfo_Anchor.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Anchor proto.InternalMessageInfo
+
+func (m *Anchor) GetOwner() []byte {
+	if m != nil {
+		return m.Owner
+	}
+	return nil
+}
+
+func (m *Anchor) GetDataHash() []byte {
+	if m != nil {
+		return m.DataHash
+	}
+	return nil
+}
+
+func (m *Anchor) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *Anchor) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+// TimeLock is value escrowed from owner until it's released to recipient.
+type TimeLock struct {
+	// hash of the tx which created the time lock.
+	Id                   []byte   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Owner                []byte   `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Recipient            []byte   `protobuf:"bytes,3,opt,name=recipient,proto3" json:"recipient,omitempty"`
+	Amount               []byte   `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	UnlockAt             uint64   `protobuf:"varint,5,opt,name=unlock_at,json=unlockAt,proto3" json:"unlock_at,omitempty"`
+	Height               uint64   `protobuf:"varint,6,opt,name=height,proto3" json:"height,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TimeLock) Reset()         { *m = TimeLock{} }
+func (m *TimeLock) String() string { return proto.CompactTextString(m) }
+func (*TimeLock) ProtoMessage()    {}
+func (*TimeLock) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{14}
+}
+
+func (m *TimeLock) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TimeLock.Unmarshal(m, b)
+}
+func (m *TimeLock) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TimeLock.Marshal(b, m, deterministic)
+}
+func (m *TimeLock) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TimeLock.Merge(m, src)
+}
+func (m *TimeLock) XXX_Size() int {
+	return xxx_messageInfo_TimeLock.Size(m)
+}
+func (m *TimeLock) XXX_DiscardUnknown() {
+	xxx_messageInfo_TimeLock.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TimeLock proto.InternalMessageInfo
+
+func (m *TimeLock) GetId() []byte {
+	if m != nil {
+		return m.Id
+	}
+	return nil
+}
+
+func (m *TimeLock) GetOwner() []byte {
+	if m != nil {
+		return m.Owner
+	}
+	return nil
+}
+
+func (m *TimeLock) GetRecipient() []byte {
+	if m != nil {
+		return m.Recipient
+	}
+	return nil
+}
+
+func (m *TimeLock) GetAmount() []byte {
+	if m != nil {
+		return m.Amount
+	}
+	return nil
+}
+
+func (m *TimeLock) GetUnlockAt() uint64 {
+	if m != nil {
+		return m.UnlockAt
+	}
+	return 0
+}
+
+func (m *TimeLock) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+// TimeLockIndex is the list of pending time lock ids of an account.
+type TimeLockIndex struct {
+	Ids                  [][]byte `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TimeLockIndex) Reset()         { *m = TimeLockIndex{} }
+func (m *TimeLockIndex) String() string { return proto.CompactTextString(m) }
+func (*TimeLockIndex) ProtoMessage()    {}
+func (*TimeLockIndex) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{15}
+}
+
+func (m *TimeLockIndex) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TimeLockIndex.Unmarshal(m, b)
+}
+func (m *TimeLockIndex) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TimeLockIndex.Marshal(b, m, deterministic)
+}
+func (m *TimeLockIndex) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TimeLockIndex.Merge(m, src)
+}
+func (m *TimeLockIndex) XXX_Size() int {
+	return xxx_messageInfo_TimeLockIndex.Size(m)
+}
+func (m *TimeLockIndex) XXX_DiscardUnknown() {
+	xxx_messageInfo_TimeLockIndex.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TimeLockIndex proto.InternalMessageInfo
+
+func (m *TimeLockIndex) GetIds() [][]byte {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
+// HTLC is a hash-time-locked contract, value is paid to recipient with the
+// preimage of hash_lock before timeout_height, or refunded to sender after it.
+type HTLC struct {
+	// hash of the tx which created the contract.
+	Id            []byte        `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Sender        []byte        `protobuf:"bytes,2,opt,name=sender,proto3" json:"sender,omitempty"`
+	Recipient     []byte        `protobuf:"bytes,3,opt,name=recipient,proto3" json:"recipient,omitempty"`
+	Amount        []byte        `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	HashAlgorithm HashAlgorithm `protobuf:"varint,5,opt,name=hash_algorithm,json=hashAlgorithm,proto3,enum=corepb.HashAlgorithm" json:"hash_algorithm,omitempty"`
+	HashLock      []byte        `protobuf:"bytes,6,opt,name=hash_lock,json=hashLock,proto3" json:"hash_lock,omitempty"`
+	TimeoutHeight uint64        `protobuf:"varint,7,opt,name=timeout_height,json=timeoutHeight,proto3" json:"timeout_height,omitempty"`
+	Height        uint64        `protobuf:"varint,8,opt,name=height,proto3" json:"height,omitempty"`
+	Status        HTLCStatus    `protobuf:"varint,9,opt,name=status,proto3,enum=corepb.HTLCStatus" json:"status,omitempty"`
+	// revealed by the claim, so the counterparty of a swap can claim on the other chain.
+	Preimage             []byte   `protobuf:"bytes,10,opt,name=preimage,proto3" json:"preimage,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HTLC) Reset()         { *m = HTLC{} }
+func (m *HTLC) String() string { return proto.CompactTextString(m) }
+func (*HTLC) ProtoMessage()    {}
+func (*HTLC) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{16}
+}
+
+func (m *HTLC) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HTLC.Unmarshal(m, b)
+}
+func (m *HTLC) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HTLC.Marshal(b, m, deterministic)
+}
+func (m *HTLC) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HTLC.Merge(m, src)
+}
+func (m *HTLC) XXX_Size() int {
+	return xxx_messageInfo_HTLC.Size(m)
+}
+func (m *HTLC) XXX_DiscardUnknown() {
+	xxx_messageInfo_HTLC.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HTLC proto.InternalMessageInfo
+
+func (m *HTLC) GetId() []byte {
+	if m != nil {
+		return m.Id
+	}
+	return nil
+}
+
+func (m *HTLC) GetSender() []byte {
+	if m != nil {
+		return m.Sender
+	}
+	return nil
+}
+
+func (m *HTLC) GetRecipient() []byte {
+	if m != nil {
+		return m.Recipient
+	}
+	return nil
+}
+
+func (m *HTLC) GetAmount() []byte {
+	if m != nil {
+		return m.Amount
+	}
+	return nil
+}
+
+func (m *HTLC) GetHashAlgorithm() HashAlgorithm {
+	if m != nil {
+		return m.HashAlgorithm
+	}
+	return HashAlgorithm_SHA3_256
+}
+
+func (m *HTLC) GetHashLock() []byte {
+	if m != nil {
+		return m.HashLock
+	}
+	return nil
+}
+
+func (m *HTLC) GetTimeoutHeight() uint64 {
+	if m != nil {
+		return m.TimeoutHeight
+	}
+	return 0
+}
+
+func (m *HTLC) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *HTLC) GetStatus() HTLCStatus {
+	if m != nil {
+		return m.Status
+	}
+	return HTLCStatus_LOCKED
+}
+
+func (m *HTLC) GetPreimage() []byte {
+	if m != nil {
+		return m.Preimage
+	}
+	return nil
+}
+
+type BlockHeader struct {
+	Height     uint64 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	ParentHash []byte `protobuf:"bytes,2,opt,name=parent_hash,json=parentHash,proto3" json:"parent_hash,omitempty"`
+	Timestamp  int64  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Coinbase   []byte `protobuf:"bytes,4,opt,name=coinbase,proto3" json:"coinbase,omitempty"`
+	StateRoot  []byte `protobuf:"bytes,5,opt,name=state_root,json=stateRoot,proto3" json:"state_root,omitempty"`
+	TxRoot     []byte `protobuf:"bytes,6,opt,name=tx_root,json=txRoot,proto3" json:"tx_root,omitempty"`
+	// base fee per weight unit, it is burned.
+	BaseFee []byte `protobuf:"bytes,7,opt,name=base_fee,json=baseFee,proto3" json:"base_fee,omitempty"`
+	// total weight of txs in the block.
+	Weight uint64 `protobuf:"varint,8,opt,name=weight,proto3" json:"weight,omitempty"`
+	// bloom of addresses and topics of the logs of txs in the block.
+	LogsBloom            []byte   `protobuf:"bytes,9,opt,name=logs_bloom,json=logsBloom,proto3" json:"logs_bloom,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BlockHeader) Reset()         { *m = BlockHeader{} }
+func (m *BlockHeader) String() string { return proto.CompactTextString(m) }
+func (*BlockHeader) ProtoMessage()    {}
+func (*BlockHeader) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{17}
+}
+
+func (m *BlockHeader) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlockHeader.Unmarshal(m, b)
+}
+func (m *BlockHeader) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlockHeader.Marshal(b, m, deterministic)
+}
+func (m *BlockHeader) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlockHeader.Merge(m, src)
+}
+func (m *BlockHeader) XXX_Size() int {
+	return xxx_messageInfo_BlockHeader.Size(m)
+}
+func (m *BlockHeader) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlockHeader.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlockHeader proto.InternalMessageInfo
+
+func (m *BlockHeader) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *BlockHeader) GetParentHash() []byte {
+	if m != nil {
+		return m.ParentHash
+	}
+	return nil
+}
+
+func (m *BlockHeader) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *BlockHeader) GetCoinbase() []byte {
+	if m != nil {
+		return m.Coinbase
+	}
+	return nil
+}
+
+func (m *BlockHeader) GetStateRoot() []byte {
+	if m != nil {
+		return m.StateRoot
+	}
+	return nil
+}
+
+func (m *BlockHeader) GetTxRoot() []byte {
+	if m != nil {
+		return m.TxRoot
+	}
+	return nil
+}
+
+func (m *BlockHeader) GetBaseFee() []byte {
+	if m != nil {
+		return m.BaseFee
+	}
+	return nil
+}
+
+func (m *BlockHeader) GetWeight() uint64 {
+	if m != nil {
+		return m.Weight
+	}
+	return 0
+}
+
+func (m *BlockHeader) GetLogsBloom() []byte {
+	if m != nil {
+		return m.LogsBloom
+	}
+	return nil
+}
+
+type Block struct {
+	Header               *BlockHeader `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	Txs                  [][]byte     `protobuf:"bytes,2,rep,name=txs,proto3" json:"txs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *Block) Reset()         { *m = Block{} }
+func (m *Block) String() string { return proto.CompactTextString(m) }
+func (*Block) ProtoMessage()    {}
+func (*Block) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f7e43720d1edc0fe, []int{18}
+}
+
+func (m *Block) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Block.Unmarshal(m, b)
+}
+func (m *Block) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Block.Marshal(b, m, deterministic)
+}
+func (m *Block) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Block.Merge(m, src)
+}
+func (m *Block) XXX_Size() int {
+	return xxx_messageInfo_Block.Size(m)
+}
+func (m *Block) XXX_DiscardUnknown() {
+	xxx_messageInfo_Block.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Block proto.InternalMessageInfo
+
+func (m *Block) GetHeader() *BlockHeader {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func (m *Block) GetTxs() [][]byte {
+	if m != nil {
+		return m.Txs
+	}
+	return nil
+}
+
 func init() {
+	proto.RegisterEnum("corepb.TxType", TxType_name, TxType_value)
+	proto.RegisterEnum("corepb.HashAlgorithm", HashAlgorithm_name, HashAlgorithm_value)
+	proto.RegisterEnum("corepb.HTLCStatus", HTLCStatus_name, HTLCStatus_value)
 	proto.RegisterType((*Transaction)(nil), "corepb.Transaction")
+	proto.RegisterType((*KeysPayload)(nil), "corepb.KeysPayload")
+	proto.RegisterType((*ValidatorPayload)(nil), "corepb.ValidatorPayload")
+	proto.RegisterType((*AnchorPayload)(nil), "corepb.AnchorPayload")
+	proto.RegisterType((*TimeLockPayload)(nil), "corepb.TimeLockPayload")
+	proto.RegisterType((*CancelTimeLockPayload)(nil), "corepb.CancelTimeLockPayload")
+	proto.RegisterType((*HTLCLockPayload)(nil), "corepb.HTLCLockPayload")
+	proto.RegisterType((*HTLCClaimPayload)(nil), "corepb.HTLCClaimPayload")
+	proto.RegisterType((*HTLCRefundPayload)(nil), "corepb.HTLCRefundPayload")
+	proto.RegisterType((*ContractDeployPayload)(nil), "corepb.ContractDeployPayload")
+	proto.RegisterType((*ContractCallPayload)(nil), "corepb.ContractCallPayload")
 	proto.RegisterType((*Account)(nil), "corepb.Account")
+	proto.RegisterType((*Validator)(nil), "corepb.Validator")
+	proto.RegisterType((*Anchor)(nil), "corepb.Anchor")
+	proto.RegisterType((*TimeLock)(nil), "corepb.TimeLock")
+	proto.RegisterType((*TimeLockIndex)(nil), "corepb.TimeLockIndex")
+	proto.RegisterType((*HTLC)(nil), "corepb.HTLC")
+	proto.RegisterType((*BlockHeader)(nil), "corepb.BlockHeader")
+	proto.RegisterType((*Block)(nil), "corepb.Block")
 }
 
 func init() { proto.RegisterFile("core.proto", fileDescriptor_f7e43720d1edc0fe) }
 
 var fileDescriptor_f7e43720d1edc0fe = []byte{
-	// 232 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x4c, 0x90, 0x4d, 0x6e, 0x84, 0x30,
-	0x0c, 0x85, 0x15, 0x60, 0xa0, 0xe3, 0xfe, 0xa8, 0x8a, 0xba, 0xf0, 0xa2, 0x0b, 0x34, 0x2b, 0x56,
-	0xdd, 0xf4, 0x04, 0xbd, 0x02, 0xed, 0x05, 0x4c, 0xc8, 0x94, 0x48, 0x43, 0x8c, 0x92, 0xd0, 0xbb,
-	0xf6, 0x36, 0x95, 0xc3, 0x8c, 0xe8, 0xee, 0xbd, 0xef, 0x3d, 0x25, 0xb6, 0x01, 0x0c, 0x07, 0xfb,
-	0xb6, 0x04, 0x4e, 0xac, 0x6b, 0xd1, 0xcb, 0x70, 0xfa, 0x55, 0x70, 0xff, 0x15, 0xc8, 0x47, 0x32,
-	0xc9, 0xb1, 0xd7, 0x1a, 0xaa, 0x89, 0xe2, 0x84, 0xaa, 0x55, 0xdd, 0x43, 0x9f, 0xb5, 0x46, 0x68,
-	0xcc, 0x44, 0xce, 0xbb, 0x11, 0x8b, 0x56, 0x75, 0x8f, 0xfd, 0xcd, 0x4a, 0xfb, 0x1c, 0x78, 0xc6,
-	0x72, 0x6b, 0x8b, 0xd6, 0x4f, 0x50, 0x24, 0xc6, 0x2a, 0x93, 0x22, 0xb1, 0x7e, 0x81, 0xc3, 0x0f,
-	0x5d, 0x56, 0x8b, 0x87, 0x8c, 0x36, 0xa3, 0x9f, 0xa1, 0x3c, 0x5b, 0x8b, 0x75, 0x66, 0x22, 0xa5,
-	0xe7, 0xd9, 0x1b, 0x8b, 0x4d, 0xab, 0xba, 0xaa, 0xdf, 0x8c, 0x7e, 0x85, 0x63, 0x72, 0xb3, 0x8d,
-	0x89, 0xe6, 0x05, 0xef, 0x5a, 0xd5, 0x95, 0xfd, 0x0e, 0x24, 0x8d, 0xee, 0xdb, 0x53, 0x5a, 0x83,
-	0xc5, 0x63, 0x7e, 0x6b, 0x07, 0xa7, 0x4f, 0x68, 0x3e, 0x8c, 0xe1, 0xd5, 0x27, 0x59, 0x81, 0xc6,
-	0x31, 0xd8, 0x18, 0xaf, 0x9b, 0xdd, 0xac, 0x24, 0x03, 0x5d, 0x48, 0x3e, 0x2e, 0xb6, 0xe4, 0x6a,
-	0xf7, 0x81, 0xca, 0x7f, 0x03, 0x0d, 0x75, 0xbe, 0xdf, 0xfb, 0x5f, 0x00, 0x00, 0x00, 0xff, 0xff,
-	0x17, 0xb5, 0x7a, 0x88, 0x4d, 0x01, 0x00, 0x00,
+	// 1247 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x56, 0xc1, 0x6e, 0xdb, 0x46,
+	0x13, 0x0e, 0x25, 0x99, 0x92, 0x46, 0x96, 0x4c, 0xaf, 0x93, 0xfc, 0xfc, 0xd3, 0x14, 0x71, 0x88,
+	0x16, 0x70, 0xdd, 0xc2, 0x28, 0x9c, 0xb6, 0xa7, 0x5e, 0x18, 0x4a, 0xae, 0x04, 0x2b, 0x96, 0x41,
+	0x33, 0x01, 0x72, 0x22, 0x56, 0xe4, 0x4a, 0x22, 0x4c, 0x71, 0x59, 0xee, 0x2a, 0xb1, 0x5e, 0xa1,
+	0xd7, 0x1e, 0xfb, 0x22, 0x7d, 0x82, 0xbe, 0x4b, 0xdf, 0xa2, 0x98, 0x25, 0x29, 0x51, 0x4e, 0x90,
+	0x16, 0xb9, 0xed, 0xf7, 0xcd, 0xec, 0xce, 0xb7, 0xc3, 0x99, 0x59, 0x02, 0x04, 0x3c, 0x63, 0x67,
+	0x69, 0xc6, 0x25, 0x27, 0x3a, 0xae, 0xd3, 0xa9, 0xf5, 0x67, 0x1d, 0x3a, 0x5e, 0x46, 0x13, 0x41,
+	0x03, 0x19, 0xf1, 0x84, 0x10, 0x68, 0x2c, 0xa8, 0x58, 0x98, 0xda, 0xb1, 0x76, 0xb2, 0xef, 0xaa,
+	0x35, 0x31, 0xa1, 0x19, 0x2c, 0x68, 0x94, 0x44, 0xa1, 0x59, 0x3b, 0xd6, 0x4e, 0xba, 0x6e, 0x09,
+	0xd1, 0x7b, 0x96, 0xf1, 0xa5, 0x59, 0xcf, 0xbd, 0x71, 0x4d, 0x7a, 0x50, 0x93, 0xdc, 0x6c, 0x28,
+	0xa6, 0x26, 0x39, 0x79, 0x08, 0x7b, 0xef, 0x68, 0xbc, 0x62, 0xe6, 0x9e, 0xa2, 0x72, 0x40, 0x0c,
+	0xa8, 0xcf, 0x18, 0x33, 0x75, 0xc5, 0xe1, 0x12, 0xfd, 0x12, 0x9e, 0x04, 0xcc, 0x6c, 0x1e, 0x6b,
+	0x27, 0x0d, 0x37, 0x07, 0xe4, 0x29, 0xb4, 0x65, 0xb4, 0x64, 0x42, 0xd2, 0x65, 0x6a, 0xb6, 0x8e,
+	0xb5, 0x93, 0xba, 0xbb, 0x25, 0xd0, 0x2a, 0xa2, 0x79, 0x42, 0xe5, 0x2a, 0x63, 0x66, 0x5b, 0x9d,
+	0xb5, 0x25, 0xc8, 0x97, 0x00, 0xe9, 0x6a, 0x1a, 0x47, 0x81, 0x7f, 0xcb, 0xd6, 0x26, 0xe4, 0xe6,
+	0x9c, 0xb9, 0x64, 0x6b, 0x14, 0xbf, 0x64, 0x4b, 0x6e, 0x76, 0x72, 0xf1, 0xb8, 0xc6, 0xab, 0xbe,
+	0x63, 0x99, 0x88, 0x78, 0x62, 0xee, 0xe7, 0x57, 0x2d, 0x20, 0xb1, 0xa0, 0x21, 0xd7, 0x29, 0x33,
+	0xbb, 0xc7, 0xda, 0x49, 0xef, 0xbc, 0x77, 0x96, 0xe7, 0xef, 0xcc, 0xbb, 0xf3, 0xd6, 0x29, 0x73,
+	0x95, 0x0d, 0x77, 0xa7, 0x74, 0x1d, 0x73, 0x1a, 0x9a, 0x3d, 0x75, 0x68, 0x09, 0xc9, 0x33, 0xe8,
+	0xbc, 0xa3, 0x71, 0x14, 0xfa, 0x74, 0x26, 0x59, 0x66, 0x1e, 0xa8, 0x2b, 0x82, 0xa2, 0x6c, 0x64,
+	0xb6, 0x0e, 0xab, 0x44, 0x46, 0xb1, 0x69, 0x54, 0x1c, 0x5e, 0x23, 0x83, 0x09, 0x93, 0x51, 0x6a,
+	0x1e, 0xe6, 0x09, 0x93, 0x51, 0x6a, 0x3d, 0x87, 0xce, 0x25, 0x5b, 0x8b, 0xeb, 0x22, 0x04, 0x81,
+	0xc6, 0x2d, 0x5b, 0x0b, 0x53, 0x3b, 0xae, 0xe3, 0x75, 0x70, 0x6d, 0x2d, 0xc0, 0x78, 0x83, 0x47,
+	0x50, 0xc9, 0xb3, 0xd2, 0xef, 0x2b, 0xe8, 0x4d, 0x63, 0xe1, 0x57, 0x32, 0x93, 0x7f, 0xeb, 0xfd,
+	0x69, 0x2c, 0xae, 0x37, 0xc9, 0x39, 0x83, 0xa3, 0x34, 0xe3, 0x7c, 0xe6, 0xf3, 0x99, 0x9f, 0x72,
+	0x21, 0x98, 0x50, 0x49, 0xa9, 0x29, 0xd7, 0x43, 0x65, 0x9a, 0xcc, 0xae, 0x37, 0x06, 0xeb, 0x3b,
+	0xe8, 0xda, 0x49, 0xb0, 0xd8, 0x86, 0xf9, 0x02, 0xda, 0x21, 0x95, 0xd4, 0xaf, 0x54, 0x53, 0x0b,
+	0x89, 0x21, 0x15, 0x0b, 0xeb, 0x0c, 0x0e, 0xbc, 0x68, 0xc9, 0xc6, 0x3c, 0xb8, 0xad, 0xf8, 0xaf,
+	0x92, 0x98, 0x07, 0xb7, 0x3e, 0x95, 0xca, 0xbf, 0xe1, 0xb6, 0x72, 0xc2, 0x96, 0xd6, 0xf7, 0xf0,
+	0xc8, 0xa1, 0x49, 0xc0, 0xe2, 0xfb, 0xbb, 0xfe, 0x07, 0x4d, 0xb5, 0x27, 0x0a, 0x8b, 0x18, 0x3a,
+	0xc2, 0x51, 0x68, 0xfd, 0xae, 0xc1, 0xc1, 0xd0, 0x1b, 0x3b, 0x55, 0xe7, 0x9f, 0xa1, 0x87, 0x6a,
+	0x7c, 0x1a, 0xcf, 0x79, 0x16, 0xc9, 0xc5, 0x52, 0xed, 0xe9, 0x9d, 0x3f, 0x2a, 0x3f, 0x26, 0x6a,
+	0xb3, 0x4b, 0xa3, 0xdb, 0x5d, 0x54, 0x21, 0x0a, 0x54, 0xbb, 0x31, 0x40, 0x91, 0x87, 0x16, 0x12,
+	0x18, 0x81, 0x7c, 0x0d, 0x3d, 0xac, 0x4a, 0xbe, 0x92, 0xfe, 0x82, 0x45, 0xf3, 0x85, 0x54, 0x2d,
+	0xd1, 0x70, 0xbb, 0x05, 0x3b, 0x54, 0xa4, 0x35, 0x01, 0x03, 0x45, 0x39, 0x31, 0x8d, 0x96, 0xd7,
+	0xdb, 0xd2, 0x08, 0x78, 0x22, 0x33, 0x1a, 0xc8, 0xed, 0x35, 0xa0, 0xa4, 0x46, 0x21, 0x79, 0x02,
+	0xad, 0x34, 0x63, 0xd1, 0x92, 0xce, 0x59, 0x19, 0xb7, 0xc4, 0xd6, 0x0f, 0x70, 0x88, 0x07, 0xba,
+	0x6c, 0xb6, 0x4a, 0xc2, 0xff, 0x7a, 0xa2, 0x35, 0x84, 0x47, 0x4e, 0x81, 0xfa, 0x2c, 0x8d, 0xf9,
+	0xba, 0x52, 0x43, 0x01, 0x0f, 0x59, 0xd9, 0xfd, 0xb8, 0xc6, 0x7b, 0xcf, 0xa9, 0xf0, 0xe3, 0x68,
+	0x19, 0x49, 0x15, 0xbf, 0xe1, 0xb6, 0xe6, 0x54, 0x8c, 0x11, 0x5b, 0x17, 0x70, 0x54, 0x9e, 0xe4,
+	0xd0, 0x38, 0xae, 0x9c, 0x43, 0xb3, 0xf9, 0xa6, 0x16, 0x71, 0xfd, 0xe9, 0x73, 0xe6, 0xd0, 0xb4,
+	0x83, 0x80, 0xaf, 0x12, 0x89, 0x4d, 0x44, 0xc3, 0x30, 0x63, 0x42, 0x14, 0x32, 0x4a, 0x88, 0x96,
+	0x29, 0x8d, 0xb1, 0x0e, 0x8a, 0x3c, 0x94, 0x70, 0x3b, 0x3b, 0xea, 0xd5, 0xd9, 0x51, 0x76, 0x44,
+	0xa3, 0xd2, 0x11, 0x14, 0xda, 0x9b, 0x8e, 0xf8, 0x44, 0xa8, 0x0f, 0x9b, 0xa4, 0xf6, 0x91, 0x26,
+	0x79, 0x08, 0x7b, 0x42, 0xd2, 0x5b, 0x56, 0xcc, 0xbf, 0x1c, 0x58, 0xbf, 0x82, 0x9e, 0xb7, 0x02,
+	0xda, 0xf9, 0xfb, 0x84, 0x65, 0xc5, 0xe9, 0x39, 0xd8, 0xed, 0x8c, 0xda, 0x6e, 0x67, 0x90, 0xc7,
+	0xa0, 0xef, 0x14, 0x50, 0x81, 0x76, 0xe7, 0x60, 0xe3, 0xde, 0x1c, 0xb4, 0xfe, 0xd0, 0xa0, 0x55,
+	0xb6, 0x06, 0x0e, 0xe0, 0xcd, 0x57, 0xaf, 0x45, 0xe1, 0x56, 0x45, 0xad, 0xaa, 0xe2, 0x29, 0xb4,
+	0x33, 0x16, 0x44, 0x69, 0xc4, 0x12, 0x59, 0xe8, 0xdf, 0x12, 0x28, 0x83, 0x2e, 0xf1, 0x73, 0x14,
+	0x83, 0xbc, 0x40, 0xbb, 0x5d, 0xba, 0xb7, 0xdb, 0xa5, 0x15, 0xed, 0x7a, 0x55, 0xbb, 0xf5, 0x1c,
+	0xba, 0xa5, 0xb8, 0x51, 0x12, 0xb2, 0x3b, 0x9c, 0x65, 0x51, 0x58, 0x56, 0x07, 0x2e, 0xad, 0xbf,
+	0x6a, 0xd0, 0xc0, 0x42, 0xfe, 0x40, 0xfc, 0x63, 0xd0, 0x05, 0x4b, 0xc2, 0x8d, 0xfa, 0x02, 0x7d,
+	0xa6, 0xfc, 0x0f, 0x27, 0xc0, 0xde, 0xe7, 0x4e, 0x00, 0xfd, 0x5f, 0x27, 0x40, 0xf3, 0x23, 0x13,
+	0xa0, 0x92, 0xa3, 0xd6, 0xce, 0xf7, 0x3d, 0x05, 0x5d, 0x48, 0x2a, 0x57, 0x42, 0x3d, 0x63, 0xbd,
+	0x73, 0xb2, 0x51, 0xe4, 0x8d, 0x9d, 0x1b, 0x65, 0x71, 0x0b, 0x8f, 0x9d, 0x81, 0x00, 0xf7, 0x06,
+	0xc2, 0x6f, 0x35, 0xe8, 0xbc, 0x44, 0x81, 0x43, 0x46, 0x31, 0x4f, 0xdb, 0x78, 0xda, 0x4e, 0xbc,
+	0x67, 0xd0, 0x49, 0x69, 0xc6, 0x12, 0x59, 0x2d, 0x43, 0xc8, 0x29, 0x55, 0x88, 0x3b, 0x05, 0x57,
+	0xbf, 0xff, 0xf0, 0x3e, 0x81, 0x56, 0xc0, 0xa3, 0x64, 0x4a, 0x05, 0x2b, 0x52, 0xbc, 0xc1, 0xf8,
+	0xec, 0xa2, 0x50, 0xe6, 0x67, 0x9c, 0xcb, 0xe2, 0xd5, 0x6f, 0x2b, 0xc6, 0xe5, 0x5c, 0xe2, 0xc8,
+	0x96, 0x77, 0xb9, 0x2d, 0xcf, 0xa1, 0x2e, 0xef, 0x94, 0xe1, 0xff, 0xd0, 0xc2, 0xfd, 0x3e, 0xfe,
+	0x17, 0x34, 0xcb, 0xfe, 0x16, 0xec, 0x82, 0x31, 0xbc, 0xc5, 0xfb, 0x9d, 0xac, 0xe5, 0x08, 0x43,
+	0xc5, 0x7c, 0x2e, 0xfc, 0x69, 0xcc, 0xf9, 0xb2, 0xfc, 0x01, 0x40, 0xe6, 0x25, 0x12, 0xd6, 0x05,
+	0xec, 0xa9, 0x5c, 0x90, 0x6f, 0x31, 0x0b, 0x98, 0x0f, 0x95, 0x85, 0xce, 0xf9, 0x51, 0x99, 0xdd,
+	0x4a, 0xaa, 0xdc, 0xc2, 0x45, 0xbd, 0xb4, 0x77, 0xc2, 0xac, 0xe5, 0xd5, 0x29, 0xef, 0xc4, 0xe9,
+	0xdf, 0x1a, 0xe8, 0xf9, 0x43, 0x4f, 0xf6, 0xa1, 0xe5, 0xb9, 0xf6, 0xd5, 0xcd, 0xc5, 0xc0, 0x35,
+	0x1e, 0x10, 0x02, 0x3d, 0xc7, 0x1d, 0xd8, 0xde, 0xc0, 0xb7, 0x1d, 0x67, 0xf2, 0xfa, 0xca, 0x33,
+	0x34, 0xd2, 0x03, 0x70, 0x27, 0x1e, 0x72, 0x97, 0x83, 0xb7, 0x46, 0x8d, 0x3c, 0x06, 0xe2, 0x0e,
+	0x7e, 0x19, 0xdd, 0x78, 0x03, 0xd7, 0x7f, 0x63, 0x8f, 0x47, 0x7d, 0xdb, 0x9b, 0xb8, 0x46, 0x9d,
+	0x1c, 0x40, 0xc7, 0xbe, 0x72, 0x86, 0x13, 0xd7, 0xef, 0xdb, 0x9e, 0x6d, 0x34, 0xd0, 0xd1, 0x1b,
+	0xbd, 0x1a, 0xf8, 0xe3, 0x89, 0x73, 0xe9, 0x6f, 0x82, 0xec, 0x91, 0x87, 0x60, 0x38, 0xf6, 0x95,
+	0x33, 0x18, 0xfb, 0x1b, 0xb3, 0xa1, 0x93, 0x2e, 0xb4, 0xb1, 0x34, 0x72, 0xd8, 0xc4, 0xa8, 0x0a,
+	0x3a, 0x63, 0x7b, 0xf4, 0xca, 0x68, 0xe1, 0xe9, 0x0a, 0xbb, 0x83, 0x8b, 0xd7, 0x57, 0x7d, 0xa3,
+	0x4d, 0x8e, 0xe0, 0xc0, 0x99, 0x5c, 0x79, 0xae, 0xed, 0x78, 0x7e, 0x7f, 0x70, 0x3d, 0x9e, 0xbc,
+	0x35, 0x80, 0x1c, 0x42, 0x77, 0x43, 0x3a, 0xf6, 0x78, 0x6c, 0x74, 0x4e, 0xbf, 0x81, 0xee, 0x4e,
+	0x13, 0xe0, 0x8d, 0x6f, 0x86, 0xf6, 0x0b, 0xff, 0xfc, 0xc7, 0x9f, 0x8c, 0x07, 0x04, 0x40, 0xbf,
+	0x19, 0xda, 0xb8, 0xd6, 0x4e, 0x5f, 0x00, 0x6c, 0xab, 0x13, 0x2d, 0xa8, 0x65, 0xd0, 0x37, 0x1e,
+	0x90, 0x0e, 0x34, 0x95, 0x90, 0x41, 0xdf, 0xd0, 0xf0, 0x80, 0x5c, 0xc5, 0xa0, 0x6f, 0xd4, 0xa6,
+	0xba, 0xfa, 0xff, 0x7c, 0xf1, 0xcf, 0x00, 0x52, 0xcd, 0x28, 0xe4, 0x8d, 0x0a, 0x00, 0x00,
 }
@@ -1,20 +1,29 @@
 package rpc
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
+	"net"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/gorilla/mux"
 	log "github.com/inconshreveable/log15"
 	"github.com/ldmtam/tam-chain/abstraction"
 	"github.com/ldmtam/tam-chain/account"
 	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/chain"
+	"github.com/ldmtam/tam-chain/core/executor"
+	"github.com/ldmtam/tam-chain/core/state"
 	"github.com/ldmtam/tam-chain/core/transaction"
+	"github.com/ldmtam/tam-chain/crypto/bls"
+	"github.com/ldmtam/tam-chain/p2p"
 	"github.com/mr-tron/base58/base58"
 )
 
@@ -32,6 +41,7 @@ func generateKeypairHandler(w http.ResponseWriter, r *http.Request) {
 	type keypair struct {
 		PrivateKey string `json:"private_key"`
 		PublicKey  string `json:"public_key"`
+		Address    string `json:"address"`
 	}
 
 	keyPair, err := account.NewKeyPair()
@@ -45,60 +55,110 @@ func generateKeypairHandler(w http.ResponseWriter, r *http.Request) {
 	kp := keypair{
 		PrivateKey: keyPair.EncodePrivateKey(),
 		PublicKey:  keyPair.EncodePublicKey(),
+		Address:    keyPair.Address().String(),
 	}
 
 	json.NewEncoder(w).Encode(kp)
 }
 
-func createRawTxHandler(w http.ResponseWriter, r *http.Request) {
+func createRawTxHandler(w http.ResponseWriter, r *http.Request, chainConfig *common.ChainConfig) {
 	type createRawTx struct {
-		ChainID string `json:"chainid"`
-		From    string `json:"from"`
-		To      string `json:"to"`
-		Value   string `json:"value"`
-		Fee     string `json:"fee"`
-		Nonce   string `json:"nonce"`
+		ChainID   string `json:"chainid"`
+		PublicKey string `json:"public_key"`
+		From      string `json:"from"`
+		To        string `json:"to"`
+		Value     string `json:"value"`
+		Fee       string `json:"fee"`
+		Tip       string `json:"tip"`
+		Nonce     string `json:"nonce"`
+		Memo      string `json:"memo"`
+
+		// validity window, see transaction.ValidityTimeThreshold.
+		ValidAfter string `json:"valid_after"`
+		ValidUntil string `json:"valid_until"`
+
+		Type string `json:"type"`
+		txPayloadFields
 	}
 
 	data := new(createRawTx)
 	b, _ := ioutil.ReadAll(r.Body)
 	json.Unmarshal(b, &data)
 
-	if data.From == data.To {
-		errString := `from and to address must not be the same`
-		log.Error("addresses are the same", "error", errString)
+	signer := &account.KeyPairImpl{}
 
-		renderErrorMessage(errors.New(errString), w)
+	err := signer.DecodePublicKey(data.PublicKey)
+	if err != nil {
+		log.Error("can not decode `public_key` field", "error", err)
+
+		renderErrorMessage(err, w)
 		return
 	}
 
-	from := &account.KeyPairImpl{}
-	to := &account.KeyPairImpl{}
+	// `from` is only needed by accounts which rotated their keys, it's derived
+	// from the signing key by default.
+	txFrom := signer.Address()
+	if data.From != "" {
+		txFrom, err = common.ParseAddress(data.From)
+		if err != nil {
+			log.Error("cannot decode `from` field", "error", err)
+
+			renderErrorMessage(err, w)
+			return
+		}
+	}
 
-	err := from.DecodePublicKey(data.From)
-	if err != nil {
-		log.Error("can not decode `from` field", "error", err)
+	// tx type is optional, it's a transfer by default.
+	txType := transaction.TxTypeTransfer
+	if data.Type != "" {
+		txType, err = transaction.ParseTxType(data.Type)
+		if err != nil {
+			log.Error("cannot parse `type` field", "error", err)
 
-		renderErrorMessage(err, w)
-		return
+			renderErrorMessage(err, w)
+			return
+		}
 	}
 
-	err = to.DecodePublicKey(data.To)
-	if err != nil {
-		log.Error("cannot decode `to` field", "error", err)
+	// txs which only change the sender's account are sent to the sender itself.
+	txTo := txFrom
+	if data.To != "" || txType == transaction.TxTypeTransfer || txType == transaction.TxTypeCreateAccount || txType == transaction.TxTypeTimeLockTransfer || txType == transaction.TxTypeHTLCLock || txType == transaction.TxTypeContractCall {
+		txTo, err = common.ParseAddress(data.To)
+		if err != nil {
+			log.Error("cannot decode `to` field", "error", err)
 
-		renderErrorMessage(err, w)
-		return
+			renderErrorMessage(err, w)
+			return
+		}
 	}
 
-	txChainID, err := strconv.Atoi(data.ChainID)
-	if err != nil {
-		log.Error("cannot convert `chainID` to int", "error", err)
+	if txType == transaction.TxTypeTransfer && txFrom.Equals(txTo) {
+		errString := `from and to address must not be the same`
+		log.Error("addresses are the same", "error", errString)
 
-		renderErrorMessage(err, w)
+		renderErrorMessage(errors.New(errString), w)
 		return
 	}
 
+	// chain id is optional, the node's chain id is used by default.
+	if data.ChainID != "" {
+		txChainID, err := strconv.Atoi(data.ChainID)
+		if err != nil {
+			log.Error("cannot convert `chainID` to int", "error", err)
+
+			renderErrorMessage(err, w)
+			return
+		}
+
+		if uint32(txChainID) != chainConfig.ChainID {
+			errString := fmt.Sprintf("chain id must be %d", chainConfig.ChainID)
+			log.Error("mismatched chain id", "error", errString)
+
+			renderErrorMessage(errors.New(errString), w)
+			return
+		}
+	}
+
 	txValue, err := strconv.Atoi(data.Value)
 	if err != nil {
 		log.Error("cannot convert `value` to int", "error", err)
@@ -123,19 +183,42 @@ func createRawTxHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var txFrom common.Address
-	copy(txFrom[:], from.PublicKey)
-	var txTo common.Address
-	copy(txTo[:], to.PublicKey)
+	// contracts are deployed to the address derived from the sender and the nonce.
+	if txType == transaction.TxTypeContractDeploy && data.To == "" {
+		txTo = common.NewContractAddress(txFrom, uint64(txNonce))
+	}
+
+	var txMemo []byte
+	if data.Memo != "" {
+		txMemo, err = base58.Decode(data.Memo)
+		if err != nil {
+			log.Error("cannot decode `memo` field", "error", err)
 
-	tx, err := transaction.NewTransaction(
-		uint32(txChainID),
+			renderErrorMessage(err, w)
+			return
+		}
+	}
+
+	txPayload, err := buildTxPayload(txType, &data.txPayloadFields)
+	if err != nil {
+		log.Error("cannot build transaction payload", "error", err)
+
+		renderErrorMessage(err, w)
+		return
+	}
+
+	tx, err := transaction.NewTypedTransaction(
+		txType,
+		txPayload,
+		chainConfig.ChainID,
 		txFrom,
+		signer.PublicKey,
 		txTo,
 		big.NewInt(int64(txValue)),
 		big.NewInt(int64(txFee)),
 		uint64(txNonce),
 		time.Now().Unix(),
+		txMemo,
 	)
 	if err != nil {
 		log.Error("cannot create new raw transaction", "error", err)
@@ -144,6 +227,50 @@ func createRawTxHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if data.Tip != "" {
+		txTip, err := strconv.Atoi(data.Tip)
+		if err != nil {
+			log.Error("cannot convert `tip` to int", "error", err)
+
+			renderErrorMessage(err, w)
+			return
+		}
+
+		err = tx.SetTip(big.NewInt(int64(txTip)))
+		if err != nil {
+			log.Error("invalid tip", "error", err)
+
+			renderErrorMessage(err, w)
+			return
+		}
+	}
+
+	if data.ValidAfter != "" || data.ValidUntil != "" {
+		validAfter, err := parseOptionalUint(data.ValidAfter)
+		if err != nil {
+			log.Error("cannot convert `valid_after` to int", "error", err)
+
+			renderErrorMessage(err, w)
+			return
+		}
+
+		validUntil, err := parseOptionalUint(data.ValidUntil)
+		if err != nil {
+			log.Error("cannot convert `valid_until` to int", "error", err)
+
+			renderErrorMessage(err, w)
+			return
+		}
+
+		err = tx.SetValidityWindow(validAfter, validUntil)
+		if err != nil {
+			log.Error("invalid validity window", "error", err)
+
+			renderErrorMessage(err, w)
+			return
+		}
+	}
+
 	pbMess, err := tx.Marshal()
 	if err != nil {
 		log.Error("cannot encode transaction with protobuf", "error", err)
@@ -156,6 +283,187 @@ func createRawTxHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(d)
 }
 
+// parseOptionalUint parses a decimal uint64, empty string is 0.
+func parseOptionalUint(s string) (uint64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// txPayloadFields are the type-specific fields of a createrawtx request.
+//
+// `keys` of create_account and rotate_key txs are base58 encoded ed25519
+// public keys, `bls_public_key` and `bls_proof` (proof of possession) of
+// register_validator txs are base58 encoded, `data_hash` of anchor_data txs
+// is hex encoded.
+//
+// `unlock_at` of time_lock_transfer txs is a block height or unix timestamp
+// like validity window bounds, `lock_id` of cancel_time_lock txs is the hex
+// encoded hash of the tx which created the time lock.
+//
+// `hash_lock` of htlc_lock txs is hex encoded and `hash_algorithm` is sha3_256
+// by default. `contract_id` of htlc_claim and htlc_refund txs is the hex
+// encoded hash of the htlc_lock tx, `preimage` of htlc_claim txs is hex encoded.
+//
+// `code` of contract_deploy txs and `args` of contract_call txs are hex
+// encoded, both need a `gas_limit`.
+type txPayloadFields struct {
+	Keys         []string `json:"keys"`
+	DataHash     string   `json:"data_hash"`
+	BLSPublicKey string   `json:"bls_public_key"`
+	BLSProof     string   `json:"bls_proof"`
+
+	UnlockAt string `json:"unlock_at"`
+	LockID   string `json:"lock_id"`
+
+	HashAlgorithm string `json:"hash_algorithm"`
+	HashLock      string `json:"hash_lock"`
+	TimeoutHeight string `json:"timeout_height"`
+	ContractID    string `json:"contract_id"`
+	Preimage      string `json:"preimage"`
+
+	Code     string   `json:"code"`
+	Args     []string `json:"args"`
+	GasLimit string   `json:"gas_limit"`
+}
+
+// parseHash parses a hex encoded hash, name is the request field.
+func parseHash(name, s string) (common.Hash, error) {
+	var h common.Hash
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return h, err
+	}
+	if len(b) != common.HashLength {
+		return h, fmt.Errorf("`%s` must be 32 bytes", name)
+	}
+	h.SetBytes(b)
+	return h, nil
+}
+
+// buildTxPayload encodes the payload of a tx type from request fields.
+func buildTxPayload(txType transaction.TxType, fields *txPayloadFields) ([]byte, error) {
+	switch txType {
+	case transaction.TxTypeCreateAccount, transaction.TxTypeRotateKey:
+		pubKeys := make([][]byte, len(fields.Keys))
+		for i, key := range fields.Keys {
+			kp := &account.KeyPairImpl{}
+			if err := kp.DecodePublicKey(key); err != nil {
+				return nil, err
+			}
+			pubKeys[i] = kp.PublicKey
+		}
+		return transaction.EncodeKeysPayload(pubKeys)
+
+	case transaction.TxTypeRegisterValidator:
+		pubKeyBytes, err := base58.Decode(fields.BLSPublicKey)
+		if err != nil {
+			return nil, err
+		}
+		pubKey, err := bls.UnmarshalPublicKey(pubKeyBytes)
+		if err != nil {
+			return nil, err
+		}
+		proofBytes, err := base58.Decode(fields.BLSProof)
+		if err != nil {
+			return nil, err
+		}
+		proof, err := bls.UnmarshalSignature(proofBytes)
+		if err != nil {
+			return nil, err
+		}
+		return transaction.EncodeValidatorPayload(pubKey, proof)
+
+	case transaction.TxTypeAnchorData:
+		h, err := parseHash("data_hash", fields.DataHash)
+		if err != nil {
+			return nil, err
+		}
+		return transaction.EncodeAnchorPayload(h)
+
+	case transaction.TxTypeTimeLockTransfer:
+		unlockAt, err := parseOptionalUint(fields.UnlockAt)
+		if err != nil {
+			return nil, err
+		}
+		return transaction.EncodeTimeLockPayload(unlockAt)
+
+	case transaction.TxTypeCancelTimeLock:
+		id, err := parseHash("lock_id", fields.LockID)
+		if err != nil {
+			return nil, err
+		}
+		return transaction.EncodeCancelTimeLockPayload(id)
+
+	case transaction.TxTypeHTLCLock:
+		lock := &transaction.HTLCLock{HashAlgorithm: transaction.HashSHA3256}
+		var err error
+		if fields.HashAlgorithm != "" {
+			lock.HashAlgorithm, err = transaction.ParseHashAlgorithm(fields.HashAlgorithm)
+			if err != nil {
+				return nil, err
+			}
+		}
+		lock.HashLock, err = parseHash("hash_lock", fields.HashLock)
+		if err != nil {
+			return nil, err
+		}
+		lock.TimeoutHeight, err = parseOptionalUint(fields.TimeoutHeight)
+		if err != nil {
+			return nil, err
+		}
+		return transaction.EncodeHTLCLockPayload(lock)
+
+	case transaction.TxTypeHTLCClaim:
+		id, err := parseHash("contract_id", fields.ContractID)
+		if err != nil {
+			return nil, err
+		}
+		preimage, err := hex.DecodeString(fields.Preimage)
+		if err != nil {
+			return nil, err
+		}
+		return transaction.EncodeHTLCClaimPayload(id, preimage)
+
+	case transaction.TxTypeHTLCRefund:
+		id, err := parseHash("contract_id", fields.ContractID)
+		if err != nil {
+			return nil, err
+		}
+		return transaction.EncodeHTLCRefundPayload(id)
+
+	case transaction.TxTypeContractDeploy:
+		code, err := hex.DecodeString(fields.Code)
+		if err != nil {
+			return nil, err
+		}
+		gasLimit, err := parseOptionalUint(fields.GasLimit)
+		if err != nil {
+			return nil, err
+		}
+		return transaction.EncodeContractDeployPayload(code, gasLimit)
+
+	case transaction.TxTypeContractCall:
+		args := make([][]byte, len(fields.Args))
+		for i, arg := range fields.Args {
+			b, err := hex.DecodeString(arg)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = b
+		}
+		gasLimit, err := parseOptionalUint(fields.GasLimit)
+		if err != nil {
+			return nil, err
+		}
+		return transaction.EncodeContractCallPayload(args, gasLimit)
+
+	default:
+		return nil, nil
+	}
+}
+
 func signRawTxHandler(w http.ResponseWriter, r *http.Request) {
 	type signRawTx struct {
 		PrivateKey    string `json:"private_key"`
@@ -207,7 +515,8 @@ func signRawTxHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(d)
 }
 
-func sendRawTxHandler(w http.ResponseWriter, r *http.Request, txPool abstraction.TxPool) {
+// sendRawTxHandler adds a tx to the tx pool and relays it to the network.
+func sendRawTxHandler(w http.ResponseWriter, r *http.Request, txPool abstraction.TxPool, p2pService abstraction.P2PService) {
 	type sendRawTx struct {
 		RawTx string `json:"raw_tx"`
 	}
@@ -231,6 +540,470 @@ func sendRawTxHandler(w http.ResponseWriter, r *http.Request, txPool abstraction
 		renderErrorMessage(err, w)
 		return
 	}
+	p2pService.Publish(txBytes, abstraction.MessageType(p2p.PublishTx))
+
+	d := map[string]string{"result": "success"}
+	json.NewEncoder(w).Encode(d)
+}
+
+func encryptMemoHandler(w http.ResponseWriter, r *http.Request) {
+	type encryptMemo struct {
+		PublicKey string `json:"public_key"`
+		Memo      string `json:"memo"`
+	}
+
+	data := new(encryptMemo)
+	b, _ := ioutil.ReadAll(r.Body)
+	json.Unmarshal(b, &data)
+
+	recipient := &account.KeyPairImpl{}
+
+	err := recipient.DecodePublicKey(data.PublicKey)
+	if err != nil {
+		log.Error("cannot decode `public_key` field", "error", err)
+
+		renderErrorMessage(err, w)
+		return
+	}
+
+	encryptedMemo, err := account.EncryptMemo(recipient.PublicKey, []byte(data.Memo))
+	if err != nil {
+		log.Error("cannot encrypt memo", "error", err)
+
+		renderErrorMessage(err, w)
+		return
+	}
+
+	d := map[string]string{"encrypted_memo": base58.Encode(encryptedMemo)}
+	json.NewEncoder(w).Encode(d)
+}
+
+func decryptMemoHandler(w http.ResponseWriter, r *http.Request) {
+	type decryptMemo struct {
+		PrivateKey string `json:"private_key"`
+		RawTx      string `json:"raw_tx"`
+	}
+
+	data := new(decryptMemo)
+	b, _ := ioutil.ReadAll(r.Body)
+	json.Unmarshal(b, &data)
+
+	txBytes, err := base58.Decode(data.RawTx)
+	if err != nil {
+		log.Error("cannot convert tx string to bytes", "error", err)
+
+		renderErrorMessage(err, w)
+		return
+	}
+
+	tx := new(transaction.TxImpl)
+	err = tx.Unmarshal(txBytes)
+	if err != nil {
+		log.Error("cannot decode transaction with protobuf", "error", err)
+
+		renderErrorMessage(err, w)
+		return
+	}
+
+	kp := &account.KeyPairImpl{}
+
+	err = kp.DecodePrivateKey(data.PrivateKey)
+	if err != nil {
+		log.Error("cannot decode private key string to bytes", "error", err)
+
+		renderErrorMessage(err, w)
+		return
+	}
+
+	memo, err := kp.DecryptMemo(tx.Memo())
+	if err != nil {
+		log.Error("cannot decrypt memo", "error", err)
+
+		renderErrorMessage(err, w)
+		return
+	}
+
+	d := map[string]string{"memo": string(memo)}
+	json.NewEncoder(w).Encode(d)
+}
+
+func estimateFeeHandler(w http.ResponseWriter, r *http.Request, txPool abstraction.TxPool) {
+	weight, err := parseOptionalUint(r.URL.Query().Get("weight"))
+	if err != nil {
+		log.Error("cannot convert `weight` to int", "error", err)
+
+		renderErrorMessage(err, w)
+		return
+	}
+
+	estimate := txPool.EstimateFee(weight)
+
+	d := map[string]string{
+		"weight":   strconv.FormatUint(estimate.Weight, 10),
+		"base_fee": estimate.BaseFee.String(),
+		"tip":      estimate.Tip.String(),
+		"max_fee":  estimate.MaxFee.String(),
+	}
+	json.NewEncoder(w).Encode(d)
+}
+
+func timeLocksHandler(w http.ResponseWriter, r *http.Request, stateDB *state.StateDB) {
+	type timeLock struct {
+		ID        string `json:"id"`
+		Owner     string `json:"owner"`
+		Recipient string `json:"recipient"`
+		Amount    string `json:"amount"`
+		UnlockAt  string `json:"unlock_at"`
+		Height    string `json:"height"`
+	}
+
+	address, err := common.ParseAddress(mux.Vars(r)["address"])
+	if err != nil {
+		log.Error("cannot decode `address` field", "error", err)
+
+		renderErrorMessage(err, w)
+		return
+	}
+
+	locks, err := executor.GetTimeLocks(stateDB, address)
+	if err != nil {
+		log.Error("cannot read time locks", "error", err)
+
+		renderErrorMessage(err, w)
+		return
+	}
+
+	d := make([]timeLock, len(locks))
+	for i, lock := range locks {
+		var owner, recipient common.Address
+		owner.SetBytes(lock.Owner)
+		recipient.SetBytes(lock.Recipient)
+
+		d[i] = timeLock{
+			ID:        hex.EncodeToString(lock.Id),
+			Owner:     owner.String(),
+			Recipient: recipient.String(),
+			Amount:    new(big.Int).SetBytes(lock.Amount).String(),
+			UnlockAt:  strconv.FormatUint(lock.UnlockAt, 10),
+			Height:    strconv.FormatUint(lock.Height, 10),
+		}
+	}
+	json.NewEncoder(w).Encode(d)
+}
+
+func htlcHandler(w http.ResponseWriter, r *http.Request, stateDB *state.StateDB) {
+	id, err := parseHash("id", mux.Vars(r)["id"])
+	if err != nil {
+		log.Error("cannot decode `id` field", "error", err)
+
+		renderErrorMessage(err, w)
+		return
+	}
+
+	htlc, err := executor.GetHTLC(stateDB, id)
+	if err != nil {
+		log.Error("cannot read htlc", "error", err)
+
+		renderErrorMessage(err, w)
+		return
+	}
+	if htlc == nil {
+		renderErrorMessage(errors.New("htlc does not exist"), w)
+		return
+	}
+
+	var sender, recipient common.Address
+	sender.SetBytes(htlc.Sender)
+	recipient.SetBytes(htlc.Recipient)
+
+	d := map[string]string{
+		"id":             hex.EncodeToString(htlc.Id),
+		"sender":         sender.String(),
+		"recipient":      recipient.String(),
+		"amount":         new(big.Int).SetBytes(htlc.Amount).String(),
+		"hash_algorithm": strings.ToLower(htlc.HashAlgorithm.String()),
+		"hash_lock":      hex.EncodeToString(htlc.HashLock),
+		"timeout_height": strconv.FormatUint(htlc.TimeoutHeight, 10),
+		"height":         strconv.FormatUint(htlc.Height, 10),
+		"status":         strings.ToLower(htlc.Status.String()),
+		"preimage":       hex.EncodeToString(htlc.Preimage),
+	}
+	json.NewEncoder(w).Encode(d)
+}
+
+// rpcLog is the json form of a log, topics and data are hex encoded.
+type rpcLog struct {
+	Address string   `json:"address"`
+	Topics  []string `json:"topics"`
+	Data    string   `json:"data"`
+	TxHash  string   `json:"tx_hash"`
+	Height  string   `json:"height"`
+}
+
+func newRPCLogs(logs []*executor.Log) []rpcLog {
+	d := make([]rpcLog, len(logs))
+	for i, l := range logs {
+		d[i] = rpcLog{
+			Address: l.Address.String(),
+			Topics:  make([]string, len(l.Topics)),
+			Data:    hex.EncodeToString(l.Data),
+			TxHash:  hex.EncodeToString(l.TxHash.CloneBytes()),
+			Height:  strconv.FormatUint(l.Height, 10),
+		}
+		for j, topic := range l.Topics {
+			d[i].Topics[j] = hex.EncodeToString(topic)
+		}
+	}
+	return d
+}
+
+func receiptHandler(w http.ResponseWriter, r *http.Request, blockChain *chain.BlockChain) {
+	type receipt struct {
+		TxHash  string   `json:"tx_hash"`
+		Height  string   `json:"height"`
+		Status  string   `json:"status"`
+		Fee     string   `json:"fee"`
+		GasUsed string   `json:"gas_used"`
+		Return  string   `json:"return"`
+		Logs    []rpcLog `json:"logs"`
+		Error   string   `json:"error,omitempty"`
+	}
+
+	hash, err := parseHash("hash", mux.Vars(r)["hash"])
+	if err != nil {
+		log.Error("cannot decode `hash` field", "error", err)
+
+		renderErrorMessage(err, w)
+		return
+	}
+
+	rec := blockChain.GetReceipt(hash)
+	if rec == nil {
+		renderErrorMessage(errors.New("receipt does not exist"), w)
+		return
+	}
+
+	d := receipt{
+		TxHash:  hex.EncodeToString(rec.TxHash.CloneBytes()),
+		Height:  strconv.FormatUint(rec.Height, 10),
+		Status:  "successful",
+		Fee:     rec.Fee.String(),
+		GasUsed: strconv.FormatUint(rec.GasUsed, 10),
+		Return:  hex.EncodeToString(rec.Return),
+		Logs:    newRPCLogs(rec.Logs),
+		Error:   rec.Error,
+	}
+	if rec.Status == executor.ReceiptStatusFailed {
+		d.Status = "failed"
+	}
+	json.NewEncoder(w).Encode(d)
+}
+
+// getLogsHandler returns logs of a block range by addresses and topics, see
+// chain.LogFilter. `topics` are hex encoded, an empty list at a position
+// matches any topic.
+func getLogsHandler(w http.ResponseWriter, r *http.Request, blockChain *chain.BlockChain) {
+	type getLogs struct {
+		FromHeight string     `json:"from_height"`
+		ToHeight   string     `json:"to_height"`
+		Addresses  []string   `json:"addresses"`
+		Topics     [][]string `json:"topics"`
+	}
+
+	data := new(getLogs)
+	b, _ := ioutil.ReadAll(r.Body)
+	json.Unmarshal(b, &data)
+
+	filter := &chain.LogFilter{
+		Addresses: make([]common.Address, len(data.Addresses)),
+		Topics:    make([][][]byte, len(data.Topics)),
+	}
+
+	var err error
+	filter.FromHeight, err = parseOptionalUint(data.FromHeight)
+	if err != nil {
+		log.Error("cannot convert `from_height` to int", "error", err)
+
+		renderErrorMessage(err, w)
+		return
+	}
+
+	// the range ends at the head by default.
+	filter.ToHeight = blockChain.Head().Header.Height
+	if data.ToHeight != "" {
+		filter.ToHeight, err = strconv.ParseUint(data.ToHeight, 10, 64)
+		if err != nil {
+			log.Error("cannot convert `to_height` to int", "error", err)
+
+			renderErrorMessage(err, w)
+			return
+		}
+	}
+
+	for i, address := range data.Addresses {
+		filter.Addresses[i], err = common.ParseAddress(address)
+		if err != nil {
+			log.Error("cannot decode `addresses` field", "error", err)
+
+			renderErrorMessage(err, w)
+			return
+		}
+	}
+
+	for i, alternatives := range data.Topics {
+		filter.Topics[i] = make([][]byte, len(alternatives))
+		for j, topic := range alternatives {
+			filter.Topics[i][j], err = hex.DecodeString(topic)
+			if err != nil {
+				log.Error("cannot decode `topics` field", "error", err)
+
+				renderErrorMessage(err, w)
+				return
+			}
+		}
+	}
+
+	logs, err := blockChain.GetLogs(filter)
+	if err != nil {
+		log.Error("cannot get logs", "error", err)
+
+		renderErrorMessage(err, w)
+		return
+	}
+	json.NewEncoder(w).Encode(newRPCLogs(logs))
+}
+
+// netPeersHandler returns the neighbors of the node with their measured latency.
+func netPeersHandler(w http.ResponseWriter, r *http.Request, p2pService abstraction.P2PService) {
+	type peer struct {
+		ID         string `json:"id"`
+		Addr       string `json:"addr"`
+		Version    string `json:"version"`
+		HeadHeight string `json:"head_height"`
+		LatencyMs  string `json:"latency_ms"`
+		LastSeen   string `json:"last_seen"`
+		Score      string `json:"score"`
+		BytesIn    string `json:"bytes_in"`
+		BytesOut   string `json:"bytes_out"`
+	}
+
+	peers := p2pService.Peers()
+	d := make([]peer, len(peers))
+	for i, p := range peers {
+		d[i] = peer{
+			ID:         p.ID,
+			Addr:       p.Addr,
+			Version:    strconv.FormatUint(uint64(p.Version), 10),
+			HeadHeight: strconv.FormatUint(p.HeadHeight, 10),
+			LatencyMs:  strconv.FormatInt(int64(p.Latency/time.Millisecond), 10),
+			LastSeen:   strconv.FormatInt(p.LastSeen.Unix(), 10),
+			Score:      strconv.Itoa(p.Score),
+			BytesIn:    strconv.FormatUint(p.BytesIn, 10),
+			BytesOut:   strconv.FormatUint(p.BytesOut, 10),
+		}
+	}
+	json.NewEncoder(w).Encode(d)
+}
+
+// netBandwidthHandler returns the bytes received and sent by message type.
+func netBandwidthHandler(w http.ResponseWriter, r *http.Request, p2pService abstraction.P2PService) {
+	type bandwidth struct {
+		Type     string `json:"type"`
+		BytesIn  string `json:"bytes_in"`
+		BytesOut string `json:"bytes_out"`
+	}
+
+	stats := p2pService.Bandwidth()
+	d := make([]bandwidth, len(stats))
+	for i, s := range stats {
+		d[i] = bandwidth{
+			Type:     s.Type,
+			BytesIn:  strconv.FormatUint(s.In, 10),
+			BytesOut: strconv.FormatUint(s.Out, 10),
+		}
+	}
+	json.NewEncoder(w).Encode(d)
+}
+
+// adminOnly rejects requests which do not come from the local host.
+func adminOnly(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if ip := net.ParseIP(host); err != nil || ip == nil || !ip.IsLoopback() {
+			w.WriteHeader(http.StatusForbidden)
+			renderErrorMessage(errors.New("admin api is only served to local host"), w)
+			return
+		}
+		h(w, r)
+	}
+}
+
+func bansHandler(w http.ResponseWriter, r *http.Request, p2pService abstraction.P2PService) {
+	type ban struct {
+		ID    string `json:"id"`
+		Until string `json:"until"`
+	}
+
+	bans := p2pService.BannedPeers()
+	d := make([]ban, len(bans))
+	for i, b := range bans {
+		d[i] = ban{
+			ID:    b.ID,
+			Until: strconv.FormatInt(b.Until.Unix(), 10),
+		}
+	}
+	json.NewEncoder(w).Encode(d)
+}
+
+// banHandler bans a peer for `duration` seconds, a day by default.
+func banHandler(w http.ResponseWriter, r *http.Request, p2pService abstraction.P2PService) {
+	type ban struct {
+		ID       string `json:"id"`
+		Duration string `json:"duration"`
+	}
+
+	data := new(ban)
+	b, _ := ioutil.ReadAll(r.Body)
+	json.Unmarshal(b, &data)
+
+	duration := uint64(24 * 60 * 60)
+	if data.Duration != "" {
+		var err error
+		duration, err = strconv.ParseUint(data.Duration, 10, 32)
+		if err != nil || duration == 0 {
+			log.Error("cannot convert `duration` to int", "error", err)
+
+			renderErrorMessage(errors.New("invalid `duration` field"), w)
+			return
+		}
+	}
+
+	if err := p2pService.Ban(data.ID, time.Duration(duration)*time.Second); err != nil {
+		log.Error("cannot ban peer", "error", err)
+
+		renderErrorMessage(err, w)
+		return
+	}
+
+	d := map[string]string{"result": "success"}
+	json.NewEncoder(w).Encode(d)
+}
+
+func unbanHandler(w http.ResponseWriter, r *http.Request, p2pService abstraction.P2PService) {
+	type unban struct {
+		ID string `json:"id"`
+	}
+
+	data := new(unban)
+	b, _ := ioutil.ReadAll(r.Body)
+	json.Unmarshal(b, &data)
+
+	if err := p2pService.Unban(data.ID); err != nil {
+		log.Error("cannot unban peer", "error", err)
+
+		renderErrorMessage(err, w)
+		return
+	}
 
 	d := map[string]string{"result": "success"}
 	json.NewEncoder(w).Encode(d)
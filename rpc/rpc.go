@@ -8,6 +8,8 @@ import (
 	"github.com/gorilla/mux"
 	log "github.com/inconshreveable/log15"
 	"github.com/ldmtam/tam-chain/abstraction"
+	"github.com/ldmtam/tam-chain/common"
+	"github.com/ldmtam/tam-chain/core/chain"
 )
 
 // JSONServer json based api rpc server.
@@ -34,7 +36,7 @@ func NewJSONServer(endPoint, port string) *JSONServer {
 }
 
 // Start the server
-func (j *JSONServer) Start(txPool abstraction.TxPool) {
+func (j *JSONServer) Start(txPool abstraction.TxPool, blockChain *chain.BlockChain, p2pService abstraction.P2PService, chainConfig *common.ChainConfig) {
 	go func() {
 		r := mux.NewRouter()
 
@@ -42,12 +44,58 @@ func (j *JSONServer) Start(txPool abstraction.TxPool) {
 
 		r.HandleFunc("/generatekeypair", generateKeypairHandler).Methods("POST")
 
-		r.HandleFunc("/createrawtx", createRawTxHandler).Methods("POST")
+		r.HandleFunc("/createrawtx", func(w http.ResponseWriter, r *http.Request) {
+			createRawTxHandler(w, r, chainConfig)
+		}).Methods("POST")
 
 		r.HandleFunc("/signrawtx", signRawTxHandler).Methods("POST")
 
+		r.HandleFunc("/encryptmemo", encryptMemoHandler).Methods("POST")
+
+		r.HandleFunc("/decryptmemo", decryptMemoHandler).Methods("POST")
+
+		r.HandleFunc("/fee/estimate", func(w http.ResponseWriter, r *http.Request) {
+			estimateFeeHandler(w, r, txPool)
+		}).Methods("GET")
+
+		r.HandleFunc("/timelocks/{address}", func(w http.ResponseWriter, r *http.Request) {
+			timeLocksHandler(w, r, blockChain.State())
+		}).Methods("GET")
+
+		r.HandleFunc("/htlc/{id}", func(w http.ResponseWriter, r *http.Request) {
+			htlcHandler(w, r, blockChain.State())
+		}).Methods("GET")
+
+		r.HandleFunc("/receipt/{hash}", func(w http.ResponseWriter, r *http.Request) {
+			receiptHandler(w, r, blockChain)
+		}).Methods("GET")
+
+		r.HandleFunc("/getlogs", func(w http.ResponseWriter, r *http.Request) {
+			getLogsHandler(w, r, blockChain)
+		}).Methods("POST")
+
+		r.HandleFunc("/net/peers", func(w http.ResponseWriter, r *http.Request) {
+			netPeersHandler(w, r, p2pService)
+		}).Methods("GET")
+
+		r.HandleFunc("/net/bandwidth", func(w http.ResponseWriter, r *http.Request) {
+			netBandwidthHandler(w, r, p2pService)
+		}).Methods("GET")
+
+		r.HandleFunc("/admin/bans", adminOnly(func(w http.ResponseWriter, r *http.Request) {
+			bansHandler(w, r, p2pService)
+		})).Methods("GET")
+
+		r.HandleFunc("/admin/ban", adminOnly(func(w http.ResponseWriter, r *http.Request) {
+			banHandler(w, r, p2pService)
+		})).Methods("POST")
+
+		r.HandleFunc("/admin/unban", adminOnly(func(w http.ResponseWriter, r *http.Request) {
+			unbanHandler(w, r, p2pService)
+		})).Methods("POST")
+
 		r.HandleFunc("/sendrawtx", func(w http.ResponseWriter, r *http.Request) {
-			sendRawTxHandler(w, r, txPool)
+			sendRawTxHandler(w, r, txPool, p2pService)
 		}).Methods("POST")
 
 		j.srv = &http.Server{